@@ -3,12 +3,42 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
+// Режимы получения апдейтов
+const (
+	ModePolling = "polling"
+	ModeWebhook = "webhook"
+)
+
 type Config struct {
 	BotToken string
+
+	// Mode — polling (по умолчанию) или webhook
+	Mode string
+	// HTTPAddr — адрес встроенного HTTP-сервера (вебхуки, healthcheck)
+	HTTPAddr string
+	// DebugAddr — внутренний адрес метрик /debug/vars; пустой — метрики не отдаются
+	DebugAddr string
+	// WebhookURL — публичный URL вебхука, путь из него используется для регистрации обработчика
+	WebhookURL string
+	// WebhookSecret — сверяется с заголовком X-Telegram-Bot-Api-Secret-Token
+	WebhookSecret string
+
+	// Workers — число параллельных обработчиков апдейтов, QueueSize — длина очереди каждого
+	Workers   int
+	QueueSize int
+
+	// CallbackSecret — ключ подписи callback_data; пустой — производный от токена бота
+	CallbackSecret string
+
+	// NotifyTimezone — часовой пояс тихих часов уведомлений (IANA, например Europe/Moscow)
+	NotifyTimezone string
+	// ExpiryReminderDays — за сколько дней предупреждать об окончании доступа
+	ExpiryReminderDays int
 }
 
 func LoadConfig() *Config {
@@ -18,6 +48,33 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		BotToken: os.Getenv("BOT_TOKEN"),
+		BotToken:      os.Getenv("BOT_TOKEN"),
+		Mode:          getenv("BOT_MODE", ModePolling),
+		HTTPAddr:      getenv("HTTP_ADDR", ":8080"),
+		DebugAddr:     getenv("DEBUG_ADDR", "127.0.0.1:6060"),
+		WebhookURL:    os.Getenv("WEBHOOK_URL"),
+		WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+		Workers:       getenvInt("BOT_WORKERS", 0),
+		QueueSize:     getenvInt("BOT_QUEUE_SIZE", 0),
+
+		CallbackSecret: os.Getenv("CALLBACK_SECRET"),
+
+		NotifyTimezone:     getenv("NOTIFY_TIMEZONE", "Europe/Moscow"),
+		ExpiryReminderDays: getenvInt("EXPIRY_REMINDER_DAYS", 3),
+	}
+}
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getenvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
 	}
+	return v
 }
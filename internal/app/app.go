@@ -2,30 +2,47 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	_ "time/tzdata" // часовые пояса без системной tzdata (контейнеры)
 	"walki/config"
 	"walki/internal/bot"
 	"walki/internal/db"
+	"walki/internal/handlers/mux"
+	"walki/internal/httpserver"
 	"walki/internal/repository/postgres"
+	"walki/internal/scheduler"
 	"walki/internal/service"
 	"walki/internal/service/tgmedia"
 	"walki/internal/storage/s3client"
 )
 
-// Run собирает зависимости и запускает бота
+// Сколько ждём завершения начатых обработчиков при остановке
+const shutdownTimeout = 30 * time.Second
+
+// Run собирает зависимости и запускает бота до SIGINT/SIGTERM.
+// Порядок остановки: приём апдейтов (опрос, HTTP) → дообработка принятых →
+// подтверждение offset или снятие вебхука → S3 → БД.
 func Run() {
 	cfg := config.LoadConfig()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// init db pool
 	connStr := os.Getenv("DATABASE_URL")
 	pool := db.MustPool(connStr)
 	defer pool.Close()
 
-	s3c, err := s3client.New(context.Background())
+	s3c, err := s3client.New(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer s3c.Close()
 
 	// repo-адаптеры поверх storage
 	routeRepo := postgres.NewRouteRepo(pool)
@@ -33,16 +50,103 @@ func Run() {
 	userRepo := postgres.NewUserRepo(pool)
 	runRepo := postgres.NewRouteRunRepo(pool)
 	mediaRepo := postgres.NewMediaRepo(pool)
+	pubRepo := postgres.NewPublicationRepo(pool)
+	authRepo := postgres.NewAuthoringRepo(pool)
+	stateRepo := postgres.NewChatStateRepo(pool)
+	banRepo := postgres.NewBanRepo(pool)
+	payloadRepo := postgres.NewCallbackPayloadRepo(pool)
+	promoRepo := postgres.NewPromoRepo(pool)
+	favRepo := postgres.NewFavoriteRepo(pool)
+	transRepo := postgres.NewTranslationRepo(pool)
+	reviewRepo := postgres.NewReviewRepo(pool)
+	notifyRepo := postgres.NewNotificationRepo(pool)
 
 	// сервисы
-	routeSvc := service.NewRouteService(routeRepo)
-	orderSvc := service.NewOrderService(orderRepo, routeRepo)
-	profSvc := service.NewProfileService(orderRepo, routeRepo)
+	routeSvc := service.NewRouteService(routeRepo, transRepo)
+	orderSvc := service.NewOrderService(orderRepo, routeRepo, promoRepo)
+	profSvc := service.NewProfileService(orderRepo, routeRepo, favRepo, reviewRepo, transRepo)
 	userSvc := service.NewUserService(userRepo)
-	runSvc := service.NewRouteRunService(routeRepo, orderRepo, runRepo)
+	runSvc := service.NewRouteRunService(routeRepo, orderRepo, runRepo, transRepo)
+	pubSvc := service.NewPublicationService(routeRepo, pubRepo)
+	authSvc := service.NewAuthoringService(authRepo, routeRepo, runRepo, transRepo)
+	modSvc := service.NewModerationService(banRepo)
+	notifySvc := service.NewNotificationService(notifyRepo, notifyLocation(cfg), cfg.ExpiryReminderDays)
 	tgSvc := tgmedia.New(mediaRepo, s3c)
 
+	// подписанные callback_data; длинные данные — в БД
+	codec := mux.NewCodec(callbackSecret(cfg), payloadRepo)
+
 	// бот с явным внедрением сервисов
-	b := bot.New(cfg.BotToken, routeSvc, orderSvc, profSvc, userSvc, runSvc, tgSvc)
-	b.Start()
+	b := bot.New(cfg.BotToken, routeSvc, orderSvc, profSvc, userSvc, runSvc, pubSvc, authSvc, modSvc, notifySvc, stateRepo, codec, tgSvc)
+	b.SetConcurrency(cfg.Workers, cfg.QueueSize)
+
+	srv := httpserver.New(cfg.HTTPAddr)
+	if cfg.Mode == config.ModeWebhook {
+		if err := b.StartWebhook(srv, cfg.WebhookURL, cfg.WebhookSecret); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// HTTP: вебхук и healthcheck; Run возвращается после Shutdown
+	httpDone := make(chan struct{})
+	go func() {
+		defer close(httpDone)
+		if err := srv.Run(ctx); err != nil {
+			log.Printf("http server: %v", err)
+		}
+	}()
+
+	// метрики — только на внутреннем адресе
+	debugDone := make(chan struct{})
+	go func() {
+		defer close(debugDone)
+		if cfg.DebugAddr == "" {
+			return
+		}
+		if err := httpserver.NewDebug(cfg.DebugAddr).Run(ctx); err != nil {
+			log.Printf("debug server: %v", err)
+		}
+	}()
+
+	// напоминания и другие запланированные уведомления; очередь в БД общая для всех экземпляров.
+	// Там же — уборка устаревших данных inline-кнопок.
+	schedDone := make(chan struct{})
+	go func() {
+		defer close(schedDone)
+		scheduler.New(notifySvc, b, payloadRepo).Run(ctx)
+	}()
+
+	if cfg.Mode == config.ModeWebhook {
+		<-ctx.Done()
+	} else {
+		b.Start(ctx)
+	}
+	log.Println("shutting down...")
+
+	// вебхук перестаёт принимать апдейты только после остановки сервера;
+	// планировщик не должен ставить сообщения в уже закрытую очередь
+	<-httpDone
+	<-debugDone
+	<-schedDone
+	b.Stop(shutdownTimeout)
+	log.Println("bye")
+}
+
+// callbackSecret: явный CALLBACK_SECRET или ключ, производный от токена бота
+func callbackSecret(cfg *config.Config) []byte {
+	if cfg.CallbackSecret != "" {
+		return []byte(cfg.CallbackSecret)
+	}
+	sum := sha256.Sum256([]byte("callback:" + cfg.BotToken))
+	return sum[:]
+}
+
+// notifyLocation — часовой пояс тихих часов; неизвестный — UTC
+func notifyLocation(cfg *config.Config) *time.Location {
+	loc, err := time.LoadLocation(cfg.NotifyTimezone)
+	if err != nil {
+		log.Printf("notify timezone %q: %v, using UTC", cfg.NotifyTimezone, err)
+		return time.UTC
+	}
+	return loc
 }
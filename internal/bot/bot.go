@@ -1,8 +1,14 @@
 package bot
 
 import (
+	"context"
+	"log"
+	"time"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"walki/internal/handlers"
+	"walki/internal/handlers/mux"
+	"walki/internal/sender"
 	"walki/internal/service"
 	"walki/internal/service/tgmedia"
 )
@@ -10,9 +16,30 @@ import (
 // Bot представляет собой экземпляр Telegram бота
 type Bot struct {
 	api     *tgbotapi.BotAPI
+	out     *sender.Sender
 	handler *handlers.Handler
+
+	// параллельная обработка апдейтов (см. pool.go); создаётся в Start/StartWebhook
+	workers   int
+	queueSize int
+	pool      *updatePool
+	webhook   bool
 }
 
+// Параметры пула по умолчанию
+const (
+	defaultWorkers   = 16
+	defaultQueueSize = 64
+	// очередь несрочных исходящих сообщений (рассылки, напоминания)
+	sendQueueSize = 10000
+)
+
+// Long polling: таймаут запроса и пауза после ошибки
+const (
+	pollTimeout = 60
+	pollBackoff = 3 * time.Second
+)
+
 // Новый конструктор с DI сервисов.
 func New(token string,
 	routeSvc *service.RouteService,
@@ -20,25 +47,147 @@ func New(token string,
 	profileSvc *service.ProfileService,
 	userSvc *service.UserService,
 	runSvc *service.RouteRunService,
+	pubSvc *service.PublicationService,
+	authSvc *service.AuthoringService,
+	modSvc *service.ModerationService,
+	notifySvc *service.NotificationService,
+	states mux.StateStore,
+	codec *mux.Codec,
 	tg *tgmedia.Service) *Bot {
 	api, _ := tgbotapi.NewBotAPI(token)
-	h := handlers.NewHandler(api, routeSvc, orderSvc, profileSvc, userSvc, runSvc, tg)
-	return &Bot{api: api, handler: h}
-}
-
-// Start запускает бота в режиме опроса
-func (b *Bot) Start() {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := b.api.GetUpdatesChan(u)
-
-	for update := range updates {
-		if update.CallbackQuery != nil {
-			// Обрабатываем callback-запросы
-			b.handler.HandleCallback(update)
-		} else if update.Message != nil {
-			// Обрабатываем обычные сообщения
-			b.handler.HandleUpdate(update)
+	out := sender.New(api, sendQueueSize)
+	h := handlers.NewHandler(out, routeSvc, orderSvc, profileSvc, userSvc, runSvc, pubSvc, authSvc, modSvc, notifySvc, states, codec, tg)
+	return &Bot{api: api, out: out, handler: h, workers: defaultWorkers, queueSize: defaultQueueSize}
+}
+
+// SetConcurrency задаёт число воркеров и длину очереди каждого; вызывать до Start
+func (b *Bot) SetConcurrency(workers, queueSize int) {
+	if workers > 0 {
+		b.workers = workers
+	}
+	if queueSize > 0 {
+		b.queueSize = queueSize
+	}
+}
+
+// Start опрашивает Telegram, пока не отменён ctx.
+// Offset подтверждаем только по обработанным апдейтам (см. updatePool.Offset),
+// поэтому необработанные при падении апдейты Telegram пришлёт повторно.
+func (b *Bot) Start(ctx context.Context) {
+	b.pool = newUpdatePool(b.workers, b.queueSize, b.dispatch)
+
+	cfg := tgbotapi.NewUpdate(0)
+	cfg.Timeout = pollTimeout
+	for ctx.Err() == nil {
+		if off := b.pool.Offset(); off > 0 {
+			cfg.Offset = off
+		}
+		updates, err := b.getUpdates(ctx, cfg)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("get updates: %v", err)
+			sleepCtx(ctx, pollBackoff)
+			continue
+		}
+
+		fresh := 0
+		for _, update := range updates {
+			// ещё не подтверждённые, но уже принятые апдейты приходят повторно
+			if b.pool.Seen(update.UpdateID) {
+				continue
+			}
+			if err := b.pool.Submit(ctx, update); err != nil {
+				// остальные апдейты пачки не приняты и придут после перезапуска
+				return
+			}
+			fresh++
+		}
+		if len(updates) > 0 && fresh == 0 {
+			// все апдейты ещё в обработке — не крутим пустой цикл
+			sleepCtx(ctx, 500*time.Millisecond)
 		}
 	}
 }
+
+// getUpdates — long polling, прерываемый отменой ctx.
+// Брошенный запрос ничего не подтверждает: offset уходит только со следующим запросом.
+func (b *Bot) getUpdates(ctx context.Context, cfg tgbotapi.UpdateConfig) ([]tgbotapi.Update, error) {
+	type result struct {
+		updates []tgbotapi.Update
+		err     error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		updates, err := b.api.GetUpdates(cfg)
+		ch <- result{updates, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.updates, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Notify — запланированное уведомление пользователю (для scheduler)
+func (b *Bot) Notify(ctx context.Context, n *service.Notification) error {
+	return b.handler.Notify(ctx, n)
+}
+
+// Sender — исходящие сообщения бота (для рассылок и напоминаний — Enqueue)
+func (b *Bot) Sender() *sender.Sender { return b.out }
+
+// Stop дожидается обработки принятых апдейтов и отправки очереди сообщений
+// (не дольше timeout; не успевшие обработчики отменяются, и Stop ждёт их возврата),
+// затем подтверждает offset (опрос) или снимает вебхук.
+// Вызывать после того, как приём апдейтов остановлен.
+func (b *Bot) Stop(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if b.pool == nil {
+		_ = b.out.Close(ctx)
+		return
+	}
+	if err := b.pool.Close(ctx); err != nil {
+		log.Printf("bot: handlers cancelled after %s, %d updates left unconfirmed", timeout, b.pool.Pending())
+	}
+	if err := b.out.Close(ctx); err != nil {
+		log.Printf("bot: outgoing queue not drained: %v", err)
+	}
+
+	if b.webhook {
+		b.deleteWebhook()
+		return
+	}
+	b.commitOffset()
+}
+
+// commitOffset подтверждает обработанные апдейты, чтобы после перезапуска они не пришли снова
+func (b *Bot) commitOffset() {
+	off := b.pool.Offset()
+	if off == 0 {
+		return
+	}
+	cfg := tgbotapi.UpdateConfig{Offset: off, Limit: 1}
+	if _, err := b.api.GetUpdates(cfg); err != nil {
+		log.Printf("bot: commit offset %d: %v", off, err)
+		return
+	}
+	log.Printf("bot: committed offset %d", off)
+}
+
+// dispatch — общая точка входа апдейтов для опроса и вебхука
+func (b *Bot) dispatch(ctx context.Context, update tgbotapi.Update) {
+	b.handler.Handle(ctx, update)
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
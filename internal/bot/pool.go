@@ -0,0 +1,247 @@
+package bot
+
+import (
+	"context"
+	"expvar"
+	"hash/fnv"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+/*
+Пул обработки апдейтов. Апдейты одного чата всегда попадают в один и тот же шард
+(по хешу chat_id) и обрабатываются строго по очереди; разные чаты — параллельно.
+Очередь шарда ограничена: когда она заполнена, Submit ждёт — так приём апдейтов
+притормаживает, а не копит память.
+
+Пул помнит необработанные апдейты: по ним считается offset, который можно
+подтвердить Telegram, не потеряв ни одного апдейта.
+
+Обработчики получают контекст пула. Если при остановке они не уложились в срок,
+контекст отменяется; прерванные и не начатые апдейты остаются неподтверждёнными.
+*/
+
+// Метрики пула, доступны на /debug/vars
+var (
+	poolMetrics    = expvar.NewMap("bot_pool")
+	poolQueueDepth = new(expvar.Int) // апдейтов в очередях сейчас
+	poolInFlight   = new(expvar.Int) // обрабатываются сейчас
+	poolProcessed  = new(expvar.Int)
+	poolRejected   = new(expvar.Int) // не дождались места в очереди
+	poolLatency    = new(expvar.Map) // гистограмма времени обработки, мс
+)
+
+// Верхние границы корзин гистограммы, мс
+var latencyBuckets = []int64{10, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+func init() {
+	poolMetrics.Set("queue_depth", poolQueueDepth)
+	poolMetrics.Set("in_flight", poolInFlight)
+	poolMetrics.Set("processed", poolProcessed)
+	poolMetrics.Set("rejected", poolRejected)
+	poolMetrics.Set("latency_ms", poolLatency)
+}
+
+type updatePool struct {
+	shards []chan tgbotapi.Update
+	handle func(context.Context, tgbotapi.Update)
+	wg     sync.WaitGroup
+
+	ctx    context.Context // контекст обработчиков; отменяется, если Close не дождался
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending map[int]struct{} // приняты, но ещё не обработаны
+	maxSeen int              // максимальный принятый update_id
+}
+
+func newUpdatePool(workers, queueSize int, handle func(context.Context, tgbotapi.Update)) *updatePool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &updatePool{
+		shards:  make([]chan tgbotapi.Update, workers),
+		handle:  handle,
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: map[int]struct{}{},
+	}
+	for i := range p.shards {
+		p.shards[i] = make(chan tgbotapi.Update, queueSize)
+		p.wg.Add(1)
+		go p.worker(p.shards[i])
+	}
+	return p
+}
+
+// Submit ставит апдейт в очередь его чата; блокируется, пока очередь полна
+func (p *updatePool) Submit(ctx context.Context, update tgbotapi.Update) error {
+	shard := p.shards[shardOf(updateChatID(update), len(p.shards))]
+	poolQueueDepth.Add(1)
+	p.track(update.UpdateID)
+	select {
+	case shard <- update:
+		p.accept(update.UpdateID)
+		return nil
+	case <-ctx.Done():
+		p.done(update.UpdateID)
+		poolQueueDepth.Add(-1)
+		poolRejected.Add(1)
+		return ctx.Err()
+	}
+}
+
+// Seen — апдейт уже принят пулом или ждёт места в очереди (Telegram прислал его повторно)
+func (p *updatePool) Seen(updateID int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, waiting := p.pending[updateID]
+	return waiting || updateID <= p.maxSeen
+}
+
+// Offset — минимальный update_id, который ещё нельзя подтверждать: всё, что меньше,
+// обработано. 0 — пул ещё ничего не принимал.
+func (p *updatePool) Offset() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.maxSeen == 0 {
+		return 0
+	}
+	off := p.maxSeen + 1
+	for id := range p.pending {
+		if id < off {
+			off = id
+		}
+	}
+	return off
+}
+
+// Pending — сколько апдейтов ещё не обработано
+func (p *updatePool) Pending() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.pending)
+}
+
+// track — апдейт ждёт места в очереди: пока он не обработан, offset его не пропустит
+func (p *updatePool) track(updateID int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[updateID] = struct{}{}
+}
+
+// accept — апдейт попал в очередь. maxSeen растёт только здесь: отклонённый
+// апдейт не должен сдвинуть offset, иначе Telegram сочтёт его доставленным.
+func (p *updatePool) accept(updateID int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if updateID > p.maxSeen {
+		p.maxSeen = updateID
+	}
+}
+
+func (p *updatePool) done(updateID int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.pending, updateID)
+}
+
+// Close перестаёт принимать апдейты и ждёт, пока воркеры разберут очереди.
+// Если ctx истёк раньше — отменяет контекст обработчиков, дожидается воркеров
+// и возвращает ошибку ctx. После возврата обработчиков не остаётся:
+// зависимости (БД, S3) можно закрывать. После Close вызывать Submit нельзя.
+func (p *updatePool) Close(ctx context.Context) error {
+	for _, ch := range p.shards {
+		close(ch)
+	}
+	drained := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(drained)
+	}()
+	defer p.cancel()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+	p.cancel()
+	<-drained
+	return ctx.Err()
+}
+
+func (p *updatePool) worker(ch <-chan tgbotapi.Update) {
+	defer p.wg.Done()
+	for update := range ch {
+		poolQueueDepth.Add(-1)
+		if p.ctx.Err() != nil {
+			continue // остановка: апдейт придёт снова после перезапуска
+		}
+		poolInFlight.Add(1)
+		start := time.Now()
+
+		p.safeHandle(update)
+		if p.ctx.Err() == nil {
+			p.done(update.UpdateID) // прерванный обработчик не подтверждаем
+		}
+
+		observeLatency(time.Since(start))
+		poolInFlight.Add(-1)
+		poolProcessed.Add(1)
+	}
+}
+
+// safeHandle — паника вне обработчиков роутера не должна убивать воркер
+func (p *updatePool) safeHandle(update tgbotapi.Update) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[panic] update %d: %v\n%s", update.UpdateID, r, debug.Stack())
+		}
+	}()
+	p.handle(p.ctx, update)
+}
+
+func observeLatency(d time.Duration) {
+	ms := d.Milliseconds()
+	for _, b := range latencyBuckets {
+		if ms <= b {
+			poolLatency.Add("le_"+strconv.FormatInt(b, 10), 1)
+			return
+		}
+	}
+	poolLatency.Add("le_inf", 1)
+}
+
+func shardOf(chatID int64, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strconv.FormatInt(chatID, 10)))
+	return int(h.Sum32() % uint32(n))
+}
+
+// updateChatID — ключ упорядочивания: чат, а если его нет — пользователь
+func updateChatID(u tgbotapi.Update) int64 {
+	switch {
+	case u.Message != nil:
+		return u.Message.Chat.ID
+	case u.EditedMessage != nil:
+		return u.EditedMessage.Chat.ID
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return u.CallbackQuery.Message.Chat.ID
+	case u.CallbackQuery != nil:
+		return u.CallbackQuery.From.ID
+	case u.InlineQuery != nil:
+		return u.InlineQuery.From.ID
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From.ID
+	}
+	return 0
+}
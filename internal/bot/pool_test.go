@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func chatUpdate(id int) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 1}}}
+}
+
+// Апдейт, не дождавшийся места в очереди, не должен попасть в подтверждённый offset
+func TestSubmitCancelledKeepsOffset(t *testing.T) {
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	p := newUpdatePool(1, 1, func(context.Context, tgbotapi.Update) {
+		started <- struct{}{}
+		<-release
+	})
+
+	if err := p.Submit(context.Background(), chatUpdate(10)); err != nil {
+		t.Fatal(err)
+	}
+	<-started // воркер занят апдейтом 10
+	if err := p.Submit(context.Background(), chatUpdate(11)); err != nil {
+		t.Fatal(err)
+	}
+
+	// очередь полна: Submit ждёт, пока ctx не отменят
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if err := p.Submit(ctx, chatUpdate(12)); err == nil {
+		t.Fatal("Submit with cancelled ctx: want error")
+	}
+	if p.Seen(12) {
+		t.Error("rejected update is reported as seen")
+	}
+	if got := p.Offset(); got != 10 {
+		t.Errorf("Offset while processing = %d, want 10", got)
+	}
+
+	close(release)
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Second)
+	defer closeCancel()
+	if err := p.Close(closeCtx); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Offset(); got != 12 {
+		t.Errorf("Offset after drain = %d, want 12", got)
+	}
+}
+
+func TestSeenWhileWaiting(t *testing.T) {
+	release := make(chan struct{})
+	p := newUpdatePool(1, 1, func(context.Context, tgbotapi.Update) { <-release })
+	defer func() {
+		close(release)
+		_ = p.Close(context.Background())
+	}()
+
+	_ = p.Submit(context.Background(), chatUpdate(1))
+	_ = p.Submit(context.Background(), chatUpdate(2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	submitted := make(chan struct{})
+	go func() {
+		defer close(submitted)
+		_ = p.Submit(ctx, chatUpdate(3))
+	}()
+	defer func() {
+		cancel()
+		<-submitted // Submit после Close нельзя
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !p.Seen(3) {
+		if time.Now().After(deadline) {
+			t.Fatal("waiting update is not reported as seen")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Close по истечении срока отменяет обработчики, дожидается их и не подтверждает прерванное
+func TestCloseCancelsHandlers(t *testing.T) {
+	started := make(chan struct{})
+	p := newUpdatePool(1, 1, func(ctx context.Context, _ tgbotapi.Update) {
+		close(started)
+		<-ctx.Done()
+	})
+	if err := p.Submit(context.Background(), chatUpdate(5)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); err == nil {
+		t.Fatal("Close: want deadline error")
+	}
+	if got := p.Offset(); got != 5 {
+		t.Errorf("Offset = %d, want 5: cancelled update must stay unconfirmed", got)
+	}
+}
@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/httpserver"
+)
+
+// Заголовок, в котором Telegram присылает secret_token из setWebhook
+const secretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// StartWebhook регистрирует обработчик на сервере и сообщает Telegram адрес вебхука.
+// Обработчик вешается на путь из publicURL.
+func (b *Bot) StartWebhook(srv *httpserver.Server, publicURL, secret string) error {
+	u, err := url.Parse(publicURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", publicURL)
+	}
+	if secret == "" {
+		return fmt.Errorf("webhook secret is required")
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	b.pool = newUpdatePool(b.workers, b.queueSize, b.dispatch)
+	b.webhook = true
+	srv.Handle("POST "+path, b.webhookHandler(secret))
+
+	// WebhookConfig из tgbotapi v5.5 не знает про secret_token — собираем запрос сами
+	params := tgbotapi.Params{}
+	params["url"] = publicURL
+	params["secret_token"] = secret
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("set webhook: %w", err)
+	}
+	log.Printf("webhook: registered %s", u.Host+path)
+	return nil
+}
+
+// deleteWebhook снимает вебхук, чтобы Telegram не слал апдейты в остановленный инстанс
+func (b *Bot) deleteWebhook() {
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		log.Printf("webhook: delete: %v", err)
+	}
+}
+
+func (b *Bot) webhookHandler(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := r.Header.Get(secretHeader)
+		if subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+			log.Printf("webhook: decode update: %v", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		// повтор уже принятого апдейта (Telegram не дождался ответа) — подтверждаем без обработки
+		if b.pool.Seen(update.UpdateID) {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		// очередь чата полна и Telegram уже отключился — пусть пришлёт апдейт повторно
+		if err := b.pool.Submit(r.Context(), update); err != nil {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
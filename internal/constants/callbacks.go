@@ -2,6 +2,10 @@ package constants
 
 const (
 	BtnRoutes  = "routes"
+	BtnSearch  = "search"
 	BtnProfile = "profile"
 	BtnHelp    = "help"
+
+	BtnSendLocation = "send_location"
+	BtnMainMenu     = "main_menu"
 )
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"time"
+
+	"walki/internal/models"
+)
+
+// JobTarget — кому и о чём уведомление; читается при отправке, а не при планировании,
+// чтобы не напоминать о том, что уже неактуально
+type JobTarget struct {
+	User         models.User
+	RouteID      int
+	VersionID    int
+	AccessExpiry *time.Time    // для JobAccessExpiry
+	Idle         time.Duration // для JobProgressNudge: сколько прошло с последнего действия
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "walki/internal/models"
+
+// NearbyRoute — маршрут каталога и расстояние до его старта
+type NearbyRoute struct {
+	Route      models.RouteVersion
+	DistanceKm float64
+}
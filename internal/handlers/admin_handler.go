@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/render"
+)
+
+// Подписи статусов для админских экранов
+func routeStatusText(l i18n.Localizer, status string) string {
+	return l.T("status." + status)
+}
+
+// routeAdminCommand — общий каркас для команд вида "/publish <routeID>"
+func (h *Handler) routeAdminCommand(action func(ctx context.Context, chatID int64, usr *models.User, routeID int) error) mux.HandlerFunc {
+	return func(u *mux.UpdateCtx) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		routeID, err := strconv.Atoi(strings.TrimSpace(u.Update.Message.CommandArguments()))
+		if err != nil || routeID <= 0 {
+			h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("admin.route_id_usage", u.Update.Message.Command()))
+			return nil
+		}
+		return action(u.Ctx, u.ChatID, usr, routeID)
+	}
+}
+
+func (h *Handler) handlePublish(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	ver, prev, err := h.publication.Publish(ctx, usr, routeID)
+	if err != nil {
+		return &publicationError{"publish", routeID, err}
+	}
+	h.sendMessage(chatID, i18n.From(ctx).T("admin.published", ver.VersionNumber, ver.Title))
+	h.notifyFavorites(ctx, ver, prev)
+	return nil
+}
+
+func (h *Handler) handleArchive(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	if err := h.publication.Archive(ctx, usr, routeID); err != nil {
+		return &publicationError{"archive", routeID, err}
+	}
+	h.sendMessage(chatID, i18n.From(ctx).T("admin.archived", routeID))
+	return nil
+}
+
+func (h *Handler) handleRestore(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	if err := h.publication.Restore(ctx, usr, routeID); err != nil {
+		return &publicationError{"restore", routeID, err}
+	}
+	h.sendMessage(chatID, i18n.From(ctx).T("admin.restored", routeID))
+	return nil
+}
+
+func (h *Handler) handleSetVisible(visible bool) func(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	return func(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+		if err := h.publication.SetVisible(ctx, usr, routeID, visible); err != nil {
+			return &publicationError{"set visible", routeID, err}
+		}
+		if visible {
+			h.sendMessage(chatID, i18n.From(ctx).T("admin.shown", routeID))
+		} else {
+			h.sendMessage(chatID, i18n.From(ctx).T("admin.hidden", routeID))
+		}
+		return nil
+	}
+}
+
+func (h *Handler) handleHistory(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	changes, err := h.publication.History(ctx, usr, routeID)
+	if err != nil {
+		return &publicationError{"history", routeID, err}
+	}
+	l := i18n.From(ctx)
+	if len(changes) == 0 {
+		h.sendMessage(chatID, l.T("admin.history_empty"))
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString(l.T("admin.history_title", routeID))
+	for _, c := range changes {
+		from := "—"
+		if c.FromStatus != nil {
+			from = *c.FromStatus
+		}
+		fmt.Fprintf(&b, "\n%s: %s → %s", c.ChangedAt.Format("02.01.2006 15:04"), from, c.ToStatus)
+		if c.VersionID != nil {
+			b.WriteString(l.T("admin.history_version", *c.VersionID))
+		}
+		if c.ChangedBy != nil {
+			b.WriteString(l.T("admin.history_user", *c.ChangedBy))
+		}
+		if c.Comment != "" {
+			fmt.Fprintf(&b, ", %s", l.T("admin.history_reason."+c.Comment))
+		}
+	}
+	h.sendMessage(chatID, b.String())
+	return nil
+}
+
+// handlePreview показывает карточку последней версии (в т.ч. черновика) автору или администратору
+func (h *Handler) handlePreview(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	ver, err := h.publication.Preview(ctx, usr, routeID)
+	if err != nil {
+		return &publicationError{"preview", routeID, err}
+	}
+	l := i18n.From(ctx)
+	message := render.RoutePreview(l, routeStatusText(l, ver.Status), ver)
+	h.sendMessageWithMarkup(chatID, message, tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu)),
+	))
+	return nil
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/service"
+	"walki/internal/service/tgmedia"
+)
+
+/*
+Режим автора: гид собирает маршрут прямо в чате.
+Шаг диалога — состояние FSM роутера, поэтому следующее сообщение
+(текст, геопозиция, фото, голос) уходит обработчику этого шага.
+*/
+
+// Состояния диалога автора
+const (
+	stateAuthorNewTitle      = "author:new_title"
+	stateAuthorField         = "author:field"          // v, f
+	stateAuthorPointLocation = "author:point_location" // v
+	stateAuthorPointText     = "author:point_text"     // v, p
+	stateAuthorPointMedia    = "author:point_media"    // v, p
+)
+
+// Автор может уйти за геопозицией к точке — ждём долго
+const authorStateTTL = 2 * time.Hour
+
+// Поля метаданных в порядке кнопок редактора; подписи и подсказки —
+// ключи каталога "author.field.<поле>" и "author.prompt.<поле>"
+var authorFields = []string{
+	service.FieldTitle,
+	service.FieldCity,
+	service.FieldDescription,
+	service.FieldTheme,
+	service.FieldDuration,
+	service.FieldLength,
+	service.FieldPrice,
+}
+
+func fieldPrompt(l i18n.Localizer, field string) string {
+	for _, f := range authorFields {
+		if f == field {
+			return l.T("author.prompt." + f)
+		}
+	}
+	return l.T("author.prompt.default")
+}
+
+// handleAuthor — вход в режим автора: список своих маршрутов
+func (h *Handler) handleAuthor(ctx context.Context, chatID int64, usr *models.User) error {
+	routes, err := h.authoring.MyRoutes(ctx, usr)
+	if err != nil {
+		return &authorError{"my routes", err}
+	}
+
+	l := i18n.From(ctx)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range routes {
+		btnText := l.T("author.route_item", r.Title, routeStatusText(l, r.Status))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(btnText, h.cb.AuthorRoute.Data(ctx, idRef{r.RouteID})),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("author.new_route"), CallbackAuthorNew)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu)),
+	)
+
+	text := l.T("author.menu")
+	if len(routes) == 0 {
+		text = l.T("author.menu_empty")
+	}
+	h.sendPlain(chatID, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+	return nil
+}
+
+func (h *Handler) authorNewRoute(u *mux.UpdateCtx, usr *models.User) error {
+	if !usr.IsAuthor() {
+		return &authorError{"new route", service.ErrForbidden}
+	}
+	h.setAuthorState(u, stateAuthorNewTitle, nil)
+	h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.ask_title"))
+	return nil
+}
+
+func (h *Handler) authorEditRoute(ctx context.Context, chatID int64, usr *models.User, routeID int) error {
+	ver, err := h.authoring.EditRoute(ctx, usr, routeID)
+	if err != nil {
+		return &authorError{"edit route", err}
+	}
+	return h.showAuthorEditor(ctx, chatID, usr, ver.ID)
+}
+
+// showAuthorEditor — карточка черновика с кнопками редактирования
+func (h *Handler) showAuthorEditor(ctx context.Context, chatID int64, usr *models.User, versionID int) error {
+	ver, err := h.authoring.Draft(ctx, usr, versionID)
+	if err != nil {
+		return &authorError{"draft", err}
+	}
+	points, err := h.authoring.Points(ctx, usr, versionID)
+	if err != nil {
+		return &authorError{"points", err}
+	}
+
+	l := i18n.From(ctx)
+	var b strings.Builder
+	b.WriteString(l.T("author.editor_header", ver.Title, ver.VersionNumber, routeStatusText(l, ver.Status)))
+	b.WriteString(l.T("author.editor_meta", orDash(ver.City), orDash(ver.Theme)))
+	b.WriteString(l.T("author.editor_numbers", ver.DurationMinutes, ver.LengthKm, ver.Price))
+	b.WriteString(l.T("author.editor_description", orDash(trimTo(300, ver.Description))))
+	b.WriteString(l.N("author.editor_points", len(points)))
+	if ver.SubmittedAt != nil {
+		b.WriteString(l.T("author.editor_submitted", ver.SubmittedAt.Format("02.01.2006 15:04")))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for _, f := range authorFields {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.T("author.field."+f), h.cb.AuthorField.Data(ctx, fieldRef{versionID, f})))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.points_btn", len(points)), h.cb.AuthorPoints.Data(ctx, idRef{versionID})),
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.add_point"), h.cb.AuthorAddPoint.Data(ctx, idRef{versionID})),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.preview"), h.cb.AuthorPreview.Data(ctx, idRef{versionID})),
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.submit"), h.cb.AuthorSubmit.Data(ctx, idRef{versionID})),
+		),
+		h.translationButtons(ctx, l, versionID),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("author.back_to_routes"), CallbackAuthorMenu)),
+	)
+	h.sendPlain(chatID, b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+	return nil
+}
+
+func (h *Handler) authorAskField(u *mux.UpdateCtx, usr *models.User, versionID int, field string) error {
+	if _, err := h.authoring.Draft(u.Ctx, usr, versionID); err != nil {
+		return &authorError{"ask field", err}
+	}
+	h.setAuthorState(u, stateAuthorField, map[string]string{"v": strconv.Itoa(versionID), "f": field})
+	l := i18n.From(u.Ctx)
+	h.sendMessage(u.ChatID, fieldPrompt(l, field)+l.T("author.cancel_hint"))
+	return nil
+}
+
+func (h *Handler) authorAskLocation(u *mux.UpdateCtx, usr *models.User, versionID int) error {
+	if _, err := h.authoring.Draft(u.Ctx, usr, versionID); err != nil {
+		return &authorError{"ask location", err}
+	}
+	h.setAuthorState(u, stateAuthorPointLocation, map[string]string{"v": strconv.Itoa(versionID)})
+	h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.ask_location"))
+	return nil
+}
+
+// showAuthorPoints — список точек с перестановкой и удалением
+func (h *Handler) showAuthorPoints(ctx context.Context, chatID int64, usr *models.User, versionID int) error {
+	points, err := h.authoring.Points(ctx, usr, versionID)
+	if err != nil {
+		return &authorError{"points", err}
+	}
+
+	l := i18n.From(ctx)
+	var b strings.Builder
+	b.WriteString(l.T("author.points_title"))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, p := range points {
+		n := strconv.Itoa(i + 1)
+		fmt.Fprintf(&b, "\n%s. %s", n, p.Title)
+		previewBtn := "👀 " + n
+		if p.IsPreview {
+			b.WriteString(l.T("author.point_sample"))
+			previewBtn = "🔒 " + n
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬆️ "+n, h.cb.AuthorPointOp.Data(ctx, pointOpRef{"up", p.ID})),
+			tgbotapi.NewInlineKeyboardButtonData("⬇️ "+n, h.cb.AuthorPointOp.Data(ctx, pointOpRef{"down", p.ID})),
+			tgbotapi.NewInlineKeyboardButtonData(previewBtn, h.cb.AuthorPointOp.Data(ctx, pointOpRef{"preview", p.ID})),
+			tgbotapi.NewInlineKeyboardButtonData("🗑 "+n, h.cb.AuthorPointOp.Data(ctx, pointOpRef{"del", p.ID})),
+		))
+	}
+	if len(points) == 0 {
+		b.WriteString(l.T("author.points_empty"))
+	} else {
+		b.WriteString(l.T("author.points_sample_hint"))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("author.add_point"), h.cb.AuthorAddPoint.Data(ctx, idRef{versionID}))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("author.back_to_route"), h.cb.AuthorVersion.Data(ctx, idRef{versionID}))),
+	)
+	h.sendPlain(chatID, b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+	return nil
+}
+
+func (h *Handler) authorPointOp(ctx context.Context, chatID int64, usr *models.User, op string, pointID int) error {
+	var (
+		versionID int
+		err       error
+	)
+	switch op {
+	case "up":
+		versionID, err = h.authoring.MovePoint(ctx, usr, pointID, -1)
+	case "down":
+		versionID, err = h.authoring.MovePoint(ctx, usr, pointID, +1)
+	case "preview":
+		versionID, err = h.authoring.TogglePreview(ctx, usr, pointID)
+	case "del":
+		// удаление необратимо — переспрашиваем
+		l := i18n.From(ctx)
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.delete_confirm"), h.cb.AuthorPointOp.Data(ctx, pointOpRef{"delok", pointID})),
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.delete_cancel"), CallbackAuthorMenu),
+		))
+		h.sendPlain(chatID, l.T("author.delete_ask"), kb)
+		return nil
+	case "delok":
+		versionID, err = h.authoring.DeletePoint(ctx, usr, pointID)
+	default:
+		return nil
+	}
+	if err != nil {
+		return &authorError{"point " + op, err}
+	}
+	return h.showAuthorPoints(ctx, chatID, usr, versionID)
+}
+
+func (h *Handler) authorPreview(ctx context.Context, chatID int64, usr *models.User, versionID int) error {
+	ver, err := h.authoring.Draft(ctx, usr, versionID)
+	if err != nil {
+		return &authorError{"preview", err}
+	}
+	res, err := h.run.Preview(ctx, usr.ID, ver)
+	if err != nil {
+		return mux.Internal(i18n.From(ctx).T("author.preview_failed"), fmt.Errorf("start preview of version %d: %w", versionID, err))
+	}
+	h.sendMessage(chatID, i18n.From(ctx).T("author.preview_intro"))
+	h.renderRoutePoint(ctx, chatID, usr.ID, res)
+	return nil
+}
+
+func (h *Handler) authorSubmit(ctx context.Context, chatID int64, usr *models.User, versionID int) error {
+	ver, err := h.authoring.Submit(ctx, usr, versionID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidValue) {
+			return mux.Invalid(i18n.From(ctx).T("author.submit_incomplete"), err)
+		}
+		return &authorError{"submit", err}
+	}
+	h.sendMessage(chatID, i18n.From(ctx).T("author.submitted"))
+
+	admins, err := h.users.Admins(ctx)
+	if err != nil {
+		log.Printf("Error loading admins: %v", err)
+		return nil
+	}
+	for _, a := range admins {
+		l := h.trUser(&a)
+		text := l.T("author.admin_submitted", ver.Title, ver.RouteID, ver.VersionNumber, displayName(l, usr))
+		kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.preview"), h.cb.AuthorPreview.Data(ctx, idRef{ver.ID})),
+			tgbotapi.NewInlineKeyboardButtonData(l.T("author.admin_publish"), h.cb.AdminPublish.Data(ctx, idRef{ver.RouteID})),
+		))
+		h.sendPlain(a.TelegramID, text, kb)
+	}
+	return nil
+}
+
+// === Обработчики состояний (ответы автора)
+
+func (h *Handler) authorOnTitle(u *mux.UpdateCtx, usr *models.User) error {
+	ver, err := h.authoring.CreateRoute(u.Ctx, usr, u.Update.Message.Text)
+	if err != nil {
+		return &authorError{"create route", err}
+	}
+	h.clearAuthorState(u)
+	h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.created"))
+	return h.showAuthorEditor(u.Ctx, u.ChatID, usr, ver.ID)
+}
+
+func (h *Handler) authorOnField(u *mux.UpdateCtx, usr *models.User) error {
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	if err := h.authoring.SetField(u.Ctx, usr, versionID, u.StateData("f"), u.Update.Message.Text); err != nil {
+		return &authorError{"set field", err}
+	}
+	h.clearAuthorState(u)
+	return h.showAuthorEditor(u.Ctx, u.ChatID, usr, versionID)
+}
+
+func (h *Handler) authorOnLocation(u *mux.UpdateCtx, usr *models.User) error {
+	loc := u.Update.Message.Location
+	if loc == nil {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.need_location"))
+		return nil
+	}
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	// название-заглушка на языке автора, пока он не прислал текст точки
+	p, err := h.authoring.AddPoint(u.Ctx, usr, versionID, i18n.From(u.Ctx).T("author.new_point"), loc.Latitude, loc.Longitude)
+	if err != nil {
+		return &authorError{"add point", err}
+	}
+	h.setAuthorState(u, stateAuthorPointText, map[string]string{"v": strconv.Itoa(versionID), "p": strconv.Itoa(p.ID)})
+	h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.ask_point_text"))
+	return nil
+}
+
+func (h *Handler) authorOnPointText(u *mux.UpdateCtx, usr *models.User) error {
+	text := u.Update.Message.Text
+	if text == "" {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.need_point_text"))
+		return nil
+	}
+	pointID, _ := strconv.Atoi(u.StateData("p"))
+	if err := h.authoring.SetPointText(u.Ctx, usr, pointID, text); err != nil {
+		return &authorError{"point text", err}
+	}
+	h.setAuthorState(u, stateAuthorPointMedia, u.State.Data)
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	l := i18n.From(u.Ctx)
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("author.done"), h.cb.AuthorPointDone.Data(u.Ctx, idRef{versionID})),
+	))
+	h.sendPlain(u.ChatID, l.T("author.ask_media"), kb)
+	return nil
+}
+
+func (h *Handler) authorOnPointMedia(u *mux.UpdateCtx, usr *models.User) error {
+	pointID, _ := strconv.Atoi(u.StateData("p"))
+	return h.authorAttachMedia(u.Ctx, u.ChatID, usr, pointID, "", u.Update.Message)
+}
+
+// authorAttachMedia загружает присланное фото/голос в S3 и привязывает к точке.
+// lang — язык озвучки перевода; "" — оригинал.
+func (h *Handler) authorAttachMedia(ctx context.Context, chatID int64, usr *models.User, pointID int, lang string, msg *tgbotapi.Message) error {
+	l := i18n.From(ctx)
+	var (
+		f    tgmedia.TelegramFile
+		done string
+	)
+	switch {
+	case len(msg.Photo) > 0:
+		ph := msg.Photo[len(msg.Photo)-1] // самое крупное превью
+		f = tgmedia.TelegramFile{FileID: ph.FileID, FileName: "photo.jpg", MimeType: "image/jpeg", Size: int64(ph.FileSize), MediaType: "image"}
+		done = l.T("author.photo_added")
+	case msg.Voice != nil:
+		mime := msg.Voice.MimeType
+		if mime == "" {
+			mime = "audio/ogg"
+		}
+		f = tgmedia.TelegramFile{FileID: msg.Voice.FileID, FileName: "voice.ogg", MimeType: mime, Size: int64(msg.Voice.FileSize), MediaType: "audio"}
+		done = l.T("author.audio_added")
+	case msg.Audio != nil:
+		f = tgmedia.TelegramFile{FileID: msg.Audio.FileID, FileName: msg.Audio.FileName, MimeType: msg.Audio.MimeType, Size: int64(msg.Audio.FileSize), MediaType: "audio"}
+		done = l.T("author.audio_added")
+	default:
+		h.sendMessage(chatID, l.T("author.need_media"))
+		return nil
+	}
+
+	mediaID, err := h.tgMedia.ImportTelegramFile(ctx, h.bot, f, usr.ID)
+	if err != nil {
+		return mux.Internal(l.T("author.media_failed"), fmt.Errorf("import telegram file: %w", err))
+	}
+	if lang != "" {
+		err = h.authoring.AttachTranslatedAudio(ctx, usr, pointID, lang, mediaID)
+	} else {
+		err = h.authoring.AttachMedia(ctx, usr, pointID, mediaID)
+	}
+	if err != nil {
+		return &authorError{"attach media", err}
+	}
+	h.sendMessage(chatID, done)
+	return nil
+}
+
+func (h *Handler) setAuthorState(u *mux.UpdateCtx, name string, data map[string]string) {
+	if err := u.SetState(name, data, authorStateTTL); err != nil {
+		log.Printf("Error saving author state: %v", err)
+	}
+}
+
+func (h *Handler) clearAuthorState(u *mux.UpdateCtx) {
+	if err := u.ClearState(); err != nil {
+		log.Printf("Error clearing author state: %v", err)
+	}
+}
+
+// sendPlain — сообщение без разметки (пользовательский текст не ломает Markdown)
+func (h *Handler) sendPlain(chatID int64, text string, markup tgbotapi.InlineKeyboardMarkup) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = markup
+	if _, err := h.bot.Send(msg); err != nil {
+		log.Printf("Error sending message: %v", err)
+	}
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "—"
+	}
+	return s
+}
+
+func displayName(l i18n.Localizer, u *models.User) string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	if strings.TrimSpace(u.FullName) != "" {
+		return u.FullName
+	}
+	return l.T("author.user_name", u.ID)
+}
@@ -1,5 +1,7 @@
 package handlers
 
+import "walki/internal/handlers/mux"
+
 // Идентификаторы callback'ов
 const (
 	CallbackCity           = "city:"
@@ -8,6 +10,10 @@ const (
 	CallbackSelectCity     = "action:select_city"
 	CallbackMainMenu       = "menu:main"
 	CallbackMyRoutes       = "profile:my_routes"
+	CallbackProfile        = "profile:main"
+	CallbackLanguages      = "profile:language"
+	CallbackNotifications  = "profile:notifications"
+	CallbackQuietHours     = "notify:quiet"
 	CallbackStartRoute     = "start_route:"
 	CallbackNextRoute      = "route_next:"
 	CallbackPrevRoute      = "route_prev:"
@@ -15,4 +21,192 @@ const (
 	CallbackContinueRoute  = "route_continue:"
 	CallbackRestartRoute   = "route_restart:"
 	CallbackPurchasedRoute = "purchased_route:"
+	CallbackUpgradeRoute   = "route_upgrade:"
+	CallbackSearch         = "search:"
+	CallbackSearchFilter   = "search_filter:"
+	CallbackNearbyAsk      = "routes:nearby"
+	CallbackNearby         = "nearby:"
+	CallbackCities         = "cities:"
+	CallbackCityPage       = "city_page:"
+	CallbackMyRoutesPage   = "my_routes:"
+	CallbackGallery        = "gallery:"
+	CallbackSample         = "sample:"
+	CallbackFavorite       = "fav:"
+	CallbackFavoriteNotify = "fav_notify:"
+	CallbackFavorites      = "favorites:"
+	CallbackLanguage       = "lang:"
+	CallbackNotifyToggle   = "notify_toggle:"
+	CallbackReview         = "review:"
+
+	// Режим автора
+	CallbackAuthorMenu      = "author:menu"
+	CallbackAuthorNew       = "author:new"
+	CallbackAuthorRoute     = "author_route:"
+	CallbackAuthorVersion   = "author_ver:"
+	CallbackAuthorField     = "author_field:"
+	CallbackAuthorAddPoint  = "author_point_add:"
+	CallbackAuthorPointDone = "author_point_done:"
+	CallbackAuthorPoints    = "author_points:"
+	CallbackAuthorPointOp   = "author_pt:"
+	CallbackAuthorPreview   = "author_preview:"
+	CallbackAuthorSubmit    = "author_submit:"
+	CallbackAdminPublish    = "admin_publish:"
+	CallbackAuthorTr        = "author_tr:"
+	CallbackAuthorTrField   = "author_tr_field:"
+	CallbackAuthorTrPoint   = "author_tr_point:"
+	CallbackAuthorTrArrival = "author_tr_arrival:"
 )
+
+// Данные callback'ов (порядок полей — часть формата, см. mux.CallbackType)
+type (
+	idRef       struct{ ID int }
+	cityRef     struct{ City string }
+	pageRef     struct{ Page int }
+	langRef     struct{ Lang string }
+	kindRef     struct{ Kind string }
+	cityPageRef struct {
+		City string
+		Page int
+	}
+	fieldRef struct {
+		VersionID int
+		Field     string
+	}
+	pointOpRef struct {
+		Op      string
+		PointID int
+	}
+	// trRef, trFieldRef, trPointRef — перевод черновика на язык Lang
+	trRef struct {
+		VersionID int
+		Lang      string
+	}
+	trFieldRef struct {
+		VersionID int
+		Lang      string
+		Field     string
+	}
+	trPointRef struct {
+		VersionID int
+		PointID   int
+		Lang      string
+	}
+	// searchRef — запрос и фильтры поиска; Duration, Length, Price — индексы пресетов,
+	// Field — какой фильтр открыть (только для SearchFilter)
+	searchRef struct {
+		Query    string
+		Theme    string
+		Duration int
+		Length   int
+		Price    int
+		Field    string
+	}
+	// reviewRef — оценка маршрута от 1 до 5
+	reviewRef struct {
+		RouteID int
+		Rating  int
+	}
+	// sampleRef — маршрут и номер пробной точки среди пробных
+	sampleRef struct {
+		ID int
+		N  int
+	}
+	// nearbyRef — точка пользователя в микроградусах и радиус в км
+	nearbyRef struct {
+		Lat    int
+		Lon    int
+		Radius int
+	}
+)
+
+// callbacks — типизированные подписанные callback'и с данными.
+// Кнопки без данных (меню, «назад») остаются строковыми константами.
+type callbacks struct {
+	City           mux.CallbackType[cityRef]
+	Route          mux.CallbackType[idRef]
+	Buy            mux.CallbackType[idRef]
+	StartRoute     mux.CallbackType[idRef]
+	NextRoute      mux.CallbackType[idRef]
+	PrevRoute      mux.CallbackType[idRef]
+	FinishRoute    mux.CallbackType[idRef]
+	ContinueRoute  mux.CallbackType[idRef]
+	RestartRoute   mux.CallbackType[idRef]
+	PurchasedRoute mux.CallbackType[idRef]
+	UpgradeRoute   mux.CallbackType[idRef]
+	Search         mux.CallbackType[searchRef]
+	SearchFilter   mux.CallbackType[searchRef]
+	Nearby         mux.CallbackType[nearbyRef]
+	Cities         mux.CallbackType[pageRef]
+	CityPage       mux.CallbackType[cityPageRef]
+	MyRoutesPage   mux.CallbackType[pageRef]
+	Gallery        mux.CallbackType[idRef]
+	Sample         mux.CallbackType[sampleRef]
+	Favorite       mux.CallbackType[idRef]
+	FavoriteNotify mux.CallbackType[idRef]
+	Favorites      mux.CallbackType[pageRef]
+	Language       mux.CallbackType[langRef]
+	NotifyToggle   mux.CallbackType[kindRef]
+	Review         mux.CallbackType[reviewRef]
+
+	AuthorRoute     mux.CallbackType[idRef]
+	AuthorVersion   mux.CallbackType[idRef]
+	AuthorField     mux.CallbackType[fieldRef]
+	AuthorAddPoint  mux.CallbackType[idRef]
+	AuthorPointDone mux.CallbackType[idRef]
+	AuthorPoints    mux.CallbackType[idRef]
+	AuthorPointOp   mux.CallbackType[pointOpRef]
+	AuthorPreview   mux.CallbackType[idRef]
+	AuthorSubmit    mux.CallbackType[idRef]
+	AdminPublish    mux.CallbackType[idRef]
+
+	AuthorTranslation   mux.CallbackType[trRef]
+	AuthorTrField       mux.CallbackType[trFieldRef]
+	AuthorTrPoint       mux.CallbackType[trPointRef]
+	AuthorTrArrivalSkip mux.CallbackType[trPointRef]
+}
+
+func newCallbacks(c *mux.Codec) callbacks {
+	return callbacks{
+		City:           mux.NewCallback[cityRef](c, CallbackCity),
+		Route:          mux.NewCallback[idRef](c, CallbackRoute),
+		Buy:            mux.NewCallback[idRef](c, CallbackBuy),
+		StartRoute:     mux.NewCallback[idRef](c, CallbackStartRoute),
+		NextRoute:      mux.NewCallback[idRef](c, CallbackNextRoute),
+		PrevRoute:      mux.NewCallback[idRef](c, CallbackPrevRoute),
+		FinishRoute:    mux.NewCallback[idRef](c, CallbackFinishRoute),
+		ContinueRoute:  mux.NewCallback[idRef](c, CallbackContinueRoute),
+		RestartRoute:   mux.NewCallback[idRef](c, CallbackRestartRoute),
+		PurchasedRoute: mux.NewCallback[idRef](c, CallbackPurchasedRoute),
+		UpgradeRoute:   mux.NewCallback[idRef](c, CallbackUpgradeRoute),
+		Search:         mux.NewCallback[searchRef](c, CallbackSearch),
+		SearchFilter:   mux.NewCallback[searchRef](c, CallbackSearchFilter),
+		Nearby:         mux.NewCallback[nearbyRef](c, CallbackNearby),
+		Cities:         mux.NewCallback[pageRef](c, CallbackCities),
+		CityPage:       mux.NewCallback[cityPageRef](c, CallbackCityPage),
+		MyRoutesPage:   mux.NewCallback[pageRef](c, CallbackMyRoutesPage),
+		Gallery:        mux.NewCallback[idRef](c, CallbackGallery),
+		Sample:         mux.NewCallback[sampleRef](c, CallbackSample),
+		Favorite:       mux.NewCallback[idRef](c, CallbackFavorite),
+		FavoriteNotify: mux.NewCallback[idRef](c, CallbackFavoriteNotify),
+		Favorites:      mux.NewCallback[pageRef](c, CallbackFavorites),
+		Language:       mux.NewCallback[langRef](c, CallbackLanguage),
+		NotifyToggle:   mux.NewCallback[kindRef](c, CallbackNotifyToggle),
+		Review:         mux.NewCallback[reviewRef](c, CallbackReview),
+
+		AuthorRoute:     mux.NewCallback[idRef](c, CallbackAuthorRoute),
+		AuthorVersion:   mux.NewCallback[idRef](c, CallbackAuthorVersion),
+		AuthorField:     mux.NewCallback[fieldRef](c, CallbackAuthorField),
+		AuthorAddPoint:  mux.NewCallback[idRef](c, CallbackAuthorAddPoint),
+		AuthorPointDone: mux.NewCallback[idRef](c, CallbackAuthorPointDone),
+		AuthorPoints:    mux.NewCallback[idRef](c, CallbackAuthorPoints),
+		AuthorPointOp:   mux.NewCallback[pointOpRef](c, CallbackAuthorPointOp),
+		AuthorPreview:   mux.NewCallback[idRef](c, CallbackAuthorPreview),
+		AuthorSubmit:    mux.NewCallback[idRef](c, CallbackAuthorSubmit),
+		AdminPublish:    mux.NewCallback[idRef](c, CallbackAdminPublish),
+
+		AuthorTranslation:   mux.NewCallback[trRef](c, CallbackAuthorTr),
+		AuthorTrField:       mux.NewCallback[trFieldRef](c, CallbackAuthorTrField),
+		AuthorTrPoint:       mux.NewCallback[trPointRef](c, CallbackAuthorTrPoint),
+		AuthorTrArrivalSkip: mux.NewCallback[trPointRef](c, CallbackAuthorTrArrival),
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+Диплинки через /start <payload> (https://t.me/<bot>?start=<payload>):
+
+	route_12        — карточка маршрута
+	city_Kazan      — маршруты города (латиница)
+	cityb64_<b64>   — маршруты города с произвольным названием (base64url без паддинга)
+	promo_SUMMER    — активация промокода
+	ref_3f9c0a      — реферал: users.referral_code пригласившего
+
+Telegram допускает в payload только [A-Za-z0-9_-] и не больше 64 символов.
+*/
+
+const (
+	startRoute   = "route_"
+	startCity    = "city_"
+	startCityB64 = "cityb64_"
+	startPromo   = "promo_"
+	startRef     = "ref_"
+
+	maxStartPayload = 64
+)
+
+var startPayloadRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type deepLinkKind int
+
+const (
+	deepLinkNone deepLinkKind = iota
+	deepLinkRoute
+	deepLinkCity
+	deepLinkPromo
+	deepLinkRef
+)
+
+type deepLink struct {
+	Kind    deepLinkKind
+	RouteID int
+	City    string
+	Promo   string
+	Ref     string
+}
+
+// parseDeepLink разбирает payload команды /start; неизвестное — deepLinkNone
+func parseDeepLink(payload string) deepLink {
+	payload = strings.TrimSpace(payload)
+	if !startPayloadRe.MatchString(payload) {
+		return deepLink{}
+	}
+	switch {
+	case strings.HasPrefix(payload, startRoute):
+		if id, err := strconv.Atoi(strings.TrimPrefix(payload, startRoute)); err == nil && id > 0 {
+			return deepLink{Kind: deepLinkRoute, RouteID: id}
+		}
+	case strings.HasPrefix(payload, startCityB64):
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(payload, startCityB64))
+		if err == nil && len(raw) > 0 {
+			return deepLink{Kind: deepLinkCity, City: string(raw)}
+		}
+	case strings.HasPrefix(payload, startCity):
+		if city := strings.TrimPrefix(payload, startCity); city != "" {
+			return deepLink{Kind: deepLinkCity, City: strings.ReplaceAll(city, "_", " ")}
+		}
+	case strings.HasPrefix(payload, startPromo):
+		if code := strings.TrimPrefix(payload, startPromo); code != "" {
+			return deepLink{Kind: deepLinkPromo, Promo: code}
+		}
+	case strings.HasPrefix(payload, startRef):
+		if code := strings.TrimPrefix(payload, startRef); code != "" {
+			return deepLink{Kind: deepLinkRef, Ref: code}
+		}
+	}
+	return deepLink{}
+}
+
+func routeStartPayload(routeID int) string { return startRoute + strconv.Itoa(routeID) }
+
+// cityStartPayload: латиница без пробелов остаётся читаемой, остальное — base64url
+func cityStartPayload(city string) string {
+	if p := startCity + strings.ReplaceAll(city, " ", "_"); startPayloadRe.MatchString(p) && !strings.Contains(city, "_") {
+		return p
+	}
+	p := startCityB64 + base64.RawURLEncoding.EncodeToString([]byte(city))
+	if len(p) > maxStartPayload {
+		return ""
+	}
+	return p
+}
+
+func refStartPayload(referralCode string) string { return startRef + referralCode }
+
+// startLink — https://t.me/<bot>?start=<payload>
+func startLink(botUsername, payload string) string {
+	return "https://t.me/" + botUsername + "?start=" + payload
+}
+
+// shareURL открывает в Telegram выбор чата для пересылки ссылки
+func shareURL(link, text string) string {
+	return "https://t.me/share/url?url=" + url.QueryEscape(link) + "&text=" + url.QueryEscape(text)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/handlers/mux/middlewares"
+	"walki/internal/models"
+	"walki/internal/service"
+)
+
+// errNoUser — апдейт от пользователя, которого ещё нет в БД (не нажимал /start)
+var errNoUser = &mux.Error{Kind: mux.KindNotFound, Key: "error.no_user"}
+
+// requireUser — пользователь из контекста (см. middlewares.WithUser) или errNoUser
+func requireUser(u *mux.UpdateCtx) (*models.User, error) {
+	usr := middlewares.UserFrom(u.Ctx)
+	if usr == nil {
+		return nil, errNoUser
+	}
+	return usr, nil
+}
+
+// authorError — ошибка в режиме автора: ответы со своими текстами (см. translateError)
+type authorError struct {
+	op  string
+	err error
+}
+
+func (e *authorError) Error() string { return "author " + e.op + ": " + e.err.Error() }
+func (e *authorError) Unwrap() error { return e.err }
+
+// publicationError — ошибка команды публикации над маршрутом
+type publicationError struct {
+	op      string
+	routeID int
+	err     error
+}
+
+func (e *publicationError) Error() string {
+	return fmt.Sprintf("%s route %d: %v", e.op, e.routeID, e.err)
+}
+func (e *publicationError) Unwrap() error { return e.err }
+
+// translateError сопоставляет ошибки сервисов с ответами пользователю (см. middlewares.Errors)
+func translateError(err error) *mux.Error {
+	var ae *authorError
+	if errors.As(err, &ae) {
+		return translateAuthorError(err)
+	}
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrNoAccess):
+		return mux.Forbidden("", err)
+	case errors.Is(err, service.ErrInvalidTransition):
+		return &mux.Error{Kind: mux.KindValidation, Key: "error.invalid_transition", Err: err}
+	case errors.Is(err, service.ErrInvalidValue):
+		return mux.Invalid("", err)
+	}
+	var pe *publicationError
+	if errors.As(err, &pe) {
+		return &mux.Error{Kind: mux.KindInternal, Key: "admin.route_unavailable", Err: err}
+	}
+	return nil
+}
+
+// translateAuthorError — то же для режима автора: гиду объясняем, что не так с черновиком
+func translateAuthorError(err error) *mux.Error {
+	switch {
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrNoAccess):
+		return &mux.Error{Kind: mux.KindForbidden, Key: "author.forbidden", Err: err}
+	case errors.Is(err, service.ErrInvalidTransition):
+		return &mux.Error{Kind: mux.KindValidation, Key: "author.published_version", Err: err}
+	case errors.Is(err, service.ErrInvalidValue):
+		return &mux.Error{Kind: mux.KindValidation, Key: "author.invalid_value", Err: err}
+	}
+	return &mux.Error{Kind: mux.KindInternal, Key: "author.error", Err: err}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/keyboards"
+	"walki/internal/models"
+)
+
+// favoriteButtons — кнопки избранного для карточки маршрута
+func (h *Handler) favoriteButtons(ctx context.Context, l i18n.Localizer, userID, routeID int) []tgbotapi.InlineKeyboardButton {
+	addBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("fav.add"), h.cb.Favorite.Data(ctx, idRef{routeID}))
+	if userID == 0 {
+		return []tgbotapi.InlineKeyboardButton{addBtn}
+	}
+	fav, notify, err := h.profile.Favorite(ctx, userID, routeID)
+	if err != nil {
+		log.Printf("Error getting favorite state: %v", err)
+	}
+	if !fav {
+		return []tgbotapi.InlineKeyboardButton{addBtn}
+	}
+	notifyText := l.T("fav.notify_off")
+	if notify {
+		notifyText = l.T("fav.notify_on")
+	}
+	return []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData(l.T("fav.added"), h.cb.Favorite.Data(ctx, idRef{routeID})),
+		tgbotapi.NewInlineKeyboardButtonData(notifyText, h.cb.FavoriteNotify.Data(ctx, idRef{routeID})),
+	}
+}
+
+// toggleFavorite — кнопка на карточке; карточка перерисовывается на месте
+func (h *Handler) toggleFavorite(u *mux.UpdateCtx, v idRef) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if _, err := h.profile.ToggleFavorite(u.Ctx, usr.ID, v.ID); err != nil {
+		return err
+	}
+	return h.showRouteDetails(screenOf(u), usr.ID, v.ID)
+}
+
+func (h *Handler) toggleFavoriteNotify(u *mux.UpdateCtx, v idRef) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if err := h.profile.ToggleFavoriteNotify(u.Ctx, usr.ID, v.ID); err != nil {
+		return err
+	}
+	return h.showRouteDetails(screenOf(u), usr.ID, v.ID)
+}
+
+// showFavorites — страница избранных маршрутов
+func (h *Handler) showFavorites(t screenTarget, userID, page int) {
+	l := i18n.From(t.Ctx)
+	routes, total, page, err := loadPage(page, func(limit, offset int) ([]models.RouteVersion, int, error) {
+		return h.profile.FavoritesPage(t.Ctx, userID, limit, offset)
+	})
+	if err != nil {
+		log.Printf("Error getting favorites: %v", err)
+		h.sendMessage(t.ChatID, l.T("fav.error"))
+		return
+	}
+
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackMainMenu)
+	if len(routes) == 0 {
+		h.showScreen(t, screen{
+			Text:   l.T("fav.empty"),
+			Markup: tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(backBtn)),
+		})
+		return
+	}
+
+	var items []tgbotapi.InlineKeyboardButton
+	for _, route := range routes {
+		btnText := l.T("fav.item", route.Title, route.City)
+		items = append(items, tgbotapi.NewInlineKeyboardButtonData(btnText, h.cb.Route.Data(t.Ctx, idRef{route.RouteID})))
+	}
+	nav := keyboards.PageNav(page, listPageSize, total, func(p int) string {
+		return h.cb.Favorites.Data(t.Ctx, pageRef{p})
+	})
+	markup := keyboards.Paginated(items, nav, tgbotapi.NewInlineKeyboardRow(backBtn))
+
+	h.showScreen(t, screen{Text: l.T("fav.title"), Markup: markup})
+}
+
+// notifyFavorites рассылает подписчикам новость о публикации: скидку или новую версию.
+// Рассылка несрочная — через очередь отправителя.
+func (h *Handler) notifyFavorites(ctx context.Context, ver, prev *models.RouteVersion) {
+	notice, err := h.profile.FavoriteNotice(ctx, ver, prev)
+	if err != nil {
+		log.Printf("Error preparing favorites notice for route %d: %v", ver.RouteID, err)
+		return
+	}
+	if notice == nil {
+		return
+	}
+
+	// название маршрута — на языке подписчика, перевод один на язык
+	titles := map[string]string{}
+	for i := range notice.Subscribers {
+		sub := &notice.Subscribers[i]
+		l := h.trUser(sub)
+		title, ok := titles[l.Lang()]
+		if !ok {
+			title = ver.Title
+			if tv, err := h.routes.Translated(i18n.WithLang(ctx, l.Lang()), ver); err != nil {
+				log.Printf("Error translating route %d: %v", ver.RouteID, err)
+			} else {
+				title = tv.Title
+			}
+			titles[l.Lang()] = title
+		}
+		text := l.T("fav.new_version", title, ver.VersionNumber)
+		if notice.Discount {
+			text = l.T("fav.discount", title, ver.Price, notice.OldPrice)
+		}
+		msg := tgbotapi.NewMessage(sub.TelegramID, text)
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("fav.view"), h.cb.Route.Data(ctx, idRef{ver.RouteID})),
+		))
+		if err := h.bot.Enqueue(msg); err != nil {
+			log.Printf("Error enqueueing favorites notice to %d: %v", sub.TelegramID, err)
+		}
+	}
+}
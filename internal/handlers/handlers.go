@@ -5,129 +5,327 @@ import (
 	"fmt"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"log"
-	"strconv"
 	"walki/internal/constants"
 	"walki/internal/handlers/mux"
 	"walki/internal/handlers/mux/middlewares"
+	"walki/internal/i18n"
 	"walki/internal/keyboards"
+	"walki/internal/models"
+	"walki/internal/sender"
 	"walki/internal/service"
 	"walki/internal/service/tgmedia"
 )
 
 // Handler обрабатывает входящие сообщения и callback'и
 type Handler struct {
-	bot *tgbotapi.BotAPI
+	bot *sender.Sender // все исходящие запросы идут через лимиты и повторы
 	// сервисы
-	routes  *service.RouteService
-	orders  *service.OrderService
-	profile *service.ProfileService
-	users   *service.UserService
-	run     *service.RouteRunService
-	tgMedia *tgmedia.Service
-	router  *mux.Router
+	routes      *service.RouteService
+	orders      *service.OrderService
+	profile     *service.ProfileService
+	users       *service.UserService
+	run         *service.RouteRunService
+	publication *service.PublicationService
+	authoring   *service.AuthoringService
+	moderation  *service.ModerationService
+	reminders   *service.NotificationService
+	tgMedia     *tgmedia.Service
+	router      *mux.Router
+	cb          callbacks
+	screens     *screenTracker
 }
 
 // Чистый конструктор с DI (используется из app/bot)
-func NewHandler(bot *tgbotapi.BotAPI,
+func NewHandler(bot *sender.Sender,
 	rs *service.RouteService,
 	os *service.OrderService,
 	ps *service.ProfileService,
 	userSvc *service.UserService,
 	runSvc *service.RouteRunService,
+	pubSvc *service.PublicationService,
+	authSvc *service.AuthoringService,
+	modSvc *service.ModerationService,
+	notifySvc *service.NotificationService,
+	states mux.StateStore,
+	codec *mux.Codec,
 	tg *tgmedia.Service) *Handler {
 	h := &Handler{
-		bot:     bot,
-		routes:  rs,
-		orders:  os,
-		profile: ps,
-		users:   userSvc,
-		run:     runSvc,
-		tgMedia: tg,
+		bot:         bot,
+		routes:      rs,
+		orders:      os,
+		profile:     ps,
+		users:       userSvc,
+		run:         runSvc,
+		publication: pubSvc,
+		authoring:   authSvc,
+		moderation:  modSvc,
+		reminders:   notifySvc,
+		tgMedia:     tg,
+		cb:          newCallbacks(codec),
+		screens:     newScreenTracker(),
 	}
 
 	// --- Router  middlewares
 	r := mux.New()
 	r.Use(middlewares.Logging())
+	r.Use(middlewares.Language())
+	r.Use(middlewares.Errors(translateError))
+	r.Use(middlewares.Recover())
+	r.Use(middlewares.Banned(h.moderation))
+	limits := middlewares.DefaultRateLimits()
+	limits.Classify = classifyUpdate
+	r.Use(middlewares.RateLimit(limits)) // до AnswerCallback: отказ отвечает на callback сам
 	r.Use(middlewares.AnswerCallback())
 	r.Use(middlewares.WithUser(h.users))
 	//r.Use(middlewares.Timeout(5 * time.Second)) // при желании
 
+	// --- Диалоги (FSM): /cancel и таймауты
+	r.States(states)
+	r.OnCancel(func(u *mux.UpdateCtx) error {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("common.cancelled"))
+		return nil
+	})
+	r.OnStateTimeout(func(u *mux.UpdateCtx) error {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("common.state_timeout"))
+		return nil
+	})
+
 	// === Команды
-	r.Command("start", func(u *mux.UpdateCtx) error { h.handleStart(u.Update); return nil })
-	r.Command("routes", func(u *mux.UpdateCtx) error { h.handleRoutes(u.Update); return nil })
-	r.Command("profile", func(u *mux.UpdateCtx) error { h.handleProfile(u.Update); return nil })
-	r.Command("help", func(u *mux.UpdateCtx) error { h.handleHelp(u.Update); return nil })
+	r.Command("start", func(u *mux.UpdateCtx) error { return h.handleStart(u) })
+	r.Command("routes", h.handleRoutes)
+	r.Command("profile", func(u *mux.UpdateCtx) error { h.handleProfile(u); return nil })
+	r.Command("help", func(u *mux.UpdateCtx) error { h.handleHelp(u); return nil })
+	r.Command("search", h.handleSearch)
 
-	// === Кнопки главного меню (точный текст)
-	r.Message(keyboards.ButtonTexts[constants.BtnRoutes], func(u *mux.UpdateCtx) error { h.handleRoutes(u.Update); return nil })
-	r.Message(keyboards.ButtonTexts[constants.BtnProfile], func(u *mux.UpdateCtx) error { h.handleProfile(u.Update); return nil })
-	r.Message(keyboards.ButtonTexts[constants.BtnHelp], func(u *mux.UpdateCtx) error { h.handleHelp(u.Update); return nil })
+	// === Inline-режим: поиск маршрутов из любого чата
+	r.OnInlineQuery(h.handleInlineQuery)
 
-	// === Callback’и (точные)
-	r.CallbackExact("action:select_city", func(u *mux.UpdateCtx) error { h.showCitySelection(u.ChatID); return nil })
-	r.CallbackExact("menu:main", func(u *mux.UpdateCtx) error { h.showMainMenu(u.ChatID); return nil })
-	r.CallbackExact("profile:my_routes", func(u *mux.UpdateCtx) error {
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
+	// === Публикация (админы; предпросмотр и история — ещё и авторам)
+	r.Command("publish", h.routeAdminCommand(h.handlePublish))
+	r.Command("archive", h.routeAdminCommand(h.handleArchive))
+	r.Command("restore", h.routeAdminCommand(h.handleRestore))
+	r.Command("hide", h.routeAdminCommand(h.handleSetVisible(false)))
+	r.Command("show", h.routeAdminCommand(h.handleSetVisible(true)))
+	r.Command("history", h.routeAdminCommand(h.handleHistory))
+	r.Command("preview", h.routeAdminCommand(h.handlePreview))
+
+	// === Только для админов: модерация пользователей и отладка
+	admin := r.Group("admin", middlewares.AdminOnly())
+	admin.Command("ban", h.handleBan)
+	admin.Command("unban", h.handleUnban)
+	admin.Command("debug_routes", h.handleRoutesDebug)
+
+	// === Режим автора
+	r.Command("author", func(u *mux.UpdateCtx) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.handleAuthor(u.Ctx, u.ChatID, usr)
+	})
+	r.CallbackExact(CallbackAuthorMenu, h.userCallback(func(ctx context.Context, chatID int64, usr *models.User, _ mux.Values) error {
+		return h.handleAuthor(ctx, chatID, usr)
+	}))
+	r.CallbackExact(CallbackAuthorNew, h.userHandler(h.authorNewRoute))
+	h.cb.AuthorRoute.Handle(r, h.userCallbackID(h.authorEditRoute))
+	h.cb.AuthorVersion.Handle(r, h.userCallbackID(h.showAuthorEditor))
+	h.cb.AuthorPoints.Handle(r, h.userCallbackID(h.showAuthorPoints))
+	h.cb.AuthorAddPoint.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error {
+			versionID := v.ID
+			return h.authorAskLocation(u, usr, versionID)
+		})(u)
+	})
+	h.cb.AuthorPointDone.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error {
+			versionID := v.ID
+			h.clearAuthorState(u)
+			return h.showAuthorEditor(u.Ctx, u.ChatID, usr, versionID)
+		})(u)
+	})
+	h.cb.AuthorPreview.Handle(r, h.userCallbackID(h.authorPreview))
+	h.cb.AuthorSubmit.Handle(r, h.userCallbackID(h.authorSubmit))
+	h.cb.AdminPublish.Handle(r, h.userCallbackID(h.handlePublish))
+	h.cb.AuthorField.Handle(r, func(u *mux.UpdateCtx, v fieldRef) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.authorAskField(u, usr, v.VersionID, v.Field)
+	})
+	h.cb.AuthorPointOp.Handle(r, func(u *mux.UpdateCtx, v pointOpRef) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.authorPointOp(u.Ctx, u.ChatID, usr, v.Op, v.PointID)
+	})
+	r.State(stateAuthorNewTitle, h.userHandler(h.authorOnTitle))
+	r.State(stateAuthorField, h.userHandler(h.authorOnField))
+	r.State(stateAuthorPointLocation, h.userHandler(h.authorOnLocation))
+	r.State(stateAuthorPointText, h.userHandler(h.authorOnPointText))
+	r.State(stateAuthorPointMedia, h.userHandler(h.authorOnPointMedia))
+
+	// === Переводы маршрута
+	h.cb.AuthorTranslation.Handle(r, func(u *mux.UpdateCtx, v trRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error {
+			h.clearAuthorState(u)
+			return h.showAuthorTranslation(u.Ctx, u.ChatID, usr, v.VersionID, v.Lang)
+		})(u)
+	})
+	h.cb.AuthorTrField.Handle(r, func(u *mux.UpdateCtx, v trFieldRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error { return h.authorAskTrField(u, usr, v) })(u)
+	})
+	h.cb.AuthorTrPoint.Handle(r, func(u *mux.UpdateCtx, v trPointRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error { return h.authorAskTrPoint(u, usr, v) })(u)
+	})
+	h.cb.AuthorTrArrivalSkip.Handle(r, func(u *mux.UpdateCtx, v trPointRef) error {
+		return h.userHandler(func(u *mux.UpdateCtx, usr *models.User) error {
+			if _, err := h.authoring.Translation(u.Ctx, usr, v.VersionID, v.Lang); err != nil {
+				return &authorError{"skip arrival translation", err}
+			}
+			h.authorAskTrAudio(u, v)
 			return nil
+		})(u)
+	})
+	r.State(stateAuthorTrField, h.userHandler(h.authorOnTrField))
+	r.State(stateAuthorTrPointText, h.userHandler(h.authorOnTrPointText))
+	r.State(stateAuthorTrPointArrival, h.userHandler(h.authorOnTrPointArrival))
+	r.State(stateAuthorTrPointAudio, h.userHandler(h.authorOnTrPointAudio))
+
+	// === Кнопки главного меню (точный текст на любом из языков)
+	button := func(btn string, fn mux.HandlerFunc) {
+		for _, text := range keyboards.Variants(btn) {
+			r.Message(text, fn)
 		}
-		h.showUserRoutes(u.ChatID, usr.ID)
+	}
+	button(constants.BtnRoutes, h.handleRoutes)
+	button(constants.BtnSearch, h.handleSearch)
+	button(constants.BtnProfile, func(u *mux.UpdateCtx) error { h.handleProfile(u); return nil })
+	button(constants.BtnHelp, func(u *mux.UpdateCtx) error { h.handleHelp(u); return nil })
+	button(constants.BtnMainMenu, func(u *mux.UpdateCtx) error { h.showMainMenu(u.Ctx, u.ChatID); return nil })
+
+	// === Callback’и (точные)
+	r.CallbackExact("action:select_city", func(u *mux.UpdateCtx) error { return h.showCitySelection(screenOf(u), 0) })
+	r.CallbackExact(keyboards.CallbackNoop, func(*mux.UpdateCtx) error { return nil })
+	r.CallbackExact("menu:main", func(u *mux.UpdateCtx) error {
+		// главное меню — reply-клавиатура: экран с кнопками больше не нужен
+		if t := screenOf(u); t.Msg != nil {
+			h.disableScreen(t.ChatID, t.Msg.MessageID)
+		}
+		h.showMainMenu(u.Ctx, u.ChatID)
 		return nil
 	})
+	r.CallbackExact("profile:my_routes", func(u *mux.UpdateCtx) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.showUserRoutes(screenOf(u), usr.ID, 0)
+	})
 
-	// === Callback’и (префиксы)
-	r.CallbackPrefix(CallbackCity, func(u *mux.UpdateCtx, v mux.Values) error {
-		h.showRoutesByCity(u.ChatID, v["id"])
+	// === Листание списков
+	h.cb.Cities.Handle(r, func(u *mux.UpdateCtx, v pageRef) error {
+		return h.showCitySelection(screenOf(u), v.Page)
+	})
+	h.cb.CityPage.Handle(r, func(u *mux.UpdateCtx, v cityPageRef) error {
+		return h.showRoutesByCity(screenOf(u), v.City, v.Page)
+	})
+	h.cb.MyRoutesPage.Handle(r, func(u *mux.UpdateCtx, v pageRef) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.showUserRoutes(screenOf(u), usr.ID, v.Page)
+	})
+
+	h.cb.Favorites.Handle(r, func(u *mux.UpdateCtx, v pageRef) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		h.showFavorites(screenOf(u), usr.ID, v.Page)
 		return nil
 	})
-	r.CallbackPrefix(CallbackRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		id, _ := strconv.Atoi(v["id"])
-		h.showRouteDetails(u.ChatID, id)
+
+	// === Язык бота
+	r.CallbackExact(CallbackProfile, func(u *mux.UpdateCtx) error {
+		h.showProfile(screenOf(u), u.Update.SentFrom().ID)
 		return nil
 	})
-	r.CallbackPrefix(CallbackBuy, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	r.CallbackExact(CallbackLanguages, h.showLanguages)
+	h.cb.Language.Handle(r, h.setLanguage)
+
+	// === Уведомления: настройки и ответ на просьбу об отзыве
+	r.CallbackExact(CallbackNotifications, h.showNotificationSettings)
+	r.CallbackExact(CallbackQuietHours, h.cycleQuietHours)
+	h.cb.NotifyToggle.Handle(r, h.toggleNotification)
+	h.cb.Review.Handle(r, h.rateRoute)
+
+	// === Поиск по каталогу
+	r.State(stateSearchQuery, h.searchOnQuery)
+	h.cb.Search.Handle(r, h.showSearchResults)
+	h.cb.SearchFilter.Handle(r, h.showSearchFilter)
+
+	// === Маршруты рядом: геопозиция вне диалогов
+	r.CallbackExact(CallbackNearbyAsk, h.askNearbyLocation)
+	r.OnLocation(h.handleLocation)
+	h.cb.Nearby.Handle(r, h.showNearby)
+
+	// === Callback’и (префиксы)
+	h.cb.City.Handle(r, func(u *mux.UpdateCtx, v cityRef) error {
+		return h.showRoutesByCity(screenOf(u), v.City, 0)
+	})
+	h.cb.Route.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		id := v.ID
+		userID := 0 // без пользователя карточка просто не знает об избранном
+		if usr := middlewares.UserFrom(u.Ctx); usr != nil {
+			userID = usr.ID
 		}
-		h.handlePurchase(u.ChatID, usr, routeID)
-		return nil
+		return h.showRouteDetails(screenOf(u), userID, id)
 	})
-	r.CallbackPrefix(CallbackPurchasedRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.Favorite.Handle(r, h.toggleFavorite)
+	h.cb.FavoriteNotify.Handle(r, h.toggleFavoriteNotify)
+	h.cb.Gallery.Handle(r, func(u *mux.UpdateCtx, v idRef) error { return h.showGallery(u, v.ID) })
+	h.cb.Sample.Handle(r, h.showSample)
+	h.cb.Buy.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
-		h.showPurchasedRouteDetails(u.ChatID, usr.ID, routeID)
-		return nil
+		return h.handlePurchase(screenOf(u), usr, routeID)
+	})
+	h.cb.PurchasedRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return h.showPurchasedRouteDetails(screenOf(u), usr.ID, routeID)
 	})
 	// в NewHandler, там где регистрируешь router r := mux.New()
-	r.CallbackPrefix(CallbackStartRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.StartRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
 
 		// есть ли незавершённый прогресс?
 		if pr, _ := h.run.Progress(u.Ctx, usr.ID, routeID); pr != nil && pr.FinishedAt == nil {
 			// спросим: продолжить или начать заново
+			l := i18n.From(u.Ctx)
 			kb := tgbotapi.NewInlineKeyboardMarkup(
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("▶️ Продолжить", fmt.Sprintf("route_continue:%d", routeID)),
-					tgbotapi.NewInlineKeyboardButtonData("🔁 Начать заново", fmt.Sprintf("route_restart:%d", routeID)),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("run.continue"), h.cb.ContinueRoute.Data(u.Ctx, idRef{routeID})),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("run.restart"), h.cb.RestartRoute.Data(u.Ctx, idRef{routeID})),
 				),
 				tgbotapi.NewInlineKeyboardRow(
-					tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "menu:main"),
+					tgbotapi.NewInlineKeyboardButtonData(l.T("common.cancel"), "menu:main"),
 				),
 			)
-			msg := tgbotapi.NewMessage(u.ChatID, "У вас уже начат маршрут. Что сделать?")
+			msg := tgbotapi.NewMessage(u.ChatID, l.T("run.already_started"))
 			msg.ReplyMarkup = kb
 			_, _ = h.bot.Send(msg)
 			return nil
@@ -136,116 +334,120 @@ func NewHandler(bot *tgbotapi.BotAPI,
 		// иначе — стартуем
 		res, err := h.run.Start(u.Ctx, usr.ID, routeID)
 		if err != nil {
-			h.sendMessage(u.ChatID, "Нет доступа или маршрут пуст.")
-			return nil
+			return mux.Forbidden(i18n.From(u.Ctx).T("run.no_access"), err)
 		}
-		h.renderRoutePoint(u.ChatID, usr.ID, res)
+		h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 		return nil
 	})
 
-	r.CallbackPrefix(CallbackContinueRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.ContinueRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
 
 		res, ok, err := h.run.Continue(u.Ctx, usr.ID, routeID)
 		if err != nil {
-			h.sendMessage(u.ChatID, "Произошла ошибка.")
-			return nil
+			return mux.Internal("", err)
 		}
 		if !ok {
-			h.sendMessage(u.ChatID, "🏁 Маршрут уже завершён.")
+			h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("run.already_finished"))
 			return nil
 		}
-		h.renderRoutePoint(u.ChatID, usr.ID, res)
+		h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 		return nil
 	})
 
-	r.CallbackPrefix(CallbackRestartRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.RestartRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
 
 		res, err := h.run.Restart(u.Ctx, usr.ID, routeID)
 		if err != nil {
-			h.sendMessage(u.ChatID, "Не получилось начать заново.")
-			return nil
+			return mux.Internal(i18n.From(u.Ctx).T("run.restart_failed"), err)
 		}
-		h.renderRoutePoint(u.ChatID, usr.ID, res)
+		h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 		return nil
 	})
 
-	r.CallbackPrefix(CallbackNextRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
+	h.cb.UpgradeRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+
+		ver, res, err := h.run.Upgrade(u.Ctx, usr.ID, routeID)
+		if err != nil {
+			return mux.Internal(i18n.From(u.Ctx).T("run.upgrade_failed"), fmt.Errorf("upgrade route %d: %w", routeID, err))
+		}
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("run.upgraded", ver.VersionNumber))
+		if res != nil {
+			h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 			return nil
 		}
+		return h.showPurchasedRouteDetails(newScreen(u.Ctx, u.ChatID), usr.ID, routeID)
+	})
+
+	h.cb.NextRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
 
 		res, ok, err := h.run.Continue(u.Ctx, usr.ID, routeID)
 		if err != nil {
-			h.sendMessage(u.ChatID, "Ошибка, попробуйте ещё раз.")
-			return nil
+			return mux.Internal("", err)
 		}
 		if !ok {
-			h.sendMessage(u.ChatID, "🏁 Маршрут завершён! Возвращайтесь в профиль, чтобы пройти ещё раз.")
+			h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("run.finished_last"))
 			return nil
 		}
-		h.renderRoutePoint(u.ChatID, usr.ID, res)
+		h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 		return nil
 	})
 
-	r.CallbackPrefix(CallbackPrevRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.PrevRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
 
 		res, ok, err := h.run.Prev(u.Ctx, usr.ID, routeID)
 		if err != nil {
-			h.sendMessage(u.ChatID, "Ошибка, попробуйте ещё раз.")
-			return nil
+			return mux.Internal("", err)
 		}
 		if !ok {
-			h.sendMessage(u.ChatID, "Это первая точка маршрута.")
+			h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("run.first_point"))
 			return nil
 		}
-		h.renderRoutePoint(u.ChatID, usr.ID, res)
+		h.renderRoutePoint(u.Ctx, u.ChatID, usr.ID, res)
 		return nil
 	})
 
-	r.CallbackPrefix(CallbackFinishRoute, func(u *mux.UpdateCtx, v mux.Values) error {
-		routeID, _ := strconv.Atoi(v["id"])
-		usr := middlewares.UserFrom(u.Ctx)
-		if usr == nil {
-			h.sendMessage(u.ChatID, "Ошибка: пользователь не найден, попробуйте /start")
-			return nil
+	h.cb.FinishRoute.Handle(r, func(u *mux.UpdateCtx, v idRef) error {
+		routeID := v.ID
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
 		}
 
-		ver, _ := h.routes.Details(u.Ctx, routeID)
-		if ver != nil {
-			err := h.run.Finish(u.Ctx, usr.ID, ver.ID)
-			if err != nil {
-				h.sendMessage(u.ChatID, "Ошибка: не удалось завершить маршрут, обратитесь в поддержку")
-				return nil
-			}
+		if err := h.run.FinishRoute(u.Ctx, usr.ID, routeID); err != nil {
+			return mux.Internal(i18n.From(u.Ctx).T("run.finish_failed"), err)
 		}
-		h.sendMessage(u.ChatID, "🏁 Маршрут завершён! Спасибо за прогулку.")
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("run.finished"))
 		return nil
 	})
 
 	// Дефолт
 	r.Default(func(u *mux.UpdateCtx) error {
-		_, _ = h.bot.Send(tgbotapi.NewMessage(u.ChatID, "Выбери действие с клавиатуры ⌨️"))
+		_, _ = h.bot.Send(tgbotapi.NewMessage(u.ChatID, i18n.From(u.Ctx).T("common.default")))
 		return nil
 	})
 
@@ -254,31 +456,65 @@ func NewHandler(bot *tgbotapi.BotAPI,
 	return h
 }
 
-// HandleUpdate обрабатывает входящее сообщение
-func (h *Handler) HandleUpdate(update tgbotapi.Update) {
-	if update.Message == nil {
-		return
+// userHandler — обёртка для обработчиков, которым нужен пользователь из БД
+func (h *Handler) userHandler(fn func(u *mux.UpdateCtx, usr *models.User) error) mux.HandlerFunc {
+	return func(u *mux.UpdateCtx) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return fn(u, usr)
 	}
-	_ = h.router.Dispatch(&mux.UpdateCtx{
-		Ctx:    context.Background(),
-		Update: update,
-		ChatID: update.Message.Chat.ID,
-		Sender: h.bot,
-	})
 }
 
-func (h *Handler) HandleCallback(update tgbotapi.Update) {
-	if update.CallbackQuery == nil {
-		return
+// userCallback — обёртка для callback'ов, которым нужен пользователь из БД
+func (h *Handler) userCallback(fn func(ctx context.Context, chatID int64, usr *models.User, v mux.Values) error) mux.HandlerFunc {
+	return func(u *mux.UpdateCtx) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return fn(u.Ctx, u.ChatID, usr, mux.Parse(u.Update.CallbackQuery.Data))
+	}
+}
+
+// userCallbackID — то же для callback'ов вида "prefix:<id>"
+func (h *Handler) userCallbackID(fn func(ctx context.Context, chatID int64, usr *models.User, id int) error) func(*mux.UpdateCtx, idRef) error {
+	return func(u *mux.UpdateCtx, v idRef) error {
+		usr, err := requireUser(u)
+		if err != nil {
+			return err
+		}
+		return fn(u.Ctx, u.ChatID, usr, v.ID)
 	}
+}
+
+// Handle прогоняет апдейт любого типа через роутер. ctx отменяется при остановке,
+// если обработчик не уложился в отведённое время.
+func (h *Handler) Handle(ctx context.Context, update tgbotapi.Update) {
 	_ = h.router.Dispatch(&mux.UpdateCtx{
-		Ctx:    context.Background(),
+		Ctx:    ctx,
 		Update: update,
-		ChatID: update.CallbackQuery.Message.Chat.ID,
+		ChatID: updateChatID(update),
 		Sender: h.bot,
 	})
 }
 
+// updateChatID — куда отвечать; у inline-запросов чата нет
+func updateChatID(u tgbotapi.Update) int64 {
+	switch {
+	case u.Message != nil:
+		return u.Message.Chat.ID
+	case u.EditedMessage != nil:
+		return u.EditedMessage.Chat.ID
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		return u.CallbackQuery.Message.Chat.ID
+	case u.PreCheckoutQuery != nil:
+		return u.PreCheckoutQuery.From.ID // оплата идёт в личке
+	}
+	return 0
+}
+
 // sendMessage отправляет текстовое сообщение
 func (h *Handler) sendMessage(chatID int64, text string) {
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -288,9 +524,10 @@ func (h *Handler) sendMessage(chatID int64, text string) {
 }
 
 // showMainMenu показывает главное меню
-func (h *Handler) showMainMenu(chatID int64) {
-	msg := tgbotapi.NewMessage(chatID, "Главное меню")
-	msg.ReplyMarkup = keyboards.MainMenu()
+func (h *Handler) showMainMenu(ctx context.Context, chatID int64) {
+	l := i18n.From(ctx)
+	msg := tgbotapi.NewMessage(chatID, l.T("menu.main"))
+	msg.ReplyMarkup = keyboards.MainMenu(l)
 	if _, err := h.bot.Send(msg); err != nil {
 		log.Printf("Error sending message: %v", err)
 	}
@@ -1,10 +1,14 @@
 package handlers
 
-import tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+import (
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
 
-func (h *Handler) handleHelp(update tgbotapi.Update) {
-	chatID := update.Message.Chat.ID
-	h.bot.Send(tgbotapi.NewMessage(chatID, "ℹ️ Тут будет помощь"))
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleHelp(u *mux.UpdateCtx) {
+	h.bot.Send(tgbotapi.NewMessage(u.ChatID, i18n.From(u.Ctx).T("help.text")))
 
 	// В будущем здесь будет:
 	// 1. FAQ
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/render"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	inlinePageSize  = 20  // Telegram принимает до 50 результатов за ответ
+	inlineCacheTime = 300 // секунд: кэш ответа на стороне Telegram (личный, см. IsPersonal)
+	inlineMaxQuery  = 64
+)
+
+// handleInlineQuery: «@bot Казань» в любом чате — поиск маршрутов и карточка для отправки.
+// Режим включается у @BotFather командой /setinline.
+func (h *Handler) handleInlineQuery(u *mux.UpdateCtx) error {
+	q := u.Update.InlineQuery
+	query := strings.TrimSpace(q.Query)
+	if r := []rune(query); len(r) > inlineMaxQuery {
+		query = string(r[:inlineMaxQuery])
+	}
+	offset, _ := strconv.Atoi(q.Offset)
+
+	routes, err := h.routes.Search(u.Ctx, models.RouteFilter{Query: query}, inlinePageSize, offset)
+	if err != nil {
+		return err
+	}
+
+	l := i18n.From(u.Ctx)
+	results := make([]interface{}, 0, len(routes))
+	for i := range routes {
+		results = append(results, h.inlineRouteResult(u.Ctx, l, &routes[i]))
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: q.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true, // результаты на языке пользователя — общий кэш отдал бы их другим
+	}
+	if len(routes) == inlinePageSize {
+		answer.NextOffset = strconv.Itoa(offset + inlinePageSize)
+	}
+	if offset == 0 && len(routes) == 0 {
+		answer.SwitchPMText = l.T("inline.nothing_found")
+		answer.SwitchPMParameter = "inline"
+	}
+	_, err = u.Sender.Request(answer)
+	return err
+}
+
+// inlineRouteResult — карточка маршрута с кнопкой «Открыть в боте»;
+// с обложкой — фото с подписью, без неё — текст
+func (h *Handler) inlineRouteResult(ctx context.Context, l i18n.Localizer, v *models.RouteVersion) interface{} {
+	id := strconv.Itoa(v.RouteID)
+	description := l.T("inline.description", v.City, v.LengthKm, v.DurationMinutes, v.Price)
+
+	openBtn := tgbotapi.NewInlineKeyboardButtonURL(l.T("inline.open"),
+		startLink(h.bot.Self().UserName, routeStartPayload(v.RouteID)))
+	markup := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(openBtn))
+
+	// Только file_id: presigned-ссылка S3 истечёт, пока Telegram кэширует ответ.
+	// Обложка попадает в кэш при первом показе карточки в боте.
+	if v.CoverMediaID != 0 {
+		if fid, ok := h.tgMedia.CachedPhotoID(ctx, v.CoverMediaID); ok {
+			photo := tgbotapi.NewInlineQueryResultCachedPhoto(id, fid)
+			photo.Title = v.Title
+			photo.Description = description
+			photo.Caption = render.RouteCard(l, v, render.CaptionLimit)
+			photo.ParseMode = render.ParseMode
+			photo.ReplyMarkup = &markup
+			return photo
+		}
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticleHTML(id, v.Title, render.RouteCard(l, v, render.TextLimit))
+	article.Description = description
+	article.ReplyMarkup = &markup
+	return article
+}
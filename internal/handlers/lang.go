@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"walki/internal/i18n"
+	"walki/internal/models"
+)
+
+/*
+Язык ответа берётся из контекста апдейта: middlewares.Language (клиент Telegram)
+и WithUser (язык из профиля). Экраны получают его через screenTarget.Ctx или ctx
+обработчика — i18n.From(ctx).
+*/
+
+// trUser — тексты для уведомления пользователю вне его апдейта: язык из профиля,
+// иначе язык по умолчанию
+func (h *Handler) trUser(u *models.User) i18n.Localizer {
+	return i18n.For(u.Language)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/handlers/mux/middlewares"
+	"walki/internal/i18n"
+	"walki/internal/service"
+)
+
+// Тяжёлые callback'и: каждый отправляет фото/аудио точки или карточку с обложкой
+var mediaCallbacks = []string{
+	CallbackRoute, CallbackStartRoute, CallbackNextRoute, CallbackPrevRoute,
+	CallbackContinueRoute, CallbackRestartRoute, CallbackUpgradeRoute, CallbackAuthorPreview,
+	CallbackGallery, CallbackSample,
+}
+
+// classifyUpdate — класс апдейта для лимитера (см. middlewares.RateLimit)
+func classifyUpdate(u *mux.UpdateCtx) string {
+	if cb := u.Update.CallbackQuery; cb != nil {
+		for _, p := range mediaCallbacks {
+			if strings.HasPrefix(cb.Data, p) {
+				return middlewares.ClassMedia
+			}
+		}
+	}
+	return "" // по типу апдейта
+}
+
+// handleBan: /ban <telegram_id> [срок: 30m, 12h, 7d] [причина]
+func (h *Handler) handleBan(u *mux.UpdateCtx) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	l := i18n.From(u.Ctx)
+	args := strings.Fields(u.Update.Message.CommandArguments())
+	if len(args) == 0 {
+		return mux.Invalid(l.T("mod.ban_usage"), nil)
+	}
+	tgID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return mux.Invalid(l.T("mod.bad_id"), err)
+	}
+	d := service.DefaultBanDuration
+	reason := ""
+	if len(args) > 1 {
+		if parsed, ok := parseBanDuration(args[1]); ok {
+			d = parsed
+			args = args[2:]
+		} else {
+			args = args[1:]
+		}
+		reason = strings.Join(args, " ")
+	}
+
+	ban, err := h.moderation.Ban(u.Ctx, usr, tgID, d, reason)
+	if errors.Is(err, service.ErrInvalidValue) {
+		return mux.Invalid(l.T("mod.bad_ban"), err)
+	}
+	if err != nil {
+		return fmt.Errorf("ban user %d: %w", tgID, err)
+	}
+	h.sendMessage(u.ChatID, l.T("mod.banned", tgID, ban.Until.Format("02.01.2006 15:04")))
+	return nil
+}
+
+// handleUnban: /unban <telegram_id>
+func (h *Handler) handleUnban(u *mux.UpdateCtx) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	l := i18n.From(u.Ctx)
+	tgID, err := strconv.ParseInt(strings.TrimSpace(u.Update.Message.CommandArguments()), 10, 64)
+	if err != nil {
+		return mux.Invalid(l.T("mod.unban_usage"), err)
+	}
+	ok, err := h.moderation.Unban(u.Ctx, usr, tgID)
+	if err != nil {
+		return fmt.Errorf("unban user %d: %w", tgID, err)
+	}
+	if !ok {
+		h.sendMessage(u.ChatID, l.T("mod.not_banned", tgID))
+		return nil
+	}
+	h.sendMessage(u.ChatID, l.T("mod.unbanned", tgID))
+	return nil
+}
+
+// parseBanDuration понимает time.ParseDuration и дни: "7d"
+func parseBanDuration(s string) (time.Duration, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// handleRoutesDebug: /debug_routes — зарегистрированные маршруты роутера
+func (h *Handler) handleRoutesDebug(u *mux.UpdateCtx) error {
+	var b strings.Builder
+	b.WriteString(i18n.From(u.Ctx).T("mod.routes"))
+	for _, ri := range h.router.Routes() {
+		b.WriteString("\n" + ri.String())
+	}
+	h.sendMessage(u.ChatID, b.String())
+	return nil
+}
@@ -0,0 +1,302 @@
+package mux
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+Типизированные callback_data.
+
+	data = prefix + payload + "!" + sig
+	payload = поля структуры по порядку через "|": числа в base36, строки как есть
+	          (экранируются только служебные символы), bool — 0/1
+	sig = HMAC-SHA256(prefix+payload), первые 6 байт в base64url
+
+Если результат длиннее лимита Telegram (64 байта), payload кладётся в PayloadStore,
+а в кнопку уходит короткий ключ: prefix + "~" + id + "!" + sig.
+*/
+
+// Лимит Telegram на callback_data, байт
+const MaxCallbackData = 64
+
+const (
+	fieldSep  = '|'
+	sigSep    = '!'
+	shortMark = '~'
+	sigBytes  = 6
+	shortLen  = 12 // символов base64url в ключе PayloadStore
+)
+
+var (
+	// ErrBadCallback — данные не разбираются или подпись не сходится (подделка, устаревшая кнопка)
+	ErrBadCallback = errors.New("bad callback data")
+	// ErrStaleCallback — длинный payload не найден в хранилище
+	ErrStaleCallback = errors.New("stale callback data")
+)
+
+// PayloadStore — хранилище длинных payload'ов (Postgres или память)
+type PayloadStore interface {
+	PutPayload(ctx context.Context, id, payload string) error
+	GetPayload(ctx context.Context, id string) (string, bool, error)
+}
+
+// Codec — общие настройки: секрет подписи и хранилище длинных payload'ов.
+// Пустой секрет — без подписи; nil store — длинные данные не поддерживаются.
+type Codec struct {
+	secret []byte
+	store  PayloadStore
+}
+
+func NewCodec(secret []byte, store PayloadStore) *Codec {
+	return &Codec{secret: secret, store: store}
+}
+
+// CallbackType — callback с данными T. T — структура с экспортируемыми полями
+// int*, uint*, string или bool; порядок полей — часть формата.
+type CallbackType[T any] struct {
+	Prefix string
+	codec  *Codec
+}
+
+func NewCallback[T any](c *Codec, prefix string) CallbackType[T] {
+	var zero T
+	if reflect.TypeOf(zero).Kind() != reflect.Struct {
+		panic("mux: callback payload must be a struct: " + prefix)
+	}
+	return CallbackType[T]{Prefix: prefix, codec: c}
+}
+
+// Data кодирует значение в callback_data; ctx — контекст апдейта, нужен только для
+// записи длинного payload. Ошибку хранилища логируем и отдаём кнопку без данных:
+// нажатие на неё вернёт ErrBadCallback, а не упадёт.
+func (t CallbackType[T]) Data(ctx context.Context, v T) string {
+	data, err := t.Encode(ctx, v)
+	if err != nil {
+		log.Printf("mux: encode callback %s: %v", t.Prefix, err)
+		return t.Prefix
+	}
+	return data
+}
+
+func (t CallbackType[T]) Encode(ctx context.Context, v T) (string, error) {
+	payload := encodePayload(reflect.ValueOf(v))
+	data := t.codec.sign(t.Prefix, payload)
+	if len(data) <= MaxCallbackData {
+		return data, nil
+	}
+	if t.codec.store == nil {
+		return "", fmt.Errorf("callback data too long (%d bytes)", len(data))
+	}
+	sum := sha256.Sum256([]byte(t.Prefix + payload))
+	id := base64.RawURLEncoding.EncodeToString(sum[:])[:shortLen]
+	if err := t.codec.store.PutPayload(ctx, id, payload); err != nil {
+		return "", err
+	}
+	return t.codec.sign(t.Prefix, string(shortMark)+id), nil
+}
+
+func (t CallbackType[T]) Decode(ctx context.Context, data string) (T, error) {
+	var v T
+	rest, ok := strings.CutPrefix(data, t.Prefix)
+	if !ok {
+		return v, ErrBadCallback
+	}
+	payload, ok := t.codec.verify(t.Prefix, rest)
+	if !ok {
+		return v, ErrBadCallback
+	}
+	if id, short := strings.CutPrefix(payload, string(shortMark)); short {
+		if t.codec.store == nil {
+			return v, ErrStaleCallback
+		}
+		stored, found, err := t.codec.store.GetPayload(ctx, id)
+		if err != nil {
+			return v, err
+		}
+		if !found {
+			return v, ErrStaleCallback
+		}
+		payload = stored
+	}
+	if err := decodePayload(payload, reflect.ValueOf(&v).Elem()); err != nil {
+		return v, fmt.Errorf("%w: %v", ErrBadCallback, err)
+	}
+	return v, nil
+}
+
+// Handle регистрирует обработчик на префикс типа.
+// Неразборчивые и поддельные данные превращаются в ошибку валидации («кнопка устарела»).
+func (t CallbackType[T]) Handle(r CallbackRegistrar, h func(u *UpdateCtx, v T) error) {
+	r.CallbackPrefix(t.Prefix, func(u *UpdateCtx, _ Values) error {
+		v, err := t.Decode(u.Ctx, u.Update.CallbackQuery.Data)
+		if err != nil {
+			return &Error{Kind: KindValidation, Key: "error.stale_button", Err: err}
+		}
+		return h(u, v)
+	})
+}
+
+func (c *Codec) sign(prefix, payload string) string {
+	if len(c.secret) == 0 {
+		return prefix + payload
+	}
+	return prefix + payload + string(sigSep) + c.mac(prefix+payload)
+}
+
+func (c *Codec) verify(prefix, rest string) (string, bool) {
+	if len(c.secret) == 0 {
+		return rest, true
+	}
+	i := strings.LastIndexByte(rest, sigSep)
+	if i < 0 {
+		return "", false
+	}
+	payload, sig := rest[:i], rest[i+1:]
+	return payload, hmac.Equal([]byte(sig), []byte(c.mac(prefix+payload)))
+}
+
+func (c *Codec) mac(s string) string {
+	m := hmac.New(sha256.New, c.secret)
+	m.Write([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(m.Sum(nil)[:sigBytes])
+}
+
+func encodePayload(v reflect.Value) string {
+	var b strings.Builder
+	n := 0
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if n > 0 {
+			b.WriteByte(fieldSep)
+		}
+		n++
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			b.WriteString(strconv.FormatInt(f.Int(), 36))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			b.WriteString(strconv.FormatUint(f.Uint(), 36))
+		case reflect.Bool:
+			if f.Bool() {
+				b.WriteByte('1')
+			} else {
+				b.WriteByte('0')
+			}
+		case reflect.String:
+			b.WriteString(escaper.Replace(f.String()))
+		default:
+			panic("mux: unsupported callback field kind " + f.Kind().String())
+		}
+	}
+	return b.String()
+}
+
+func decodePayload(payload string, v reflect.Value) error {
+	// у структуры без полей payload пустой; иначе Split("") даёт одно пустое поле
+	var parts []string
+	if payload != "" || exportedFields(v.Type()) > 0 {
+		parts = strings.Split(payload, string(fieldSep))
+	}
+	n := 0
+	for i := 0; i < v.NumField(); i++ {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if n >= len(parts) {
+			return errors.New("not enough fields")
+		}
+		s := parts[n]
+		n++
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			x, err := strconv.ParseInt(s, 36, f.Type().Bits())
+			if err != nil {
+				return err
+			}
+			f.SetInt(x)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			x, err := strconv.ParseUint(s, 36, f.Type().Bits())
+			if err != nil {
+				return err
+			}
+			f.SetUint(x)
+		case reflect.Bool:
+			f.SetBool(s == "1")
+		case reflect.String:
+			f.SetString(unescaper.Replace(s))
+		}
+	}
+	if n != len(parts) {
+		return errors.New("too many fields")
+	}
+	return nil
+}
+
+func exportedFields(t reflect.Type) int {
+	n := 0
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			n++
+		}
+	}
+	return n
+}
+
+// Экранируем только служебные символы: кириллица остаётся как есть (2 байта на букву)
+var (
+	escaper   = strings.NewReplacer("%", "%25", "|", "%7C", "!", "%21", "~", "%7E")
+	unescaper = strings.NewReplacer("%25", "%", "%7C", "|", "%21", "!", "%7E", "~")
+)
+
+// MemoryPayloadStore — хранилище длинных payload'ов в памяти процесса (для разработки)
+type MemoryPayloadStore struct {
+	mu    sync.Mutex
+	items map[string]memoryPayload
+	ttl   time.Duration
+}
+
+type memoryPayload struct {
+	payload string
+	stored  time.Time
+}
+
+func NewMemoryPayloadStore(ttl time.Duration) *MemoryPayloadStore {
+	return &MemoryPayloadStore{items: map[string]memoryPayload{}, ttl: ttl}
+}
+
+func (m *MemoryPayloadStore) PutPayload(_ context.Context, id, payload string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	for k, it := range m.items {
+		if now.Sub(it.stored) > m.ttl {
+			delete(m.items, k)
+		}
+	}
+	m.items[id] = memoryPayload{payload: payload, stored: now}
+	return nil
+}
+
+func (m *MemoryPayloadStore) GetPayload(_ context.Context, id string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	it, ok := m.items[id]
+	if !ok || time.Since(it.stored) > m.ttl {
+		return "", false, nil
+	}
+	return it.payload, true, nil
+}
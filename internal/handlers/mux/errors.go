@@ -0,0 +1,84 @@
+package mux
+
+import (
+	"fmt"
+
+	"walki/internal/i18n"
+)
+
+// ErrorKind — категория ошибки обработчика; от неё зависит ответ пользователю
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindNotFound
+	KindForbidden
+	KindValidation
+)
+
+// Ответы по умолчанию (ключи i18n), если обработчик не задал свой текст
+var defaultMessages = map[ErrorKind]string{
+	KindInternal:   "error.internal",
+	KindNotFound:   "error.not_found",
+	KindForbidden:  "error.forbidden",
+	KindValidation: "error.invalid",
+}
+
+// Error — типизированная ошибка обработчика. Message показывается пользователю,
+// Err (причина) — только в логах.
+type Error struct {
+	Kind    ErrorKind
+	Message string // готовый текст на языке пользователя
+	Key     string // ключ i18n, если текста нет
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.kindName(), e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.kindName(), e.Message)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// UserMessage — текст для пользователя на языке l
+func (e *Error) UserMessage(l i18n.Localizer) string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Key != "":
+		return l.T(e.Key)
+	}
+	return l.T(defaultMessages[e.Kind])
+}
+
+func (e *Error) kindName() string {
+	switch e.Kind {
+	case KindNotFound:
+		return "not found"
+	case KindForbidden:
+		return "forbidden"
+	case KindValidation:
+		return "validation"
+	}
+	return "internal"
+}
+
+// Конструкторы: msg == "" — стандартный текст, err может быть nil
+
+func NotFound(msg string, err error) *Error {
+	return &Error{Kind: KindNotFound, Message: msg, Err: err}
+}
+
+func Forbidden(msg string, err error) *Error {
+	return &Error{Kind: KindForbidden, Message: msg, Err: err}
+}
+
+func Invalid(msg string, err error) *Error {
+	return &Error{Kind: KindValidation, Message: msg, Err: err}
+}
+
+func Internal(msg string, err error) *Error {
+	return &Error{Kind: KindInternal, Message: msg, Err: err}
+}
@@ -0,0 +1,145 @@
+package mux
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"walki/internal/models"
+)
+
+/*
+FSM поверх роутера: у чата может быть текущее состояние («ждём название маршрута»),
+и следующее сообщение уходит обработчику этого состояния, а не в Default.
+Команды и кнопки главного меню продолжают работать; /cancel сбрасывает состояние.
+*/
+
+// StateStore — хранилище состояний; постгресовая реализация в repository/postgres
+type StateStore interface {
+	Get(ctx context.Context, chatID int64) (*models.ChatState, error)
+	Set(ctx context.Context, s *models.ChatState) error
+	Delete(ctx context.Context, chatID int64) error
+}
+
+// CancelCommand — универсальная отмена диалога
+const CancelCommand = "cancel"
+
+// States подключает хранилище состояний
+func (r *Router) States(store StateStore) { r.states = store }
+
+// State регистрирует обработчик сообщений для состояния
+func (r *Router) State(name string, h HandlerFunc) { r.stateHandlers[name] = h }
+
+// OnCancel — ответ на /cancel (состояние к этому моменту уже сброшено)
+func (r *Router) OnCancel(h HandlerFunc) { r.onCancel = h }
+
+// OnStateTimeout — уведомление о протухшем состоянии; после него апдейт маршрутизируется как обычно.
+// Выполняется внутри цепочки middleware (язык, пользователь уже в контексте).
+func (r *Router) OnStateTimeout(h HandlerFunc) { r.onTimeout = h }
+
+// loadState подтягивает состояние чата в UpdateCtx; протухшее — удаляет и возвращает
+func (r *Router) loadState(u *UpdateCtx) (expired *models.ChatState) {
+	u.states = r.states
+	if r.states == nil || u.ChatID == 0 {
+		return nil
+	}
+	st, err := r.states.Get(u.Ctx, u.ChatID)
+	if err != nil {
+		log.Printf("fsm: load state chat=%d: %v", u.ChatID, err)
+		return nil
+	}
+	if st.Expired(time.Now()) {
+		if err := r.states.Delete(u.Ctx, u.ChatID); err != nil {
+			log.Printf("fsm: delete expired state chat=%d: %v", u.ChatID, err)
+		}
+		return st
+	}
+	u.State = st
+	return nil
+}
+
+// withTimeout — сначала уведомление о протухшем состоянии, затем обработчик апдейта (если есть)
+func (r *Router) withTimeout(st *models.ChatState, next HandlerFunc) HandlerFunc {
+	return func(u *UpdateCtx) error {
+		expired := *u
+		expired.State = st
+		if err := r.onTimeout(&expired); err != nil {
+			log.Printf("fsm: timeout handler: %v", err)
+		}
+		if next == nil {
+			return nil
+		}
+		return next(u)
+	}
+}
+
+// SetState переводит чат в состояние name; ttl = 0 — без таймаута
+func (u *UpdateCtx) SetState(name string, data map[string]string, ttl time.Duration) error {
+	if u.states == nil {
+		return nil
+	}
+	st := &models.ChatState{ChatID: u.ChatID, Name: name, Data: data}
+	if ttl > 0 {
+		exp := time.Now().Add(ttl)
+		st.ExpiresAt = &exp
+	}
+	if err := u.states.Set(u.Ctx, st); err != nil {
+		return err
+	}
+	u.State = st
+	return nil
+}
+
+// ClearState завершает диалог
+func (u *UpdateCtx) ClearState() error {
+	if u.states == nil || u.State == nil {
+		return nil
+	}
+	u.State = nil
+	return u.states.Delete(u.Ctx, u.ChatID)
+}
+
+// StateData — значение из данных текущего состояния
+func (u *UpdateCtx) StateData(key string) string {
+	if u.State == nil {
+		return ""
+	}
+	return u.State.Data[key]
+}
+
+// MemoryStateStore — хранилище в памяти процесса (для разработки и одного инстанса)
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	states map[int64]models.ChatState
+}
+
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{states: map[int64]models.ChatState{}}
+}
+
+func (m *MemoryStateStore) Get(_ context.Context, chatID int64) (*models.ChatState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.states[chatID]
+	if !ok {
+		return nil, nil
+	}
+	return &st, nil
+}
+
+func (m *MemoryStateStore) Set(_ context.Context, s *models.ChatState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st := *s
+	st.UpdatedAt = time.Now()
+	m.states[s.ChatID] = st
+	return nil
+}
+
+func (m *MemoryStateStore) Delete(_ context.Context, chatID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, chatID)
+	return nil
+}
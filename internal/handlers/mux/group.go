@@ -0,0 +1,38 @@
+package mux
+
+// Group — набор маршрутов со своими middleware (например, только для админов).
+// Middleware группы выполняются внутри общих middleware роутера.
+type Group struct {
+	r    *Router
+	name string
+	mw   []Middleware
+}
+
+// Group создаёт группу; name виден в Routes()
+func (r *Router) Group(name string, mw ...Middleware) *Group {
+	return &Group{r: r, name: name, mw: mw}
+}
+
+func (g *Group) Use(m Middleware) { g.mw = append(g.mw, m) }
+
+func (g *Group) Command(cmd string, h HandlerFunc)        { g.r.command(g.name, cmd, g.wrap(h)) }
+func (g *Group) Message(text string, h HandlerFunc)       { g.r.message(g.name, text, g.wrap(h)) }
+func (g *Group) CallbackExact(data string, h HandlerFunc) { g.r.callbackExact(g.name, data, g.wrap(h)) }
+
+func (g *Group) CallbackPrefix(prefix string, h CallbackFunc) {
+	g.r.callbackPrefix(g.name, prefix, func(u *UpdateCtx, v Values) error {
+		return g.wrap(func(u *UpdateCtx) error { return h(u, v) })(u)
+	})
+}
+
+func (g *Group) wrap(h HandlerFunc) HandlerFunc {
+	for i := len(g.mw) - 1; i >= 0; i-- {
+		h = g.mw[i](h)
+	}
+	return h
+}
+
+// CallbackRegistrar — куда можно повесить обработчик префикса: Router или Group
+type CallbackRegistrar interface {
+	CallbackPrefix(prefix string, h CallbackFunc)
+}
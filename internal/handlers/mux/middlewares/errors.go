@@ -0,0 +1,46 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+)
+
+// Errors — централизованная обработка ошибок обработчиков.
+// translate переводит доменные ошибки (сервисов) в *mux.Error; всё нераспознанное — внутренняя ошибка.
+// Пользователь получает UserMessage, подробности (причина) — только в лог.
+func Errors(translate func(error) *mux.Error) mux.Middleware {
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) error {
+			err := next(u)
+			if err == nil {
+				return nil
+			}
+
+			var e *mux.Error
+			if !errors.As(err, &e) {
+				if translate != nil {
+					e = translate(err)
+				}
+				if e == nil {
+					e = mux.Internal("", err)
+				}
+			}
+
+			if e.Kind == mux.KindInternal {
+				log.Printf("[error] chat=%d: %v", u.ChatID, err)
+			} else {
+				log.Printf("[warn] chat=%d: %v", u.ChatID, err)
+			}
+			if u.ChatID != 0 {
+				if _, sendErr := u.Sender.Send(tgbotapi.NewMessage(u.ChatID, e.UserMessage(i18n.From(u.Ctx)))); sendErr != nil {
+					log.Printf("error reply: %v", sendErr)
+				}
+			}
+			return nil
+		}
+	}
+}
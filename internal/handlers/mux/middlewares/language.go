@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+)
+
+// Language — язык апдейта по language_code клиента Telegram.
+// Ставится первым, чтобы ответы остальных middleware (ошибки, лимиты, баны)
+// уже были локализованы; выбор из профиля применяет WithUser.
+func Language() mux.Middleware {
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) error {
+			code := ""
+			if from := u.Update.SentFrom(); from != nil {
+				code = from.LanguageCode
+			}
+			u.Ctx = i18n.WithLang(u.Ctx, i18n.Detect(code))
+			return next(u)
+		}
+	}
+}
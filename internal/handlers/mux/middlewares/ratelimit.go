@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/ratelimit"
+	"walki/internal/service"
+)
+
+// Классы действий с отдельными бюджетами
+const (
+	ClassMessage  = "message"
+	ClassCommand  = "command"
+	ClassCallback = "callback"
+	ClassMedia    = "media"  // тяжёлые действия: отправка фото/аудио, загрузка файлов
+	ClassInline   = "inline" // inline-запросы приходят на каждый набранный символ
+)
+
+// Budget — скорость (действий в секунду) и допустимый всплеск
+type Budget struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimits — бюджеты по классам; Classify относит апдейт к классу (nil — по типу апдейта)
+type RateLimits struct {
+	Budgets  map[string]Budget
+	Classify func(u *mux.UpdateCtx) string
+}
+
+// DefaultRateLimits — с запасом для живого человека, но не для скрипта
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Budgets: map[string]Budget{
+			ClassMessage:  {Rate: 1, Burst: 5},
+			ClassCommand:  {Rate: 0.5, Burst: 5},
+			ClassCallback: {Rate: 2, Burst: 10},
+			ClassMedia:    {Rate: ratelimit.Every(3 * time.Second), Burst: 5},
+			ClassInline:   {Rate: 3, Burst: 15},
+		},
+	}
+}
+
+// Об ограничении напоминаем не чаще раза в 10 секунд, чтобы не спамить в ответ на спам
+var warnEvery = 10 * time.Second
+
+// RateLimit — token bucket на пользователя Telegram для каждого класса действий.
+// Ставится до AnswerCallback: отклонённый callback отвечаем сами, всплывающим текстом.
+func RateLimit(limits RateLimits) mux.Middleware {
+	type key struct {
+		userID int64
+		class  string
+	}
+	buckets := ratelimit.NewKeyed(func(k key) *ratelimit.Bucket {
+		b, ok := limits.Budgets[k.class]
+		if !ok {
+			b = limits.Budgets[ClassMessage]
+		}
+		return ratelimit.NewBucket(b.Rate, b.Burst)
+	})
+	warned := ratelimit.NewKeyed(func(int64) *ratelimit.Bucket {
+		return ratelimit.NewBucket(ratelimit.Every(warnEvery), 1)
+	})
+
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) error {
+			from := u.Update.SentFrom()
+			if from == nil {
+				return next(u)
+			}
+			class := ""
+			if limits.Classify != nil {
+				class = limits.Classify(u)
+			}
+			if class == "" {
+				class = defaultClass(u)
+			}
+			if buckets.Allow(key{from.ID, class}) {
+				return next(u)
+			}
+
+			log.Printf("[ratelimit] user=%d class=%s throttled", from.ID, class)
+			if cb := u.Update.CallbackQuery; cb != nil {
+				// «часики» у кнопки убираем в любом случае
+				if _, err := u.Sender.Request(tgbotapi.NewCallback(cb.ID, i18n.From(u.Ctx).T("error.too_often"))); err != nil {
+					log.Printf("answerCallback error: %v", err)
+				}
+				return nil
+			}
+			if u.ChatID != 0 && warned.Allow(from.ID) {
+				_, _ = u.Sender.Send(tgbotapi.NewMessage(u.ChatID, i18n.From(u.Ctx).T("error.too_often")))
+			}
+			return nil
+		}
+	}
+}
+
+func defaultClass(u *mux.UpdateCtx) string {
+	switch {
+	case u.Update.CallbackQuery != nil:
+		return ClassCallback
+	case u.Update.InlineQuery != nil:
+		return ClassInline
+	case u.Update.Message != nil && u.Update.Message.IsCommand():
+		return ClassCommand
+	case u.Update.Message != nil && (u.Update.Message.Photo != nil || u.Update.Message.Voice != nil ||
+		u.Update.Message.Audio != nil || u.Update.Message.Document != nil):
+		return ClassMedia
+	}
+	return ClassMessage
+}
+
+// Banned отбрасывает апдейты заблокированных пользователей.
+// Об ограничении сообщаем не чаще warnEvery; callback всегда отвечаем, чтобы кнопка не «висела».
+func Banned(moderation *service.ModerationService) mux.Middleware {
+	warned := ratelimit.NewKeyed(func(int64) *ratelimit.Bucket {
+		return ratelimit.NewBucket(ratelimit.Every(warnEvery), 1)
+	})
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) error {
+			from := u.Update.SentFrom()
+			if from == nil {
+				return next(u)
+			}
+			ban, err := moderation.ActiveBan(u.Ctx, from.ID)
+			if err != nil {
+				// БД недоступна — не блокируем всех подряд
+				log.Printf("ban check user=%d: %v", from.ID, err)
+				return next(u)
+			}
+			if ban == nil {
+				return next(u)
+			}
+
+			text := i18n.From(u.Ctx).T("error.banned", ban.Until.Format("02.01.2006 15:04"))
+			if cb := u.Update.CallbackQuery; cb != nil {
+				if _, err := u.Sender.Request(tgbotapi.NewCallback(cb.ID, text)); err != nil {
+					log.Printf("answerCallback error: %v", err)
+				}
+				return nil
+			}
+			if u.ChatID != 0 && warned.Allow(from.ID) {
+				_, _ = u.Sender.Send(tgbotapi.NewMessage(u.ChatID, text))
+			}
+			return nil
+		}
+	}
+}
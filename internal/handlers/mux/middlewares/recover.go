@@ -0,0 +1,25 @@
+package middlewares
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"walki/internal/handlers/mux"
+)
+
+// Recover превращает панику обработчика во внутреннюю ошибку, чтобы бот не падал целиком.
+// Ставится внутрь Errors, тогда пользователь получит обычное сообщение об ошибке.
+func Recover() mux.Middleware {
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("[panic] chat=%d: %v\n%s", u.ChatID, r, debug.Stack())
+					err = mux.Internal("", fmt.Errorf("panic: %v", r))
+				}
+			}()
+			return next(u)
+		}
+	}
+}
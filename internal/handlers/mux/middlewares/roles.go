@@ -0,0 +1,15 @@
+package middlewares
+
+import "walki/internal/handlers/mux"
+
+// AdminOnly — для групп маршрутов администратора; требует WithUser выше по цепочке
+func AdminOnly() mux.Middleware {
+	return func(next mux.HandlerFunc) mux.HandlerFunc {
+		return func(u *mux.UpdateCtx) error {
+			if !UserFrom(u.Ctx).IsAdmin() {
+				return mux.Forbidden("", nil)
+			}
+			return next(u)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package middlewares
 import (
 	"context"
 	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
 	"walki/internal/models"
 	"walki/internal/service"
 )
@@ -21,6 +22,10 @@ func WithUser(users *service.UserService) mux.Middleware {
 			usr, err := users.GetByTelegramID(u.Ctx, from.ID)
 			if err == nil && usr != nil {
 				u.Ctx = context.WithValue(u.Ctx, userKey, usr)
+				// язык, выбранный в профиле, важнее языка клиента Telegram
+				if usr.Language != "" {
+					u.Ctx = i18n.WithLang(u.Ctx, usr.Language)
+				}
 			}
 			return next(u)
 		}
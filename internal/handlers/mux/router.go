@@ -2,9 +2,13 @@ package mux
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"sort"
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/models"
 )
 
 type Sender interface {
@@ -15,85 +19,265 @@ type Sender interface {
 type UpdateCtx struct {
 	Ctx    context.Context
 	Update tgbotapi.Update
-	ChatID int64
+	ChatID int64 // 0 — апдейт без чата (inline-запрос)
 	Sender Sender
+	State  *models.ChatState // текущее состояние диалога (nil — нет)
+
+	states StateStore
 }
 
 type HandlerFunc func(*UpdateCtx) error
 type CallbackFunc func(*UpdateCtx, Values) error
 type Middleware func(HandlerFunc) HandlerFunc
 
+// События, не привязанные к тексту сообщения (см. On*)
+const (
+	EventLocation      = "location"
+	EventContact       = "contact"
+	EventPhoto         = "photo"
+	EventVoice         = "voice"
+	EventEditedMessage = "edited_message"
+	EventInlineQuery   = "inline_query"
+	EventPreCheckout   = "pre_checkout_query"
+)
+
+/*
+Порядок сопоставления:
+
+	callback:  точное совпадение → самый длинный префикс → Default
+	сообщение: /cancel → команда → точный текст → обработчик состояния FSM →
+	           тип содержимого (геопозиция, контакт, фото, голос) → Default
+	остальное: edited_message, inline_query, pre_checkout_query — по событию
+*/
 type Router struct {
 	mw       []Middleware
 	commands map[string]HandlerFunc
 	messages map[string]HandlerFunc
 	cbExact  map[string]HandlerFunc
-	cbPrefix map[string]CallbackFunc
+	cbPrefix []prefixRoute // отсортированы по убыванию длины
+	events   map[string]HandlerFunc
 	def      HandlerFunc
+
+	routes []RouteInfo
+
+	// FSM (см. fsm.go)
+	states        StateStore
+	stateHandlers map[string]HandlerFunc
+	onCancel      HandlerFunc
+	onTimeout     HandlerFunc
+}
+
+type prefixRoute struct {
+	prefix string
+	h      CallbackFunc
+}
+
+// RouteInfo — описание зарегистрированного маршрута (для отладки)
+type RouteInfo struct {
+	Kind    string // command, message, callback, callback_prefix, state, event, default
+	Pattern string
+	Group   string
+}
+
+func (ri RouteInfo) String() string {
+	s := fmt.Sprintf("%-16s %s", ri.Kind, ri.Pattern)
+	if ri.Group != "" {
+		s += " [" + ri.Group + "]"
+	}
+	return s
 }
 
 func New() *Router {
 	return &Router{
-		commands: map[string]HandlerFunc{},
-		messages: map[string]HandlerFunc{},
-		cbExact:  map[string]HandlerFunc{},
-		cbPrefix: map[string]CallbackFunc{},
+		commands:      map[string]HandlerFunc{},
+		messages:      map[string]HandlerFunc{},
+		cbExact:       map[string]HandlerFunc{},
+		events:        map[string]HandlerFunc{},
+		stateHandlers: map[string]HandlerFunc{},
 	}
 }
 
 func (r *Router) Use(m Middleware) { r.mw = append(r.mw, m) }
 
-func (r *Router) Command(cmd string, h HandlerFunc)            { r.commands[cmd] = h }
-func (r *Router) Message(text string, h HandlerFunc)           { r.messages[text] = h }
-func (r *Router) CallbackExact(data string, h HandlerFunc)     { r.cbExact[data] = h }
-func (r *Router) CallbackPrefix(prefix string, h CallbackFunc) { r.cbPrefix[prefix] = h }
-func (r *Router) Default(h HandlerFunc)                        { r.def = h }
+func (r *Router) Command(cmd string, h HandlerFunc)            { r.command("", cmd, h) }
+func (r *Router) Message(text string, h HandlerFunc)           { r.message("", text, h) }
+func (r *Router) CallbackExact(data string, h HandlerFunc)     { r.callbackExact("", data, h) }
+func (r *Router) CallbackPrefix(prefix string, h CallbackFunc) { r.callbackPrefix("", prefix, h) }
+func (r *Router) Default(h HandlerFunc)                        { r.def = h; r.track("default", "*", "") }
+
+func (r *Router) OnLocation(h HandlerFunc)      { r.event("", EventLocation, h) }
+func (r *Router) OnContact(h HandlerFunc)       { r.event("", EventContact, h) }
+func (r *Router) OnPhoto(h HandlerFunc)         { r.event("", EventPhoto, h) }
+func (r *Router) OnVoice(h HandlerFunc)         { r.event("", EventVoice, h) }
+func (r *Router) OnEditedMessage(h HandlerFunc) { r.event("", EventEditedMessage, h) }
+func (r *Router) OnInlineQuery(h HandlerFunc)   { r.event("", EventInlineQuery, h) }
+func (r *Router) OnPreCheckout(h HandlerFunc)   { r.event("", EventPreCheckout, h) }
+
+// Routes — все зарегистрированные маршруты в порядке регистрации
+func (r *Router) Routes() []RouteInfo {
+	out := make([]RouteInfo, len(r.routes))
+	copy(out, r.routes)
+	return out
+}
+
+func (r *Router) command(group, cmd string, h HandlerFunc) {
+	mustBeNew(r.commands, cmd, "command /"+cmd)
+	r.commands[cmd] = h
+	r.track("command", "/"+cmd, group)
+}
+
+func (r *Router) message(group, text string, h HandlerFunc) {
+	mustBeNew(r.messages, text, "message "+text)
+	r.messages[text] = h
+	r.track("message", text, group)
+}
+
+func (r *Router) callbackExact(group, data string, h HandlerFunc) {
+	mustBeNew(r.cbExact, data, "callback "+data)
+	r.cbExact[data] = h
+	r.track("callback", data, group)
+}
+
+func (r *Router) callbackPrefix(group, prefix string, h CallbackFunc) {
+	for i := range r.cbPrefix {
+		if r.cbPrefix[i].prefix == prefix {
+			panic("mux: duplicate route: callback prefix " + prefix)
+		}
+	}
+	r.cbPrefix = append(r.cbPrefix, prefixRoute{prefix: prefix, h: h})
+	// длинный префикс важнее короткого: "route_next:" раньше "route_"
+	sort.SliceStable(r.cbPrefix, func(i, j int) bool {
+		if len(r.cbPrefix[i].prefix) != len(r.cbPrefix[j].prefix) {
+			return len(r.cbPrefix[i].prefix) > len(r.cbPrefix[j].prefix)
+		}
+		return r.cbPrefix[i].prefix < r.cbPrefix[j].prefix
+	})
+	r.track("callback_prefix", prefix+"*", group)
+}
+
+// mustBeNew: повторная регистрация ключа — ошибка при запуске, а не молча
+// заменённый обработчик (например, админская команда поверх публичной)
+func mustBeNew(m map[string]HandlerFunc, key, what string) {
+	if _, ok := m[key]; ok {
+		panic("mux: duplicate route: " + what)
+	}
+}
+
+func (r *Router) event(group, name string, h HandlerFunc) {
+	r.events[name] = h
+	r.track("event", name, group)
+}
+
+func (r *Router) track(kind, pattern, group string) {
+	r.routes = append(r.routes, RouteInfo{Kind: kind, Pattern: pattern, Group: group})
+}
 
 func (r *Router) Dispatch(u *UpdateCtx) bool {
+	expired := r.loadState(u)
 	h, ok := r.pick(u)
+	if expired != nil && r.onTimeout != nil {
+		if !ok {
+			h = nil
+		}
+		h, ok = r.withTimeout(expired, h), true
+	}
 	if !ok {
 		return false
 	}
 	for i := len(r.mw) - 1; i >= 0; i-- {
 		h = r.mw[i](h)
 	}
-	_ = h(u) // ошибки можно централизовать в middleware
+	// обычно ошибки перехватывает middlewares.Errors; сюда доходят только без него
+	if err := h(u); err != nil {
+		log.Printf("mux: unhandled error chat=%d: %v", u.ChatID, err)
+	}
 	return true
 }
 
 func (r *Router) pick(u *UpdateCtx) (HandlerFunc, bool) {
-	if cb := u.Update.CallbackQuery; cb != nil {
-		data := cb.Data
-		if h, ok := r.cbExact[data]; ok {
-			return h, true
-		}
-		for p, f := range r.cbPrefix {
-			if strings.HasPrefix(data, p) {
-				vals := Parse(data)
-				return func(uc *UpdateCtx) error { return f(uc, vals) }, true
-			}
+	switch {
+	case u.Update.CallbackQuery != nil:
+		return r.pickCallback(u.Update.CallbackQuery.Data)
+	case u.Update.Message != nil:
+		return r.pickMessage(u, u.Update.Message)
+	case u.Update.EditedMessage != nil:
+		return r.eventHandler(EventEditedMessage)
+	case u.Update.InlineQuery != nil:
+		return r.eventHandler(EventInlineQuery)
+	case u.Update.PreCheckoutQuery != nil:
+		return r.eventHandler(EventPreCheckout)
+	}
+	return nil, false
+}
+
+func (r *Router) pickCallback(data string) (HandlerFunc, bool) {
+	if h, ok := r.cbExact[data]; ok {
+		return h, true
+	}
+	for _, p := range r.cbPrefix {
+		if strings.HasPrefix(data, p.prefix) {
+			f, vals := p.h, Parse(data)
+			return func(uc *UpdateCtx) error { return f(uc, vals) }, true
 		}
-		if r.def != nil {
-			return r.def, true
+	}
+	return r.def, r.def != nil
+}
+
+func (r *Router) pickMessage(u *UpdateCtx, msg *tgbotapi.Message) (HandlerFunc, bool) {
+	if msg.IsCommand() {
+		if msg.Command() == CancelCommand && r.states != nil {
+			return r.cancel, true
 		}
-		return nil, false
-	}
-	if msg := u.Update.Message; msg != nil {
-		if msg.IsCommand() {
-			if h, ok := r.commands[msg.Command()]; ok {
-				return h, true
-			}
-			if r.def != nil {
-				return r.def, true
-			}
-			return nil, false
+		if h, ok := r.commands[msg.Command()]; ok {
+			return h, true
 		}
+		return r.def, r.def != nil
+	}
+	if msg.Text != "" {
 		if h, ok := r.messages[msg.Text]; ok {
 			return h, true
 		}
-		if r.def != nil {
-			return r.def, true
+	}
+	if u.State != nil {
+		if h, ok := r.stateHandlers[u.State.Name]; ok {
+			return h, true
 		}
 	}
-	return nil, false
+	if ev := messageEvent(msg); ev != "" {
+		if h, ok := r.events[ev]; ok {
+			return h, true
+		}
+	}
+	return r.def, r.def != nil
+}
+
+func (r *Router) eventHandler(name string) (HandlerFunc, bool) {
+	h, ok := r.events[name]
+	return h, ok
+}
+
+// messageEvent — тип содержимого сообщения без текста
+func messageEvent(msg *tgbotapi.Message) string {
+	switch {
+	case msg.Location != nil:
+		return EventLocation
+	case msg.Contact != nil:
+		return EventContact
+	case len(msg.Photo) > 0:
+		return EventPhoto
+	case msg.Voice != nil:
+		return EventVoice
+	}
+	return ""
+}
+
+// Встроенный /cancel: сбрасывает состояние и отдаёт управление OnCancel
+func (r *Router) cancel(u *UpdateCtx) error {
+	if err := u.ClearState(); err != nil {
+		return err
+	}
+	if r.onCancel != nil {
+		return r.onCancel(u)
+	}
+	return nil
 }
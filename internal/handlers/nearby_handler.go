@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"math"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/keyboards"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Радиусы поиска «рядом со мной»: начинаем с ближнего, дальше — по кнопке
+var nearbyRadiiKm = []int{10, 50, 200}
+
+const nearbyLimit = 10
+
+// askNearbyLocation — кнопка «Рядом со мной»: геопозицию можно отправить только reply-кнопкой
+func (h *Handler) askNearbyLocation(u *mux.UpdateCtx) error {
+	l := i18n.From(u.Ctx)
+	msg := tgbotapi.NewMessage(u.ChatID, l.T("nearby.ask"))
+	msg.ReplyMarkup = keyboards.LocationRequest(l)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// handleLocation — геопозиция вне диалогов: маршруты поблизости
+func (h *Handler) handleLocation(u *mux.UpdateCtx) error {
+	loc := u.Update.Message.Location
+	l := i18n.From(u.Ctx)
+	// возвращаем главное меню вместо клавиатуры с запросом геопозиции
+	ack := tgbotapi.NewMessage(u.ChatID, l.T("nearby.searching"))
+	ack.ReplyMarkup = keyboards.MainMenu(l)
+	if _, err := h.bot.Send(ack); err != nil {
+		return err
+	}
+	return h.showNearby(u, nearbyRef{Lat: toMicro(loc.Latitude), Lon: toMicro(loc.Longitude), Radius: nearbyRadiiKm[0]})
+}
+
+func (h *Handler) showNearby(u *mux.UpdateCtx, v nearbyRef) error {
+	routes, err := h.routes.Nearby(u.Ctx, fromMicro(v.Lat), fromMicro(v.Lon), float64(v.Radius), nearbyLimit)
+	if err != nil {
+		return err
+	}
+
+	l := i18n.From(u.Ctx)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, n := range routes {
+		btnText := l.T("nearby.item", n.Route.Title, formatDistance(l, n.DistanceKm))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(btnText, h.cb.Route.Data(u.Ctx, idRef{n.Route.RouteID}))))
+	}
+	if wider, ok := widerRadius(v.Radius); ok {
+		ref := v
+		ref.Radius = wider
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			l.T("nearby.wider", wider), h.cb.Nearby.Data(u.Ctx, ref))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("nearby.choose_city"), CallbackSelectCity)))
+
+	text := l.T("nearby.title", v.Radius)
+	if len(routes) == 0 {
+		text = l.T("nearby.empty", v.Radius)
+	}
+	h.showScreen(screenOf(u), screen{Text: text, Markup: tgbotapi.NewInlineKeyboardMarkup(rows...)})
+	return nil
+}
+
+func widerRadius(current int) (int, bool) {
+	for _, r := range nearbyRadiiKm {
+		if r > current {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// formatDistance: «350 м», «4.2 км»
+func formatDistance(l i18n.Localizer, km float64) string {
+	if km < 1 {
+		return l.T("nearby.meters", int(math.Round(km*1000/10))*10)
+	}
+	return l.T("nearby.km", km)
+}
+
+// Координаты в callback_data — целые микроградусы (точность ~10 см)
+func toMicro(deg float64) int { return int(math.Round(deg * 1e6)) }
+func fromMicro(v int) float64 { return float64(v) / 1e6 }
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/domain"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/service"
+)
+
+// notificationKinds — виды уведомлений в порядке кнопок настроек
+var notificationKinds = []string{models.JobAccessExpiry, models.JobProgressNudge, models.JobReviewRequest}
+
+// Notify отправляет запланированное уведомление (см. scheduler) на языке получателя.
+// Отправка синхронная: ошибка вернётся планировщику, и задание уйдёт на повтор.
+func (h *Handler) Notify(ctx context.Context, n *service.Notification) error {
+	t := &n.Target
+	l := h.trUser(&t.User)
+	title := h.notificationRouteTitle(i18n.WithLang(ctx, l.Lang()), t)
+
+	var text string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	switch n.Job.Kind {
+	case models.JobAccessExpiry:
+		text = l.T("notify.expiry", title, t.AccessExpiry.Format("02.01.2006"))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("profile.start_walk"), h.cb.StartRoute.Data(ctx, idRef{t.RouteID}))))
+	case models.JobProgressNudge:
+		text = l.T("notify.nudge", title)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("run.continue"), h.cb.ContinueRoute.Data(ctx, idRef{t.RouteID}))))
+	case models.JobReviewRequest:
+		text = l.T("notify.review", title)
+		var stars []tgbotapi.InlineKeyboardButton
+		for rating := 1; rating <= 5; rating++ {
+			stars = append(stars, tgbotapi.NewInlineKeyboardButtonData(
+				l.T("notify.star", rating), h.cb.Review.Data(ctx, reviewRef{t.RouteID, rating})))
+		}
+		rows = append(rows, stars)
+	default:
+		return fmt.Errorf("unknown job kind %q", n.Job.Kind)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("notify.settings_btn"), CallbackNotifications)))
+
+	msg := tgbotapi.NewMessage(t.User.TelegramID, text)
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// notificationRouteTitle — название маршрута на языке из ctx; не нашли — пусто
+func (h *Handler) notificationRouteTitle(ctx context.Context, t *domain.JobTarget) string {
+	var ver *models.RouteVersion
+	var err error
+	if t.VersionID != 0 {
+		ver, err = h.routes.VersionByID(ctx, t.VersionID)
+	} else {
+		ver, err = h.routes.Details(ctx, t.RouteID)
+	}
+	if err != nil {
+		log.Printf("Error getting route %d for notification: %v", t.RouteID, err)
+		return ""
+	}
+	return ver.Title
+}
+
+// showNotificationSettings — экран настроек уведомлений
+func (h *Handler) showNotificationSettings(u *mux.UpdateCtx) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	st, err := h.reminders.Settings(u.Ctx, usr.ID)
+	if err != nil {
+		return err
+	}
+
+	l := i18n.From(u.Ctx)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, kind := range notificationKinds {
+		text := l.T("notify.off", l.T("notify.kind."+kind))
+		if st.Enabled(kind) {
+			text = l.T("notify.on", l.T("notify.kind."+kind))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, h.cb.NotifyToggle.Data(u.Ctx, kindRef{kind}))))
+	}
+	quiet := l.T("notify.quiet_off")
+	if st.QuietFrom != nil && st.QuietTo != nil {
+		quiet = l.T("notify.quiet", *st.QuietFrom, *st.QuietTo)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(quiet, CallbackQuietHours)),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackProfile)),
+	)
+	h.showScreen(screenOf(u), screen{
+		Text:   l.T("notify.title", h.reminders.Location().String()),
+		Markup: tgbotapi.NewInlineKeyboardMarkup(rows...),
+	})
+	return nil
+}
+
+func (h *Handler) toggleNotification(u *mux.UpdateCtx, v kindRef) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if err := h.reminders.Toggle(u.Ctx, usr.ID, v.Kind); err != nil {
+		return err
+	}
+	return h.showNotificationSettings(u)
+}
+
+func (h *Handler) cycleQuietHours(u *mux.UpdateCtx) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if err := h.reminders.CycleQuietHours(u.Ctx, usr.ID); err != nil {
+		return err
+	}
+	return h.showNotificationSettings(u)
+}
+
+// rateRoute — оценка из просьбы об отзыве; кнопки заменяются благодарностью
+func (h *Handler) rateRoute(u *mux.UpdateCtx, v reviewRef) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if err := h.profile.Rate(u.Ctx, usr.ID, v.RouteID, v.Rating); err != nil {
+		return err
+	}
+	l := i18n.From(u.Ctx)
+	h.showScreen(screenOf(u), screen{
+		Text: l.T("notify.review_thanks", strings.Repeat("⭐", v.Rating)),
+		Markup: tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu))),
+	})
+	return nil
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import "walki/internal/keyboards"
+
+// Элементов на странице списков (города, маршруты, заказы)
+const listPageSize = 8
+
+// loadPage загружает страницу page (с нуля). Если страница опустела — данные удалили,
+// пока пользователь листал, — показывает последнюю существующую.
+func loadPage[T any](page int, load func(limit, offset int) ([]T, int, error)) ([]T, int, int, error) {
+	if page < 0 {
+		page = 0
+	}
+	items, total, err := load(listPageSize, page*listPageSize)
+	if err != nil || len(items) > 0 || page == 0 {
+		return items, total, page, err
+	}
+	// на пустой странице total неизвестен: узнаём его с первой
+	items, total, err = load(listPageSize, 0)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	last := keyboards.Pages(total, listPageSize) - 1
+	if last == 0 {
+		return items, total, 0, nil
+	}
+	items, total, err = load(listPageSize, last*listPageSize)
+	return items, total, last, err
+}
@@ -1,139 +1,187 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"walki/internal/domain"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/keyboards"
+	"walki/internal/render"
+	"walki/internal/service"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (h *Handler) handleProfile(update tgbotapi.Update) {
-	chatID := update.Message.Chat.ID
+func (h *Handler) handleProfile(u *mux.UpdateCtx) {
+	h.showProfile(newScreen(u.Ctx, u.ChatID), u.Update.Message.From.ID)
+}
+
+func (h *Handler) showProfile(t screenTarget, tgID int64) {
+	l := i18n.From(t.Ctx)
 
 	// Создаем инлайн-клавиатуру для профиля
-	myRoutesBtn := tgbotapi.NewInlineKeyboardButtonData("🚶 Мои маршруты", CallbackMyRoutes)
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CallbackMainMenu)
+	myRoutesBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("profile.my_routes"), CallbackMyRoutes)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackMainMenu)
+
+	favoritesBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("profile.favorites"), h.cb.Favorites.Data(t.Ctx, pageRef{0}))
+	langBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("profile.language", l.T("lang."+l.Lang())), CallbackLanguages)
+	notifyBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("profile.notifications"), CallbackNotifications)
+
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(myRoutesBtn, favoritesBtn)}
+	// Реферальная ссылка: пригласившим засчитываются только новые пользователи
+	if user, err := h.users.GetByTelegramID(t.Ctx, tgID); err == nil {
+		link := startLink(h.bot.Self().UserName, refStartPayload(user.ReferralCode))
+		inviteBtn := tgbotapi.NewInlineKeyboardButtonURL(l.T("profile.invite"), shareURL(link, l.T("profile.invite_text")))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(inviteBtn))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(langBtn, notifyBtn), tgbotapi.NewInlineKeyboardRow(backBtn))
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
 
-	markup := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(myRoutesBtn),
-		tgbotapi.NewInlineKeyboardRow(backBtn),
-	)
+	h.showScreen(t, screen{Text: l.T("profile.title"), Markup: markup})
+}
+
+// showLanguages — выбор языка; название языка — на нём самом
+func (h *Handler) showLanguages(u *mux.UpdateCtx) error {
+	l := i18n.From(u.Ctx)
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Languages {
+		text := i18n.For(lang).T("lang." + lang)
+		if lang == l.Lang() {
+			text = "✅ " + text
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, h.cb.Language.Data(u.Ctx, langRef{lang}))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackProfile)))
+	h.showScreen(screenOf(u), screen{Text: l.T("profile.choose_language"), Markup: tgbotapi.NewInlineKeyboardMarkup(rows...)})
+	return nil
+}
+
+// setLanguage сохраняет язык в профиле и сразу переводит reply-клавиатуру
+func (h *Handler) setLanguage(u *mux.UpdateCtx, v langRef) error {
+	usr, err := requireUser(u)
+	if err != nil {
+		return err
+	}
+	if err := h.users.SetLanguage(u.Ctx, usr, v.Lang); err != nil {
+		if errors.Is(err, service.ErrInvalidValue) {
+			return mux.Invalid("", err)
+		}
+		return err
+	}
+	u.Ctx = i18n.WithLang(u.Ctx, v.Lang) // профиль ниже — уже на новом языке
 
-	msg := tgbotapi.NewMessage(chatID, "👤 Ваш профиль\n\nЗдесь вы можете управлять своими маршрутами и настройки")
-	msg.ReplyMarkup = markup
+	l := i18n.For(v.Lang)
+	msg := tgbotapi.NewMessage(u.ChatID, l.T("profile.language_set", l.T("lang."+v.Lang)))
+	msg.ReplyMarkup = keyboards.MainMenu(l)
 	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
+		return err
 	}
+	h.showProfile(screenOf(u), usr.TelegramID)
+	return nil
 }
 
-func (h *Handler) showUserRoutes(chatID int64, userID int) {
+// showUserRoutes — страница купленных маршрутов
+func (h *Handler) showUserRoutes(t screenTarget, userID, page int) error {
+	chatID := t.ChatID
+	l := i18n.From(t.Ctx)
 	// Получаем маршруты пользователя
-	orders, err := h.profile.MyOrders(context.Background(), userID)
+	orders, total, page, err := loadPage(page, func(limit, offset int) ([]domain.OrderSummary, int, error) {
+		return h.profile.MyOrdersPage(t.Ctx, userID, limit, offset)
+	})
 	if err != nil {
-		log.Printf("Error getting user orders: %v", err)
-		h.sendMessage(chatID, "Ошибка при загрузке ваших маршрутов")
-		return
+		return mux.Internal(l.T("profile.routes_error"), fmt.Errorf("get user orders: %w", err))
 	}
 
 	if len(orders) == 0 {
-		h.sendMessage(chatID, "У вас пока нет купленных маршрутов")
-		return
+		h.sendMessage(chatID, l.T("profile.no_routes"))
+		return nil
 	}
 
 	// Создаем кнопки для каждого маршрута
-	var routeButtons []tgbotapi.InlineKeyboardButton
+	var items []tgbotapi.InlineKeyboardButton
 	for _, order := range orders {
-		btnText := fmt.Sprintf("📍 %s (%s)", order.RouteTitle, order.RouteCity)
-		btnData := CallbackPurchasedRoute + strconv.Itoa(order.RouteID)
-		btn := tgbotapi.NewInlineKeyboardButtonData(btnText, btnData)
-		routeButtons = append(routeButtons, btn)
-	}
-
-	// Создаем rows для клавиатуры (максимум 1 кнопка в ряду)
-	var rows [][]tgbotapi.InlineKeyboardButton
-	for _, btn := range routeButtons {
-		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+		btnText := l.T("profile.route_item", order.RouteTitle, order.RouteCity)
+		btnData := h.cb.PurchasedRoute.Data(t.Ctx, idRef{order.RouteID})
+		items = append(items, tgbotapi.NewInlineKeyboardButtonData(btnText, btnData))
 	}
+	nav := keyboards.PageNav(page, listPageSize, total, func(p int) string {
+		return h.cb.MyRoutesPage.Data(t.Ctx, pageRef{p})
+	})
 
 	// Добавляем кнопку "Назад"
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CallbackMainMenu)
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(backBtn))
-
-	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackMainMenu)
+	markup := keyboards.Paginated(items, nav, tgbotapi.NewInlineKeyboardRow(backBtn))
 
-	msg := tgbotapi.NewMessage(chatID, "🚶 *Ваши маршруты:*\n\nВыберите маршрут для просмотра деталей:")
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = markup
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	h.showScreen(t, screen{Text: l.T("profile.routes_title"), Markup: markup})
+	return nil
 }
 
-func (h *Handler) showPurchasedRouteDetails(chatID int64, userID int, routeID int) {
+func (h *Handler) showPurchasedRouteDetails(t screenTarget, userID int, routeID int) error {
+	chatID := t.ChatID
+	l := i18n.From(t.Ctx)
 	// Проверяем, есть ли у пользователя доступ к этому маршруту
-	hasAccess, err := h.profile.HasAccess(context.Background(), userID, routeID)
+	hasAccess, err := h.profile.HasAccess(t.Ctx, userID, routeID)
 	if err != nil {
-		log.Printf("Error checking access: %v", err)
-		h.sendMessage(chatID, "Ошибка при проверке доступа к маршруту")
-		return
+		return mux.Internal(l.T("profile.access_error"), fmt.Errorf("check access: %w", err))
 	}
 
 	if !hasAccess {
-		h.sendMessage(chatID, "У вас нет доступа к этому маршруту")
-		return
+		h.sendMessage(chatID, l.T("profile.no_access"))
+		return nil
 	}
 
 	// Получаем информацию о заказе для получения даты истечения доступа
-	orders, err := h.profile.MyOrders(context.Background(), userID)
+	orders, err := h.profile.MyOrders(t.Ctx, userID)
 	if err != nil {
-		log.Printf("Error getting user orders: %v", err)
-		h.sendMessage(chatID, "Ошибка при загрузке информации о доступе")
-		return
+		return mux.Internal(l.T("profile.access_info_error"), fmt.Errorf("get user orders: %w", err))
 	}
 
 	// Ищем заказ и версию для этого маршрута
-	var message string
+	var card render.PurchasedRoute
 	for _, order := range orders {
 		if order.RouteID == routeID {
-			route, err := h.routes.VersionByID(context.Background(), order.VersionID)
+			route, err := h.routes.VersionByID(t.Ctx, order.VersionID)
 			if err != nil {
-				log.Printf("Error getting route version: %v", err)
-				h.sendMessage(chatID, "Ошибка при загрузке информации о версии маршрута")
-				return
+				return mux.Internal(l.T("profile.version_error"), fmt.Errorf("get route version: %w", err))
 			}
-			var expiryInfo string
+			card.Version = route
 			if order.AccessExpiry != nil {
-				expiryInfo = fmt.Sprintf("доступен до %s", order.AccessExpiry.Format("02.01.2006"))
+				card.Access = l.T("profile.access_until", order.AccessExpiry.Format("02.01.2006"))
 			} else {
-				expiryInfo = "бессрочный доступ"
+				card.Access = l.T("profile.access_forever")
 			}
-			// Формируем сообщение с информацией о маршруте
-			message = fmt.Sprintf(
-				"🚶 *%s*\n*Город:* %s\n*Описание:* %s\n*Протяженность:* %.1f км\n*Время прогулки:* %d мин\n\n*Статус:* %s",
-				route.Title, route.City, route.Description, route.LengthKm, route.DurationMinutes, expiryInfo,
-			)
 			break
 		}
 	}
+	if card.Version == nil {
+		h.sendMessage(chatID, l.T("profile.no_access"))
+		return nil
+	}
 
 	// Создаем кнопки для управления маршрутом
 	startRouteBtn := tgbotapi.NewInlineKeyboardButtonData(
-		"🎯 Начать прогулку",
-		CallbackStartRoute+strconv.Itoa(routeID),
+		l.T("profile.start_walk"),
+		h.cb.StartRoute.Data(t.Ctx, idRef{routeID}),
 	)
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CallbackMyRoutes)
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackMyRoutes)
 
-	markup := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(startRouteBtn),
-		tgbotapi.NewInlineKeyboardRow(backBtn),
-	)
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(startRouteBtn)}
 
-	msg := tgbotapi.NewMessage(chatID, message)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = markup
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
+	// Новая версия не подменяет купленную молча — предлагаем обновиться явно
+	if _, latest, ok, err := h.run.UpdateAvailable(t.Ctx, userID, routeID); err != nil {
+		log.Printf("Error checking route update: %v", err)
+	} else if ok {
+		card.NewVersion = latest.VersionNumber
+		upgradeBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("profile.upgrade"), h.cb.UpgradeRoute.Data(t.Ctx, idRef{routeID}))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(upgradeBtn))
 	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(backBtn))
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	h.showScreen(t, screen{Text: render.PurchasedRouteCard(l, card), ParseMode: render.ParseMode, Markup: markup})
+	return nil
 }
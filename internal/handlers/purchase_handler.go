@@ -1,58 +1,55 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"fmt"
-	"log"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
 	"walki/internal/models"
+	"walki/internal/render"
+	"walki/internal/service"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (h *Handler) handlePurchase(chatID int64, user *models.User, routeID int) {
+// handlePurchase — покупка с карточки маршрута; подтверждение заменяет карточку
+func (h *Handler) handlePurchase(t screenTarget, user *models.User, routeID int) error {
+	l := i18n.From(t.Ctx)
 
 	// Получаем информацию о маршруте для определения цены
-	ctx := context.Background()
+	ctx := t.Ctx
 	route, err := h.routes.Details(ctx, routeID)
+	if errors.Is(err, service.ErrRouteUnavailable) {
+		return mux.NotFound(l.T("route.unavailable"), err)
+	}
 	if err != nil {
-		log.Printf("Error getting route details for purchase: %v", err)
-		h.sendMessage(chatID, "Ошибка при получении информации о маршруте")
-		return
+		return mux.Internal(l.T("purchase.route_error"), fmt.Errorf("get route details for purchase: %w", err))
 	}
 
 	// Создаем заказ (заглушка оплаты)
 	order, err := h.orders.Purchase(ctx, user.ID, routeID)
 	if err != nil {
-		log.Printf("Error creating order: %v", err)
-		h.sendMessage(chatID, "Ошибка при создании заказа")
-		return
+		return mux.Internal(l.T("purchase.order_error"), fmt.Errorf("create order: %w", err))
 	}
 
 	// Отправляем подтверждение покупки
-	message := fmt.Sprintf(
-		"🎉 Поздравляем с покупкой!\n\n"+
-			"📍 *%s*\n"+
-			"💰 Стоимость: %.2f руб.\n"+
-			"📅 Доступен до: %s\n\n"+
-			"Чтобы начать прогулку, перейдите в раздел \"👤 Профиль\" -> \"Мои маршруты\"",
-		route.Title,
-		order.Amount,
-		order.AccessExpiry.Format("02.01.2006"),
-	)
+	message := render.PurchaseDone(l, render.Purchase{
+		Title:       route.Title,
+		Amount:      order.Amount,
+		AccessUntil: order.AccessExpiry.Format("02.01.2006"),
+		PromoCode:   order.PromoCode,
+		Discount:    order.Discount,
+	})
 
 	// Кнопки для навигации
-	profileBtn := tgbotapi.NewInlineKeyboardButtonData("👤 Перейти в профиль", CallbackMyRoutes)
-	menuBtn := tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackMainMenu)
+	profileBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("purchase.to_profile"), CallbackMyRoutes)
+	menuBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu)
 
 	markup := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(profileBtn),
 		tgbotapi.NewInlineKeyboardRow(menuBtn),
 	)
 
-	msg := tgbotapi.NewMessage(chatID, message)
-	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = markup
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	h.showScreen(t, screen{Text: message, ParseMode: render.ParseMode, Markup: markup})
+	return nil
 }
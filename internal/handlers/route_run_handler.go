@@ -2,13 +2,11 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"net/url"
-	"strconv"
-	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/i18n"
+	"walki/internal/render"
 	"walki/internal/service"
 )
 
@@ -18,18 +16,34 @@ import (
 2) Голос (отдельным сообщением)
 Всегда удаляем предыдущие сообщения, чтобы карточка была внизу чата.
 */
-func (h *Handler) renderRoutePoint(chatID int64, userID int, data *service.PointWithMedia) {
-	kb := h.navKeyboard(data.RouteID, data.HasPrev, data.HasNext)
-	caption := buildCaption(data)
+func (h *Handler) renderRoutePoint(ctx context.Context, chatID int64, userID int, data *service.PointWithMedia) {
+	l := i18n.From(ctx)
+	kb := h.navKeyboard(ctx, l, data.RouteID, data.HasPrev, data.HasNext)
+	if data.Sample {
+		kb = h.sampleKeyboard(ctx, l, data)
+	}
+	// с фото текст точки уходит подписью — у неё свой лимит
+	limit := render.TextLimit
+	if len(data.PhotoIds) > 0 {
+		limit = render.CaptionLimit
+	}
+	caption := render.PointCaption(l, render.Point{
+		Title:       data.Point.Title,
+		Arrival:     data.Point.ArrivalInstructions,
+		Description: data.Point.Description,
+		Lat:         data.Point.Lat,
+		Lon:         data.Point.Lon,
+		Sample:      data.Sample,
+	}, limit)
 
 	// Поднимаем карточку: удаляем старые content+voice, шлём новые
 	h.deleteIfExists(chatID, data.ContentMsgID)
 	h.deleteIfExists(chatID, data.VoiceMsgID)
 
-	if err := h.sendFreshContent(chatID, userID, data, caption, kb); err != nil {
+	if err := h.sendFreshContent(ctx, chatID, userID, data, caption, kb); err != nil {
 		log.Printf("send fresh content: %v", err)
 	}
-	if err := h.sendFreshVoice(chatID, userID, data); err != nil {
+	if err := h.sendFreshVoice(ctx, chatID, userID, data); err != nil {
 		log.Printf("send fresh voice: %v", err)
 	}
 }
@@ -46,73 +60,81 @@ func (h *Handler) deleteIfExists(chatID int64, msgID *int) {
 }
 
 // Отправить новое контент-сообщение (фото+подпись или текст) и сохранить его message_id
-func (h *Handler) sendFreshContent(chatID int64, userID int, data *service.PointWithMedia, caption string, kb tgbotapi.InlineKeyboardMarkup) error {
+func (h *Handler) sendFreshContent(ctx context.Context, chatID int64, userID int, data *service.PointWithMedia, caption string, kb tgbotapi.InlineKeyboardMarkup) error {
 	// если есть фото — отправляем его через сервис (кэш TG + presigned S3)
 	if len(data.PhotoIds) > 0 {
-		_, msgID, err := h.tgMedia.SendMedia(h.ctx(), h.bot, chatID, data.PhotoIds[0], caption, "Markdown")
+		_, msgID, err := h.tgMedia.SendMedia(ctx, h.bot, chatID, data.PhotoIds[0], caption, render.ParseMode, kb)
 		if err != nil {
 			return err
 		}
-		return h.run.UpdateMessageIDs(h.ctx(), userID, data.VersionID, &msgID, nil)
+		return h.saveMessageIDs(ctx, userID, data, &msgID, nil)
 	}
 
 	// иначе — текстовая «страница»
 	m := tgbotapi.NewMessage(chatID, caption)
-	m.ParseMode = "Markdown"
+	m.ParseMode = render.ParseMode
 	m.ReplyMarkup = kb
 	sent, err := h.bot.Send(m)
 	if err != nil {
 		return err
 	}
-	return h.run.UpdateMessageIDs(h.ctx(), userID, data.VersionID, &sent.MessageID, nil)
+	return h.saveMessageIDs(ctx, userID, data, &sent.MessageID, nil)
 }
 
 // Отправить новое voice-/audio-сообщение (если есть) и сохранить его message_id, иначе очистить voice_msg_id
-func (h *Handler) sendFreshVoice(chatID int64, userID int, data *service.PointWithMedia) error {
+func (h *Handler) sendFreshVoice(ctx context.Context, chatID int64, userID int, data *service.PointWithMedia) error {
 	if len(data.VoiceIds) == 0 {
 		// очистить voice в прогрессе
 		zero := 0 // репозиторий должен трактовать 0 как NULL (через NULLIF)
-		return h.run.UpdateMessageIDs(h.ctx(), userID, data.VersionID, nil, &zero)
+		return h.saveMessageIDs(ctx, userID, data, nil, &zero)
 	}
 
 	// Отправляем через сервис (сам решит, чем слать по MIME; для аудио это будет Audio/Document)
-	_, msgID, err := h.tgMedia.SendMedia(h.ctx(), h.bot, chatID, data.VoiceIds[0], "", "")
+	_, msgID, err := h.tgMedia.SendMedia(ctx, h.bot, chatID, data.VoiceIds[0], "", "", nil)
 	if err != nil {
 		return err
 	}
-	return h.run.UpdateMessageIDs(h.ctx(), userID, data.VersionID, nil, &msgID)
+	return h.saveMessageIDs(ctx, userID, data, nil, &msgID)
+}
+
+// Запомнить message_id в прогрессе; у пробной точки прогресса нет
+func (h *Handler) saveMessageIDs(ctx context.Context, userID int, data *service.PointWithMedia, contentMsgID, voiceMsgID *int) error {
+	if data.Sample {
+		return nil
+	}
+	return h.run.UpdateMessageIDs(ctx, userID, data.VersionID, contentMsgID, voiceMsgID)
 }
 
 /* =========================
    UI/вёрстка
    ========================= */
 
-func (h *Handler) navKeyboard(routeID int, hasPrev, hasNext bool) tgbotapi.InlineKeyboardMarkup {
+func (h *Handler) navKeyboard(ctx context.Context, l i18n.Localizer, routeID int, hasPrev, hasNext bool) tgbotapi.InlineKeyboardMarkup {
 	row := []tgbotapi.InlineKeyboardButton{}
 	if hasPrev {
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", "route_prev:"+strconv.Itoa(routeID)))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.T("run.prev"), h.cb.PrevRoute.Data(ctx, idRef{routeID})))
 	}
 	if hasNext {
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData("▶️ Дальше", "route_next:"+strconv.Itoa(routeID)))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.T("run.next"), h.cb.NextRoute.Data(ctx, idRef{routeID})))
 	} else {
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData("🏁 Завершить", "route_finish:"+strconv.Itoa(routeID)))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.T("run.finish"), h.cb.FinishRoute.Data(ctx, idRef{routeID})))
 	}
 	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(row...))
 }
 
-func buildCaption(p *service.PointWithMedia) string {
-	title := "📍 *" + escapeMd(p.Point.Title) + "*"
-	desc := trimTo(850, escapeMd(p.Point.Description)) // чтобы вместе с координатами уложиться в лимит
-	lat := fmt.Sprintf("%.6f", p.Point.Lat)
-	lon := fmt.Sprintf("%.6f", p.Point.Lon)
-	mapsURL := "https://maps.google.com/?q=" + url.QueryEscape(lat+","+lon)
-
-	// без Markdown-ссылок — просто URL, меньше шансов словить parse error
-	return fmt.Sprintf("%s\n\n%s\n\n`%s, %s`\n%s", title, desc, lat, lon, mapsURL)
-}
-
-func escapeMd(s string) string {
-	return strings.NewReplacer("_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]", "`", "\\`").Replace(s)
+// Пробные точки листаются между собой и всегда предлагают купить маршрут
+func (h *Handler) sampleKeyboard(ctx context.Context, l i18n.Localizer, data *service.PointWithMedia) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if data.HasNext {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("run.sample_next"), h.cb.Sample.Data(ctx, sampleRef{data.RouteID, data.Idx + 1})),
+		))
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("run.sample_buy"), h.cb.Buy.Data(ctx, idRef{data.RouteID}))),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(l.T("run.sample_back"), h.cb.Route.Data(ctx, idRef{data.RouteID}))),
+	)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 func trimTo(n int, s string) string {
@@ -122,5 +144,3 @@ func trimTo(n int, s string) string {
 	}
 	return string(rs[:n-1]) + "…"
 }
-
-func (h *Handler) ctx() context.Context { return context.Background() }
@@ -1,130 +1,240 @@
 package handlers
 
 import (
-	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/keyboards"
+	"walki/internal/models"
+	"walki/internal/render"
+	"walki/internal/service"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (h *Handler) handleRoutes(update tgbotapi.Update) {
-	chatID := update.Message.Chat.ID
-	h.showCitySelection(chatID)
+func (h *Handler) handleRoutes(u *mux.UpdateCtx) error {
+	return h.showCitySelection(newScreen(u.Ctx, u.ChatID), 0)
 }
 
-func (h *Handler) showCitySelection(chatID int64) {
-	cities, err := h.routes.Cities(context.Background())
+// showCitySelection — страница городов
+func (h *Handler) showCitySelection(t screenTarget, page int) error {
+	chatID := t.ChatID
+	l := i18n.From(t.Ctx)
+	cities, total, page, err := loadPage(page, func(limit, offset int) ([]string, int, error) {
+		return h.routes.Cities(t.Ctx, limit, offset)
+	})
 	if err != nil {
-		log.Printf("Error getting cities: %v", err)
-		h.sendMessage(chatID, "Ошибка при загрузке городов")
-		return
+		return mux.Internal(l.T("catalog.cities_error"), fmt.Errorf("get cities: %w", err))
 	}
 
 	if len(cities) == 0 {
-		h.sendMessage(chatID, "Пока нет маршрутов ни в одном городе")
-		return
+		h.sendMessage(chatID, l.T("catalog.no_cities"))
+		return nil
 	}
 
 	// Создаем инлайн-клавиатуру с городами
-	var rows [][]tgbotapi.InlineKeyboardButton
+	var items []tgbotapi.InlineKeyboardButton
 	for _, city := range cities {
-		btn := tgbotapi.NewInlineKeyboardButtonData(city, CallbackCity+city)
-		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+		items = append(items, tgbotapi.NewInlineKeyboardButtonData(city, h.cb.City.Data(t.Ctx, cityRef{city})))
 	}
+	nav := keyboards.PageNav(page, listPageSize, total, func(p int) string {
+		return h.cb.Cities.Data(t.Ctx, pageRef{p})
+	})
 
+	nearbyBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("catalog.nearby"), CallbackNearbyAsk)
 	// Кнопка "Назад" к главному меню
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CallbackMainMenu)
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(backBtn))
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), CallbackMainMenu)
 
-	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
-	msg := tgbotapi.NewMessage(chatID, "Выберите город:")
-	msg.ReplyMarkup = markup
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	markup := keyboards.Paginated(items, nav,
+		tgbotapi.NewInlineKeyboardRow(nearbyBtn),
+		tgbotapi.NewInlineKeyboardRow(backBtn),
+	)
+	h.showScreen(t, screen{Text: l.T("catalog.choose_city"), Markup: markup})
+	return nil
 }
 
-func (h *Handler) showRoutesByCity(chatID int64, city string) {
-	routes, err := h.routes.ListByCity(context.Background(), city)
+// showRoutesByCity — страница маршрутов города
+func (h *Handler) showRoutesByCity(t screenTarget, city string, page int) error {
+	chatID := t.ChatID
+	l := i18n.From(t.Ctx)
+	routes, total, page, err := loadPage(page, func(limit, offset int) ([]models.RouteVersion, int, error) {
+		return h.routes.ListByCity(t.Ctx, city, limit, offset)
+	})
 	if err != nil {
-		log.Printf("Error getting routes for city %s: %v", city, err)
-		h.sendMessage(chatID, "Ошибка при загрузке маршрутов")
-		return
+		return mux.Internal(l.T("catalog.routes_error"), fmt.Errorf("get routes for city %s: %w", city, err))
 	}
 
 	if len(routes) == 0 {
-		h.sendMessage(chatID, fmt.Sprintf("В городе %s пока нет маршрутов", city))
-		return
+		h.sendMessage(chatID, l.T("catalog.no_routes", city))
+		return nil
 	}
 
 	// Создаем инлайн-клавиатуру с маршрутами
-	var rows [][]tgbotapi.InlineKeyboardButton
+	var items []tgbotapi.InlineKeyboardButton
 	for _, route := range routes {
-		btnText := fmt.Sprintf("📍 %s (%.1f км)", route.Title, route.LengthKm)
-		btnData := CallbackRoute + strconv.Itoa(route.RouteID)
-		btn := tgbotapi.NewInlineKeyboardButtonData(btnText, btnData)
-		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+		btnText := l.T("catalog.route_item", route.Title, route.LengthKm)
+		btnData := h.cb.Route.Data(t.Ctx, idRef{route.RouteID})
+		items = append(items, tgbotapi.NewInlineKeyboardButtonData(btnText, btnData))
+	}
+	nav := keyboards.PageNav(page, listPageSize, total, func(p int) string {
+		return h.cb.CityPage.Data(t.Ctx, cityPageRef{City: city, Page: p})
+	})
+
+	var tail [][]tgbotapi.InlineKeyboardButton
+	if payload := cityStartPayload(city); payload != "" {
+		link := startLink(h.bot.Self().UserName, payload)
+		shareBtn := tgbotapi.NewInlineKeyboardButtonURL(l.T("catalog.share"), shareURL(link, l.T("catalog.share_city", city)))
+		tail = append(tail, tgbotapi.NewInlineKeyboardRow(shareBtn))
 	}
 
 	// Кнопки навигации
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад к городам", CallbackSelectCity)
-	menuBtn := tgbotapi.NewInlineKeyboardButtonData("🏠 Главное меню", CallbackMainMenu)
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(backBtn, menuBtn))
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("catalog.back_to_cities"), CallbackSelectCity)
+	menuBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu)
+	tail = append(tail, tgbotapi.NewInlineKeyboardRow(backBtn, menuBtn))
 
-	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
-	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Маршруты в городе %s:", city))
-	msg.ReplyMarkup = markup
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	markup := keyboards.Paginated(items, nav, tail...)
+	h.showScreen(t, screen{Text: l.N("catalog.city_routes", total, total, city), Markup: markup})
+	return nil
 }
 
-func (h *Handler) showRouteDetails(chatID int64, routeID int) {
-	version, err := h.routes.Details(context.Background(), routeID)
+func (h *Handler) showRouteDetails(t screenTarget, userID, routeID int) error {
+	l := i18n.From(t.Ctx)
+	version, err := h.routes.Details(t.Ctx, routeID)
+	if errors.Is(err, service.ErrRouteUnavailable) {
+		return mux.NotFound(l.T("route.unavailable"), err)
+	}
 	if err != nil {
-		log.Printf("Error getting route details for ID %d: %v", routeID, err)
-		h.sendMessage(chatID, "Ошибка при загрузке информации о маршруте")
-		return
+		return mux.Internal(l.T("route.details_error"), fmt.Errorf("get route details for ID %d: %w", routeID, err))
 	}
 
-	message := fmt.Sprintf(
-		"🚶 *%s*\n*Город:* %s\n*Описание:* %s\n*Протяженность:* %.1f км\n*Время прогулки:* %d мин\n*Тематика:* %s\n*Цена:* %.2f руб.",
-		version.Title, version.City, version.Description, version.LengthKm,
-		version.DurationMinutes, version.Theme, version.Price,
-	)
+	// с обложкой карточка уходит подписью к фото
+	limit := render.TextLimit
+	if version.CoverMediaID != 0 {
+		limit = render.CaptionLimit
+	}
+	message := render.RouteCard(l, version, limit)
 
 	// Создаем кнопки для действий
-	buyBtn := tgbotapi.NewInlineKeyboardButtonData("💰 Купить", CallbackBuy+strconv.Itoa(routeID))
-	backBtn := tgbotapi.NewInlineKeyboardButtonData("⬅️ Назад", CallbackSelectCity)
-	markup := tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(buyBtn),
+	buyBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("route.buy"), h.cb.Buy.Data(t.Ctx, idRef{routeID}))
+	shareBtn := tgbotapi.NewInlineKeyboardButtonURL(l.T("catalog.share"), h.routeShareURL(l, version))
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("common.back"), h.cb.City.Data(t.Ctx, cityRef{version.City}))
+	rows := [][]tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardRow(buyBtn)}
+	// Пробная точка — попробовать маршрут до покупки
+	if n, err := h.run.SampleCount(t.Ctx, version.ID); err != nil {
+		log.Printf("Error counting preview points: %v", err)
+	} else if n > 0 {
+		sampleBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("route.sample"), h.cb.Sample.Data(t.Ctx, sampleRef{routeID, 0}))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(sampleBtn))
+	}
+	// Превью-галерея, если кроме обложки есть другие фото
+	if gallery, err := h.routes.Gallery(t.Ctx, version.ID); err != nil {
+		log.Printf("Error getting route gallery: %v", err)
+	} else if len(gallery) > 1 {
+		galleryBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("route.gallery", len(gallery)), h.cb.Gallery.Data(t.Ctx, idRef{version.ID}))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(galleryBtn))
+	}
+	rows = append(rows,
+		h.favoriteButtons(t.Ctx, l, userID, routeID),
+		tgbotapi.NewInlineKeyboardRow(shareBtn),
 		tgbotapi.NewInlineKeyboardRow(backBtn),
 	)
+	markup := tgbotapi.NewInlineKeyboardMarkup(rows...)
+
+	// Если есть обложка — фото с подписью, иначе текст
+	h.showScreen(t, screen{Text: message, ParseMode: render.ParseMode, Markup: markup, PhotoMediaID: version.CoverMediaID})
+	return nil
+}
+
+// showSample показывает пробную точку через обычный рендер точки, без проверки покупки
+func (h *Handler) showSample(u *mux.UpdateCtx, v sampleRef) error {
+	data, err := h.run.Sample(u.Ctx, v.ID, v.N)
+	if errors.Is(err, service.ErrNoSample) {
+		return mux.NotFound(i18n.From(u.Ctx).T("run.no_samples"), err)
+	}
+	if err != nil {
+		return err
+	}
+	// прогресс у пробной точки не ведётся, пользователь рендеру не нужен
+	h.renderRoutePoint(u.Ctx, u.ChatID, 0, data)
+	return nil
+}
+
+// Альбом Telegram вмещает до 10 фото
+const maxGalleryPhotos = 10
+
+// showGallery отправляет фото версии альбомом; file_id новых фото кэшируются
+func (h *Handler) showGallery(u *mux.UpdateCtx, versionID int) error {
+	ids, err := h.routes.Gallery(u.Ctx, versionID)
+	if err != nil {
+		return err
+	}
+	if len(ids) > maxGalleryPhotos {
+		ids = ids[:maxGalleryPhotos]
+	}
+
+	var (
+		media    []interface{}
+		uncached = map[int]int64{} // позиция в альбоме -> media.id
+		sources  []int64
+	)
+	for _, id := range ids {
+		file, cached, err := h.tgMedia.PhotoFile(u.Ctx, id)
+		if err != nil {
+			log.Printf("Error resolving gallery photo %d: %v", id, err)
+			continue
+		}
+		if !cached {
+			uncached[len(media)] = id
+		}
+		media = append(media, tgbotapi.NewInputMediaPhoto(file))
+		sources = append(sources, id)
+	}
+	switch len(media) {
+	case 0:
+		return mux.NotFound(i18n.From(u.Ctx).T("route.no_photos"), nil)
+	case 1:
+		// альбом из одного фото Telegram не принимает
+		photo := tgbotapi.NewPhoto(u.ChatID, media[0].(tgbotapi.InputMediaPhoto).Media)
+		sent, err := h.bot.Send(photo)
+		if err != nil {
+			return err
+		}
+		if _, ok := uncached[0]; ok {
+			h.tgMedia.RememberPhoto(u.Ctx, sources[0], sent)
+		}
+		return nil
+	}
 
-	// Если есть обложка, отправляем фото с подписью
-	if version.CoverImageURL != "" {
-		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(version.CoverImageURL))
-		photo.Caption = message
-		photo.ParseMode = "Markdown"
-		photo.ReplyMarkup = markup
-
-		if _, err := h.bot.Send(photo); err != nil {
-			log.Printf("Error sending photo: %v", err)
-			// Если не удалось отправить фото, отправляем текстовое сообщение
-			h.sendMessageWithMarkup(chatID, message, markup)
+	resp, err := h.bot.Request(tgbotapi.NewMediaGroup(u.ChatID, media))
+	if err != nil {
+		return err
+	}
+	var sent []tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		log.Printf("Error decoding media group response: %v", err)
+		return nil
+	}
+	for i, id := range uncached {
+		if i < len(sent) {
+			h.tgMedia.RememberPhoto(u.Ctx, id, sent[i])
 		}
-	} else {
-		// Если нет обложки, отправляем текстовое сообщение
-		h.sendMessageWithMarkup(chatID, message, markup)
 	}
+	return nil
+}
+
+// routeShareURL — ссылка «переслать другу», открывающая карточку маршрута
+func (h *Handler) routeShareURL(l i18n.Localizer, version *models.RouteVersion) string {
+	link := startLink(h.bot.Self().UserName, routeStartPayload(version.RouteID))
+	return shareURL(link, l.T("route.share_text", version.Title, version.City))
 }
 
-// Вспомогательная функция для отправки сообщения с разметкой
+// Вспомогательная функция для отправки сообщения из шаблона (HTML-разметка)
 func (h *Handler) sendMessageWithMarkup(chatID int64, text string, markup tgbotapi.InlineKeyboardMarkup) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = "Markdown"
+	msg.ParseMode = render.ParseMode
 	msg.ReplyMarkup = markup
 	if _, err := h.bot.Send(msg); err != nil {
 		log.Printf("Error sending message: %v", err)
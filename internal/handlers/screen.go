@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"walki/internal/handlers/mux"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+/*
+Экраны навигации (каталог, карточка маршрута, профиль, поиск) показываются
+в одном сообщении: переход по кнопке правит сообщение, с которого пришёл callback.
+
+  - текст -> текст: editMessageText;
+  - фото -> фото: editMessageMedia (подпись и кнопки вместе с фото);
+  - текст <-> фото: тип сообщения не меняется — удаляем старое и шлём новое.
+
+Последний экран чата запоминается; когда экран переезжает в другое сообщение,
+у прежнего снимаются кнопки, чтобы устаревшие меню не срабатывали.
+*/
+
+// screen — содержимое экрана
+type screen struct {
+	Text      string
+	ParseMode string
+	Markup    tgbotapi.InlineKeyboardMarkup
+	Photo     tgbotapi.RequestFileData // nil — текстовый экран
+	// PhotoMediaID — фото из media: file_id из кэша или presigned-ссылка S3 (через tgmedia)
+	PhotoMediaID int64
+}
+
+// screenTarget — где показать экран
+type screenTarget struct {
+	Ctx    context.Context // контекст апдейта
+	ChatID int64
+	Msg    *tgbotapi.Message // сообщение с нажатой кнопкой; nil — новое сообщение
+}
+
+func newScreen(ctx context.Context, chatID int64) screenTarget {
+	return screenTarget{Ctx: ctx, ChatID: chatID}
+}
+
+// screenOf — экран, из которого пришёл апдейт: callback правит своё сообщение
+func screenOf(u *mux.UpdateCtx) screenTarget {
+	t := screenTarget{Ctx: u.Ctx, ChatID: u.ChatID}
+	if cb := u.Update.CallbackQuery; cb != nil && cb.Message != nil {
+		t.Msg = cb.Message
+	}
+	return t
+}
+
+// screenTracker — последнее сообщение-экран каждого чата. Это лишь подсказка,
+// какие кнопки снять: сам переход правит сообщение из callback (screenTarget.Msg),
+// поэтому после перезапуска или на другой реплике теряется только снятие
+// старых кнопок, а не навигация.
+type screenTracker struct {
+	mu     sync.Mutex
+	last   map[int64]screenEntry
+	lastGC time.Time
+}
+
+type screenEntry struct {
+	msgID int
+	at    time.Time
+}
+
+const (
+	// screenTTL — экраны старше забываются: чат давно не заходил в бот
+	screenTTL        = 24 * time.Hour
+	screenGCInterval = time.Minute
+)
+
+func newScreenTracker() *screenTracker {
+	return &screenTracker{last: map[int64]screenEntry{}, lastGC: time.Now()}
+}
+
+// swap запоминает msgID экраном чата и возвращает прежний (0 — неизвестен или забыт)
+func (t *screenTracker) swap(chatID int64, msgID int) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if now.Sub(t.lastGC) > screenGCInterval {
+		for id, e := range t.last {
+			if now.Sub(e.at) > screenTTL {
+				delete(t.last, id)
+			}
+		}
+		t.lastGC = now
+	}
+	prev := t.last[chatID]
+	t.last[chatID] = screenEntry{msgID: msgID, at: now}
+	if now.Sub(prev.at) > screenTTL {
+		return 0
+	}
+	return prev.msgID
+}
+
+// showScreen выводит экран: правит исходное сообщение или отправляет новое
+func (h *Handler) showScreen(t screenTarget, s screen) {
+	var remember int64 // фото ушло по ссылке — запомним file_id
+	if s.Photo == nil && s.PhotoMediaID != 0 {
+		file, cached, err := h.tgMedia.PhotoFile(t.Ctx, s.PhotoMediaID)
+		if err != nil {
+			log.Printf("Error resolving screen photo %d: %v", s.PhotoMediaID, err)
+		} else {
+			s.Photo = file
+			if !cached {
+				remember = s.PhotoMediaID
+			}
+		}
+	}
+
+	if t.Msg != nil {
+		if edited, ok := h.editScreen(t, s); ok {
+			if remember != 0 && edited != nil {
+				h.tgMedia.RememberPhoto(t.Ctx, remember, *edited)
+			}
+			h.markScreen(t.ChatID, t.Msg.MessageID, 0)
+			return
+		}
+	}
+	dropped := 0
+	if t.Msg != nil && (t.Msg.Photo != nil) != (s.Photo != nil) {
+		// тип сообщения не совпадает: старый экран убираем, чтобы не дублировать
+		h.dropScreen(t.ChatID, t.Msg.MessageID)
+		dropped = t.Msg.MessageID
+	}
+
+	sent, err := h.sendScreen(t.ChatID, s)
+	if err != nil {
+		log.Printf("Error sending screen: %v", err)
+		return
+	}
+	if remember != 0 {
+		h.tgMedia.RememberPhoto(t.Ctx, remember, sent)
+	}
+	h.markScreen(t.ChatID, sent.MessageID, dropped)
+}
+
+// editScreen правит сообщение на месте; false — править нельзя, нужен новый экран.
+// Возвращает изменённое сообщение, если Telegram его прислал.
+func (h *Handler) editScreen(t screenTarget, s screen) (*tgbotapi.Message, bool) {
+	msgID := t.Msg.MessageID
+	isPhoto := t.Msg.Photo != nil
+	var req tgbotapi.Chattable
+	switch {
+	case s.Photo == nil && !isPhoto:
+		edit := tgbotapi.NewEditMessageTextAndMarkup(t.ChatID, msgID, s.Text, s.Markup)
+		edit.ParseMode = s.ParseMode
+		req = edit
+	case s.Photo != nil && isPhoto:
+		media := tgbotapi.NewInputMediaPhoto(s.Photo)
+		media.Caption = s.Text
+		media.ParseMode = s.ParseMode
+		markup := s.Markup
+		req = tgbotapi.EditMessageMediaConfig{
+			BaseEdit: tgbotapi.BaseEdit{ChatID: t.ChatID, MessageID: msgID, ReplyMarkup: &markup},
+			Media:    media,
+		}
+	default:
+		return nil, false
+	}
+
+	resp, err := h.bot.Request(req)
+	if err != nil {
+		if strings.Contains(err.Error(), "message is not modified") {
+			return nil, true
+		}
+		// сообщение удалено, слишком старое и т.п.
+		log.Printf("Error editing screen: %v", err)
+		return nil, false
+	}
+	var edited tgbotapi.Message
+	if json.Unmarshal(resp.Result, &edited) != nil {
+		return nil, true
+	}
+	return &edited, true
+}
+
+func (h *Handler) sendScreen(chatID int64, s screen) (tgbotapi.Message, error) {
+	if s.Photo != nil {
+		photo := tgbotapi.NewPhoto(chatID, s.Photo)
+		photo.Caption = s.Text
+		photo.ParseMode = s.ParseMode
+		photo.ReplyMarkup = s.Markup
+		sent, err := h.bot.Send(photo)
+		if err == nil {
+			return sent, nil
+		}
+		// фото недоступно — показываем экран без него
+		log.Printf("Error sending photo: %v", err)
+	}
+	msg := tgbotapi.NewMessage(chatID, s.Text)
+	msg.ParseMode = s.ParseMode
+	msg.ReplyMarkup = s.Markup
+	return h.bot.Send(msg)
+}
+
+// markScreen делает msgID текущим экраном чата и гасит кнопки прежнего (кроме уже убранного skip)
+func (h *Handler) markScreen(chatID int64, msgID, skip int) {
+	if prev := h.screens.swap(chatID, msgID); prev != 0 && prev != msgID && prev != skip {
+		h.disableScreen(chatID, prev)
+	}
+}
+
+// dropScreen удаляет экран; удалить нельзя (сообщение старше 48 часов) — снимает кнопки
+func (h *Handler) dropScreen(chatID int64, msgID int) {
+	if _, err := h.bot.Request(tgbotapi.NewDeleteMessage(chatID, msgID)); err != nil {
+		h.disableScreen(chatID, msgID)
+	}
+}
+
+func (h *Handler) disableScreen(chatID int64, msgID int) {
+	empty := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if _, err := h.bot.Request(tgbotapi.NewEditMessageReplyMarkup(chatID, msgID, empty)); err != nil &&
+		!strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Error disabling stale screen: %v", err)
+	}
+}
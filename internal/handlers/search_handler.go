@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Поиск: текст запроса и фильтры живут в callback_data (searchRef), поэтому
+// любую кнопку экрана результатов можно нажать повторно и через час.
+const (
+	stateSearchQuery = "search:query"
+	searchStateTTL   = 15 * time.Minute
+
+	searchResultsLimit = 10
+	maxSearchQuery     = 64 // символов
+
+	searchFieldTheme    = "theme"
+	searchFieldDuration = "duration"
+	searchFieldLength   = "length"
+	searchFieldPrice    = "price"
+)
+
+// rangePreset — вариант фильтра по диапазону; 0 — граница не задана.
+// Key — ключ подписи в каталоге i18n.
+type rangePreset struct {
+	Key      string
+	Min, Max float64
+}
+
+// Индекс 0 у всех фильтров — «без ограничения»
+var (
+	durationPresets = []rangePreset{{"search.duration.any", 0, 0}, {"search.duration.short", 0, 60}, {"search.duration.medium", 60, 120}, {"search.duration.long", 120, 0}}
+	lengthPresets   = []rangePreset{{"search.length.any", 0, 0}, {"search.length.short", 0, 3}, {"search.length.medium", 3, 7}, {"search.length.long", 7, 0}}
+	pricePresets    = []rangePreset{{"search.price.any", 0, 0}, {"search.price.low", 0, 300}, {"search.price.medium", 300, 700}, {"search.price.high", 700, 0}}
+)
+
+func preset(presets []rangePreset, i int) rangePreset {
+	if i < 0 || i >= len(presets) {
+		return presets[0]
+	}
+	return presets[i]
+}
+
+func (v searchRef) filter() models.RouteFilter {
+	d, l, p := preset(durationPresets, v.Duration), preset(lengthPresets, v.Length), preset(pricePresets, v.Price)
+	return models.RouteFilter{
+		Query:       v.Query,
+		Theme:       v.Theme,
+		MinDuration: int(d.Min),
+		MaxDuration: int(d.Max),
+		MinLength:   l.Min,
+		MaxLength:   l.Max,
+		MinPrice:    p.Min,
+		MaxPrice:    p.Max,
+	}
+}
+
+// describe — «тематика: История, время: до 1 часа»
+func (v searchRef) describe(l i18n.Localizer) string {
+	var parts []string
+	if v.Theme != "" {
+		parts = append(parts, l.T("search.part.theme", v.Theme))
+	}
+	if v.Duration > 0 {
+		parts = append(parts, l.T("search.part.duration", l.T(preset(durationPresets, v.Duration).Key)))
+	}
+	if v.Length > 0 {
+		parts = append(parts, l.T("search.part.length", l.T(preset(lengthPresets, v.Length).Key)))
+	}
+	if v.Price > 0 {
+		parts = append(parts, l.T("search.part.price", l.T(preset(pricePresets, v.Price).Key)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleSearch — кнопка «🔍 Поиск» и /search [запрос]
+func (h *Handler) handleSearch(u *mux.UpdateCtx) error {
+	if msg := u.Update.Message; msg != nil && msg.IsCommand() {
+		if q := strings.TrimSpace(msg.CommandArguments()); q != "" {
+			return h.showSearchResults(u, searchRef{Query: trimTo(maxSearchQuery, q)})
+		}
+	}
+	if err := u.SetState(stateSearchQuery, nil, searchStateTTL); err != nil {
+		return err
+	}
+	l := i18n.From(u.Ctx)
+	filtersBtn := tgbotapi.NewInlineKeyboardButtonData(l.T("search.by_filters"), h.cb.Search.Data(u.Ctx, searchRef{}))
+	msg := tgbotapi.NewMessage(u.ChatID, l.T("search.ask"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(filtersBtn))
+	_, err := h.bot.Send(msg)
+	return err
+}
+
+// searchOnQuery — текст запроса в состоянии stateSearchQuery
+func (h *Handler) searchOnQuery(u *mux.UpdateCtx) error {
+	q := strings.TrimSpace(u.Update.Message.Text)
+	if q == "" {
+		return mux.Invalid(i18n.From(u.Ctx).T("search.need_text"), nil)
+	}
+	if err := u.ClearState(); err != nil {
+		return err
+	}
+	return h.showSearchResults(u, searchRef{Query: trimTo(maxSearchQuery, q)})
+}
+
+func (h *Handler) showSearchResults(u *mux.UpdateCtx, v searchRef) error {
+	v.Field = ""
+	routes, err := h.routes.Search(u.Ctx, v.filter(), searchResultsLimit+1, 0)
+	if err != nil {
+		return err
+	}
+	more := len(routes) > searchResultsLimit
+	if more {
+		routes = routes[:searchResultsLimit]
+	}
+
+	l := i18n.From(u.Ctx)
+	var b strings.Builder
+	b.WriteString(l.T("search.title"))
+	if v.Query != "" {
+		b.WriteString(l.T("search.query", v.Query))
+	}
+	if d := v.describe(l); d != "" {
+		b.WriteString(l.T("search.filters", d))
+	}
+	switch {
+	case len(routes) == 0:
+		b.WriteString(l.T("search.nothing"))
+	case more:
+		b.WriteString(l.T("search.first_n", searchResultsLimit))
+	default:
+		b.WriteString(l.T("search.found"))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, r := range routes {
+		btnText := l.T("search.item", r.Title, r.City, r.LengthKm)
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(btnText, h.cb.Route.Data(u.Ctx, idRef{r.RouteID}))))
+	}
+	rows = append(rows, h.searchFilterRows(u.Ctx, l, v)...)
+
+	h.showScreen(screenOf(u), screen{Text: b.String(), Markup: tgbotapi.NewInlineKeyboardMarkup(rows...)})
+	return nil
+}
+
+// searchFilterRows — кнопки фильтров под результатами
+func (h *Handler) searchFilterRows(ctx context.Context, l i18n.Localizer, v searchRef) [][]tgbotapi.InlineKeyboardButton {
+	pick := func(text, field string) tgbotapi.InlineKeyboardButton {
+		ref := v
+		ref.Field = field
+		return tgbotapi.NewInlineKeyboardButtonData(text, h.cb.SearchFilter.Data(ctx, ref))
+	}
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(pick(l.T("search.filter.theme"), searchFieldTheme), pick(l.T("search.filter.duration"), searchFieldDuration)),
+		tgbotapi.NewInlineKeyboardRow(pick(l.T("search.filter.length"), searchFieldLength), pick(l.T("search.filter.price"), searchFieldPrice)),
+	}
+	if v.describe(l) != "" {
+		reset := searchRef{Query: v.Query}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T("search.reset"), h.cb.Search.Data(ctx, reset))))
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("common.main_menu"), CallbackMainMenu)))
+	return rows
+}
+
+// showSearchFilter — варианты одного фильтра; выбор сразу показывает результаты
+func (h *Handler) showSearchFilter(u *mux.UpdateCtx, v searchRef) error {
+	l := i18n.From(u.Ctx)
+	var (
+		title string
+		rows  [][]tgbotapi.InlineKeyboardButton
+	)
+	option := func(text string, ref searchRef) {
+		ref.Field = ""
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(text, h.cb.Search.Data(u.Ctx, ref))))
+	}
+	presets := func(list []rangePreset, current int, set func(*searchRef, int)) {
+		for i, p := range list {
+			text := l.T(p.Key)
+			if i == current {
+				text = "✅ " + text
+			}
+			ref := v
+			set(&ref, i)
+			option(text, ref)
+		}
+	}
+
+	switch v.Field {
+	case searchFieldTheme:
+		title = l.T("search.pick_theme")
+		themes, err := h.routes.Themes(u.Ctx)
+		if err != nil {
+			return err
+		}
+		anyTheme := v
+		anyTheme.Theme = ""
+		option(l.T("search.theme.any"), anyTheme)
+		for _, t := range themes {
+			ref := v
+			ref.Theme = t
+			text := t
+			if strings.EqualFold(t, v.Theme) {
+				text = "✅ " + t
+			}
+			option(text, ref)
+		}
+	case searchFieldDuration:
+		title = l.T("search.pick_duration")
+		presets(durationPresets, v.Duration, func(r *searchRef, i int) { r.Duration = i })
+	case searchFieldLength:
+		title = l.T("search.pick_length")
+		presets(lengthPresets, v.Length, func(r *searchRef, i int) { r.Length = i })
+	case searchFieldPrice:
+		title = l.T("search.pick_price")
+		presets(pricePresets, v.Price, func(r *searchRef, i int) { r.Price = i })
+	default:
+		return mux.Invalid(l.T("search.unknown_filter"), nil)
+	}
+
+	option(l.T("search.back"), v)
+
+	h.showScreen(screenOf(u), screen{Text: title, Markup: tgbotapi.NewInlineKeyboardMarkup(rows...)})
+	return nil
+}
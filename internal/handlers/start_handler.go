@@ -2,16 +2,21 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
 	"walki/internal/keyboards"
 	"walki/internal/models"
+	"walki/internal/service"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-func (h *Handler) handleStart(update tgbotapi.Update) {
+func (h *Handler) handleStart(upd *mux.UpdateCtx) error {
+	update := upd.Update
 	chatID := update.Message.Chat.ID
 	user := update.Message.From
 
@@ -25,13 +30,55 @@ func (h *Handler) handleStart(update tgbotapi.Update) {
 	}
 
 	// Сохраняем пользователя в БД
-	if err := h.users.Register(context.Background(), u); err != nil {
+	created, err := h.users.Register(upd.Ctx, u)
+	if err != nil {
 		log.Printf("Ошибка сохранения пользователя: %v", err)
 	}
 
-	msg := tgbotapi.NewMessage(chatID, "Привет 👋 Добро пожаловать в Walki!")
-	msg.ReplyMarkup = keyboards.MainMenu()
+	l := i18n.From(upd.Ctx)
+	msg := tgbotapi.NewMessage(chatID, l.T("start.welcome"))
+	msg.ReplyMarkup = keyboards.MainMenu(l)
 	if _, err := h.bot.Send(msg); err != nil {
 		log.Printf("Error sending message: %v", err)
 	}
+
+	// Без сохранённого пользователя диплинк обработать нельзя
+	if err != nil {
+		return nil
+	}
+	return h.handleDeepLink(upd.Ctx, chatID, u, created, parseDeepLink(update.Message.CommandArguments()))
+}
+
+// handleDeepLink — переход по ссылке ?start=<payload> сразу после регистрации
+func (h *Handler) handleDeepLink(ctx context.Context, chatID int64, u *models.User, created bool, link deepLink) error {
+	l := i18n.From(ctx)
+	switch link.Kind {
+	case deepLinkRoute:
+		return h.showRouteDetails(newScreen(ctx, chatID), u.ID, link.RouteID)
+
+	case deepLinkCity:
+		return h.showRoutesByCity(newScreen(ctx, chatID), link.City, 0)
+
+	case deepLinkPromo:
+		promo, err := h.orders.ActivatePromo(ctx, u.ID, link.Promo)
+		switch {
+		case errors.Is(err, service.ErrPromoNotFound):
+			h.sendMessage(chatID, l.T("start.promo_not_found"))
+		case errors.Is(err, service.ErrPromoExpired):
+			h.sendMessage(chatID, l.T("start.promo_expired"))
+		case err != nil:
+			log.Printf("Error activating promo %q: %v", link.Promo, err)
+			h.sendMessage(chatID, l.T("start.promo_failed"))
+		default:
+			h.sendMessage(chatID, l.T("start.promo_activated", promo.Code, promo.DiscountPercent))
+			return h.showCitySelection(newScreen(ctx, chatID), 0)
+		}
+
+	case deepLinkRef:
+		if _, err := h.users.AttributeReferral(ctx, u, created, link.Ref); err != nil {
+			log.Printf("Error attributing referral %q -> %d: %v", link.Ref, u.ID, err)
+		}
+		return h.showCitySelection(newScreen(ctx, chatID), 0)
+	}
+	return nil
 }
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"walki/internal/handlers/mux"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/service"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+/*
+Переводы маршрута: автор добавляет к черновику название, описание, тексты точек
+и озвучку на других языках. Фото общие, непереведённое показывается в оригинале.
+*/
+
+const (
+	stateAuthorTrField        = "author:tr_field"         // v, l, f
+	stateAuthorTrPointText    = "author:tr_point_text"    // v, p, l
+	stateAuthorTrPointArrival = "author:tr_point_arrival" // v, p, l
+	stateAuthorTrPointAudio   = "author:tr_point_audio"   // v, p, l
+)
+
+// Переводимые поля версии — в порядке кнопок
+var translationFields = []string{service.FieldTitle, service.FieldDescription}
+
+// translationButtons — кнопки переводов для редактора: все языки, кроме оригинала
+func (h *Handler) translationButtons(ctx context.Context, l i18n.Localizer, versionID int) []tgbotapi.InlineKeyboardButton {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range i18n.Languages {
+		if lang == i18n.Default {
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			l.T("author.translation_btn", l.T("lang_name."+lang)), h.cb.AuthorTranslation.Data(ctx, trRef{versionID, lang})))
+	}
+	return row
+}
+
+// showAuthorTranslation — перевод черновика на язык lang
+func (h *Handler) showAuthorTranslation(ctx context.Context, chatID int64, usr *models.User, versionID int, lang string) error {
+	ver, err := h.authoring.Draft(ctx, usr, versionID)
+	if err != nil {
+		return &authorError{"draft", err}
+	}
+	tr, err := h.authoring.Translation(ctx, usr, versionID, lang)
+	if err != nil {
+		return &authorError{"translation", err}
+	}
+
+	l := i18n.From(ctx)
+	var b strings.Builder
+	b.WriteString(l.T("author.tr_header", ver.Title, l.T("lang_name."+lang)))
+	b.WriteString(l.T("author.tr_title", orDash(tr.Version.Title)))
+	b.WriteString(l.T("author.tr_description", orDash(trimTo(300, tr.Version.Description))))
+	b.WriteString(l.T("author.tr_points"))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var fieldRow []tgbotapi.InlineKeyboardButton
+	for _, f := range translationFields {
+		fieldRow = append(fieldRow, tgbotapi.NewInlineKeyboardButtonData(
+			l.T("author.field."+f), h.cb.AuthorTrField.Data(ctx, trFieldRef{versionID, lang, f})))
+	}
+	rows = append(rows, fieldRow)
+
+	var pointRow []tgbotapi.InlineKeyboardButton
+	for i, p := range tr.Points {
+		b.WriteString(l.T("author.tr_point_item", i+1, p.Title, orDash(tr.Texts[p.ID].Title)))
+		pointRow = append(pointRow, tgbotapi.NewInlineKeyboardButtonData(
+			l.T("author.tr_point_btn", i+1), h.cb.AuthorTrPoint.Data(ctx, trPointRef{versionID, p.ID, lang})))
+		if len(pointRow) == 4 {
+			rows = append(rows, pointRow)
+			pointRow = nil
+		}
+	}
+	if len(pointRow) > 0 {
+		rows = append(rows, pointRow)
+	}
+	if len(tr.Points) == 0 {
+		b.WriteString(l.T("author.points_empty"))
+	}
+	b.WriteString(l.T("author.tr_hint"))
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("author.back_to_route"), h.cb.AuthorVersion.Data(ctx, idRef{versionID}))))
+	h.sendPlain(chatID, b.String(), tgbotapi.NewInlineKeyboardMarkup(rows...))
+	return nil
+}
+
+func (h *Handler) authorAskTrField(u *mux.UpdateCtx, usr *models.User, v trFieldRef) error {
+	if _, err := h.authoring.Draft(u.Ctx, usr, v.VersionID); err != nil {
+		return &authorError{"ask translation field", err}
+	}
+	h.setAuthorState(u, stateAuthorTrField, map[string]string{"v": strconv.Itoa(v.VersionID), "l": v.Lang, "f": v.Field})
+	l := i18n.From(u.Ctx)
+	h.sendMessage(u.ChatID, l.T("author.tr_prompt."+v.Field, l.T("lang_name."+v.Lang))+l.T("author.cancel_hint"))
+	return nil
+}
+
+func (h *Handler) authorAskTrPoint(u *mux.UpdateCtx, usr *models.User, v trPointRef) error {
+	tr, err := h.authoring.Translation(u.Ctx, usr, v.VersionID, v.Lang)
+	if err != nil {
+		return &authorError{"ask translation point", err}
+	}
+	title := ""
+	for _, p := range tr.Points {
+		if p.ID == v.PointID {
+			title = p.Title
+		}
+	}
+	h.setAuthorState(u, stateAuthorTrPointText, map[string]string{
+		"v": strconv.Itoa(v.VersionID), "p": strconv.Itoa(v.PointID), "l": v.Lang,
+	})
+	l := i18n.From(u.Ctx)
+	h.sendMessage(u.ChatID, l.T("author.tr_ask_point_text", title, l.T("lang_name."+v.Lang)))
+	return nil
+}
+
+// === Ответы автора
+
+func (h *Handler) authorOnTrField(u *mux.UpdateCtx, usr *models.User) error {
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	lang := u.StateData("l")
+	if err := h.authoring.SetTranslationField(u.Ctx, usr, versionID, lang, u.StateData("f"), u.Update.Message.Text); err != nil {
+		return &authorError{"set translation field", err}
+	}
+	h.clearAuthorState(u)
+	return h.showAuthorTranslation(u.Ctx, u.ChatID, usr, versionID, lang)
+}
+
+func (h *Handler) authorOnTrPointText(u *mux.UpdateCtx, usr *models.User) error {
+	text := u.Update.Message.Text
+	if text == "" {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.need_point_text"))
+		return nil
+	}
+	pointID, _ := strconv.Atoi(u.StateData("p"))
+	lang := u.StateData("l")
+	if err := h.authoring.SetPointTranslation(u.Ctx, usr, pointID, lang, text); err != nil {
+		return &authorError{"point translation", err}
+	}
+	h.setAuthorState(u, stateAuthorTrPointArrival, u.State.Data)
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	l := i18n.From(u.Ctx)
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("author.tr_skip"), h.cb.AuthorTrArrivalSkip.Data(u.Ctx, trPointRef{versionID, pointID, lang})),
+	))
+	h.sendPlain(u.ChatID, l.T("author.tr_ask_arrival"), kb)
+	return nil
+}
+
+func (h *Handler) authorOnTrPointArrival(u *mux.UpdateCtx, usr *models.User) error {
+	text := u.Update.Message.Text
+	if text == "" {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.need_arrival"))
+		return nil
+	}
+	versionID, _ := strconv.Atoi(u.StateData("v"))
+	pointID, _ := strconv.Atoi(u.StateData("p"))
+	lang := u.StateData("l")
+	if err := h.authoring.SetPointArrivalTranslation(u.Ctx, usr, pointID, lang, text); err != nil {
+		return &authorError{"arrival translation", err}
+	}
+	h.authorAskTrAudio(u, trPointRef{versionID, pointID, lang})
+	return nil
+}
+
+// authorAskTrAudio — последний шаг перевода точки: озвучка на языке перевода
+func (h *Handler) authorAskTrAudio(u *mux.UpdateCtx, v trPointRef) {
+	h.setAuthorState(u, stateAuthorTrPointAudio, map[string]string{
+		"v": strconv.Itoa(v.VersionID), "p": strconv.Itoa(v.PointID), "l": v.Lang,
+	})
+	l := i18n.From(u.Ctx)
+	kb := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(l.T("author.done"), h.cb.AuthorTranslation.Data(u.Ctx, trRef{v.VersionID, v.Lang})),
+	))
+	h.sendPlain(u.ChatID, l.T("author.tr_ask_audio"), kb)
+}
+
+func (h *Handler) authorOnTrPointAudio(u *mux.UpdateCtx, usr *models.User) error {
+	pointID, _ := strconv.Atoi(u.StateData("p"))
+	msg := u.Update.Message
+	if msg.Voice == nil && msg.Audio == nil {
+		h.sendMessage(u.ChatID, i18n.From(u.Ctx).T("author.need_audio"))
+		return nil
+	}
+	return h.authorAttachMedia(u.Ctx, u.ChatID, usr, pointID, u.StateData("l"), msg)
+}
@@ -0,0 +1,73 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Server — встроенный HTTP-сервер: вебхук Telegram, платёжные вебхуки, healthcheck.
+// Обработчики регистрируются до Run.
+type Server struct {
+	mux *http.ServeMux
+	srv *http.Server
+}
+
+func New(addr string) *Server {
+	s := newServer(addr)
+	s.mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	return s
+}
+
+// NewDebug — служебный сервер с метриками expvar (очереди, задержки обработки).
+// Отдаёт cmdline и memstats, поэтому слушает отдельный внутренний адрес, а не
+// публичный, на котором висит вебхук.
+func NewDebug(addr string) *Server {
+	s := newServer(addr)
+	s.mux.Handle("GET /debug/vars", expvar.Handler())
+	return s
+}
+
+func newServer(addr string) *Server {
+	m := http.NewServeMux()
+	return &Server{
+		mux: m,
+		srv: &http.Server{
+			Addr:              addr,
+			Handler:           m,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+}
+
+func (s *Server) Handle(pattern string, h http.Handler) { s.mux.Handle(pattern, h) }
+
+func (s *Server) HandleFunc(pattern string, h http.HandlerFunc) { s.mux.HandleFunc(pattern, h) }
+
+// Run слушает адрес до отмены ctx, затем корректно останавливает сервер
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("http: listening on %s", s.srv.Addr)
+		if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return s.srv.Shutdown(shutdownCtx)
+}
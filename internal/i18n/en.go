@@ -0,0 +1,338 @@
+package i18n
+
+// en — английский каталог
+var en = map[string]string{
+	// Общее
+	"common.cancelled":         "Cancelled",
+	"common.state_timeout":     "⌛️ The reply timed out, please start over.",
+	"common.default":           "Choose an action on the keyboard ⌨️",
+	"common.cancel":            "❌ Cancel",
+	"common.back":              "⬅️ Back",
+	"common.main_menu":         "🏠 Main menu",
+	"menu.main":                "Main menu",
+	"error.internal":           "Something went wrong, please try again.",
+	"error.not_found":          "Not found.",
+	"error.forbidden":          "⛔️ Not enough permissions",
+	"error.invalid":            "Invalid data.",
+	"error.stale_button":       "This button has expired, please open the menu again.",
+	"error.no_user":            "Error: user not found, try /start",
+	"error.invalid_transition": "This action is not available in the route's current status",
+	"error.too_often":          "Too fast 🙂 Wait a couple of seconds and try again.",
+	"error.banned":             "⛔️ Access to the bot is restricted until %s",
+
+	// Кнопки reply-клавиатуры
+	"btn.routes":        "🚶 Routes",
+	"btn.search":        "🔍 Search",
+	"btn.profile":       "👤 Profile",
+	"btn.help":          "ℹ️ Help",
+	"btn.send_location": "📍 Send location",
+	"btn.main_menu":     "🏠 Main menu",
+
+	// Прохождение маршрута
+	"run.continue":         "▶️ Continue",
+	"run.restart":          "🔁 Start over",
+	"run.already_started":  "You have already started this route. What would you like to do?",
+	"run.no_access":        "No access or the route is empty.",
+	"run.already_finished": "🏁 The route is already finished.",
+	"run.restart_failed":   "Could not start over.",
+	"run.upgrade_failed":   "Could not update the route.",
+	"run.upgraded":         "✅ The route has been updated to version %d",
+	"run.finished_last":    "🏁 Route finished! Come back to your profile to walk it again.",
+	"run.first_point":      "This is the first point of the route.",
+	"run.finish_failed":    "Error: could not finish the route, please contact support",
+	"run.finished":         "🏁 Route finished! Thanks for the walk.",
+	"run.prev":             "◀️ Back",
+	"run.next":             "▶️ Next",
+	"run.finish":           "🏁 Finish",
+	"run.sample_next":      "▶️ Next free point",
+	"run.sample_buy":       "💰 Buy the route",
+	"run.sample_back":      "⬅️ Back to the route",
+	"run.sample_note":      "👀 Free sample point. The rest unlock after purchase.",
+	"run.no_samples":       "This route has no free sample points",
+
+	// Каталог
+	"catalog.cities_error":      "Failed to load cities",
+	"catalog.no_cities":         "There are no routes in any city yet",
+	"catalog.nearby":            "📍 Near me",
+	"catalog.choose_city":       "Choose a city:",
+	"catalog.routes_error":      "Failed to load routes",
+	"catalog.no_routes":         "There are no routes in %s yet",
+	"catalog.route_item":        "📍 %s (%.1f km)",
+	"catalog.share":             "📤 Share",
+	"catalog.share_city":        "Walks around %s",
+	"catalog.back_to_cities":    "⬅️ Back to cities",
+	"catalog.city_routes#one":   "%[2]s — %[1]d route:",
+	"catalog.city_routes#few":   "%[2]s — %[1]d routes:",
+	"catalog.city_routes#many":  "%[2]s — %[1]d routes:",
+	"catalog.city_routes#other": "%[2]s — %[1]d routes:",
+
+	// Карточка маршрута
+	"route.unavailable":   "😔 This route is not available right now: it has been withdrawn or hidden from the catalog.",
+	"route.details_error": "Failed to load route details",
+	"route.buy":           "💰 Buy",
+	"route.sample":        "👀 Free sample point",
+	"route.gallery":       "🖼 Photos (%d)",
+	"route.no_photos":     "Route photos are not available yet",
+	"card.city":           "City:",
+	"card.description":    "Description:",
+	"card.length":         "Length:",
+	"card.duration":       "Walking time:",
+	"card.theme":          "Theme:",
+	"card.price":          "Price:",
+	"card.status":         "Status:",
+	"card.km":             "%.1f km",
+	"card.minutes":        "%d min",
+	"card.rub":            "%.2f RUB",
+	"route.share_text":    "🚶 %s — a walk around %s",
+
+	// Публикация (админы)
+	"status.draft":                  "📝 Draft",
+	"status.published":              "✅ Published",
+	"status.archived":               "📦 Archived",
+	"admin.route_id_usage":          "Specify the route ID: /%s <id>",
+	"admin.published":               "✅ Version %d of “%s” is published",
+	"admin.archived":                "📦 Route %d has been archived. Buyers keep their access.",
+	"admin.restored":                "✅ Route %d is back in the catalog",
+	"admin.shown":                   "👁 Route %d is shown in the catalog",
+	"admin.hidden":                  "🙈 Route %d is hidden from the catalog",
+	"admin.history_empty":           "The publication history is empty",
+	"admin.history_title":           "🗂 History of route %d:\n",
+	"admin.history_version":         " (version #%d)",
+	"admin.history_user":            ", user %d",
+	"admin.history_reason.replaced": "replaced by a newer version",
+	"admin.history_reason.hidden":   "hidden from the catalog",
+	"admin.history_reason.shown":    "shown in the catalog",
+	"admin.preview_header":          "%s · version %d",
+	"admin.route_unavailable":       "Error: the route was not found or is unavailable",
+
+	// Режим автора
+	"author.field.title":             "Title",
+	"author.field.city":              "City",
+	"author.field.description":       "Description",
+	"author.field.theme":             "Theme",
+	"author.field.duration_minutes":  "Duration",
+	"author.field.length_km":         "Length",
+	"author.field.price":             "Price",
+	"author.prompt.title":            "Enter the route title",
+	"author.prompt.city":             "Which city is the route in?",
+	"author.prompt.description":      "Send the route description",
+	"author.prompt.theme":            "Route theme (e.g. “Architecture”)",
+	"author.prompt.duration_minutes": "How many minutes does the walk take? Numbers only",
+	"author.prompt.length_km":        "Length in kilometres, e.g. 3.5",
+	"author.prompt.price":            "Price in roubles, e.g. 490",
+	"author.prompt.default":          "Enter a value",
+	"author.cancel_hint":             " (/cancel to cancel)",
+	"author.route_item":              "✏️ %s (%s)",
+	"author.new_route":               "➕ New route",
+	"author.menu":                    "🧭 Author mode\n\nChoose a route to edit or create a new one.",
+	"author.menu_empty":              "🧭 Author mode\n\nYou have no routes yet — create your first one!",
+	"author.ask_title":               "What will the route be called? (/cancel to cancel)",
+	"author.editor_header":           "✏️ %s · version %d (%s)\n\n",
+	"author.editor_meta":             "City: %s\nTheme: %s\n",
+	"author.editor_numbers":          "Duration: %d min · Length: %.1f km · Price: %.2f RUB\n",
+	"author.editor_description":      "Description: %s\n\n",
+	"author.editor_points#one":       "%d point",
+	"author.editor_points#few":       "%d points",
+	"author.editor_points#many":      "%d points",
+	"author.editor_points#other":     "%d points",
+	"author.editor_submitted":        "\n\n📤 Submitted for publication on %s",
+	"author.points_btn":              "📍 Points (%d)",
+	"author.add_point":               "➕ Point",
+	"author.preview":                 "👀 Preview",
+	"author.submit":                  "📤 Submit",
+	"author.back_to_routes":          "⬅️ Back to routes",
+	"author.back_to_route":           "⬅️ Back to the route",
+	"author.ask_location":            "📍 Send the point's location: 📎 → “Location”. (/cancel to cancel)",
+	"author.points_title":            "📍 Route points:\n",
+	"author.point_sample":            " — 👀 free sample",
+	"author.points_empty":            "\nNothing here yet — add the first point.",
+	"author.points_sample_hint":      "\n\n👀 — make the point a free sample, 🔒 — lock it again.",
+	"author.delete_confirm":          "🗑 Yes, delete",
+	"author.delete_cancel":           "Cancel",
+	"author.delete_ask":              "Delete the point together with its photos and narration?",
+	"author.preview_failed":          "Could not start the preview: add at least one point.",
+	"author.preview_intro":           "👀 Preview: this is how a buyer will see the route.",
+	"author.submit_incomplete":       "Before submitting, set the city and description and add at least one point.",
+	"author.submitted":               "📤 The route has been submitted. We will let you know when it appears in the catalog.",
+	"author.admin_submitted":         "📤 Submitted: “%s” (route %d, version %d) by %s",
+	"author.admin_publish":           "✅ Publish",
+	"author.created":                 "✅ The route has been created. Fill in the other fields and add points.",
+	"author.need_location":           "A location is needed: 📎 → “Location”. (/cancel to cancel)",
+	"author.new_point":               "New point",
+	"author.ask_point_text":          "✍️ Now the point text: the first line is the title, the rest is the story.",
+	"author.need_point_text":         "Send the text: the first line is the title, the rest is the story.",
+	"author.done":                    "✅ Done",
+	"author.ask_media":               "📷 Send photos and a 🎙 voice message with the story. When you are finished, tap “Done”.",
+	"author.photo_added":             "📷 Photo added",
+	"author.audio_added":             "🎙 Narration added",
+	"author.need_media":              "Send a photo or a voice message, or tap “Done”.",
+	"author.media_failed":            "Could not save the file, please try again.",
+	"author.forbidden":               "⛔️ Author mode is available to guides only",
+	"author.published_version":       "This version is already published — open the route again to create a draft",
+	"author.invalid_value":           "Invalid value, please try again (/cancel to cancel)",
+	"author.error":                   "Error, please try again",
+	"author.user_name":               "user %d",
+
+	// Избранное
+	"fav.add":         "⭐ Add to favorites",
+	"fav.added":       "🌟 In favorites",
+	"fav.notify_on":   "🔔 Notify me",
+	"fav.notify_off":  "🔕 No notifications",
+	"fav.error":       "Failed to load favorites",
+	"fav.empty":       "⭐ Your favorites are empty.\n\nAdd routes with the “⭐ Add to favorites” button on a route card — we will let you know about discounts and updates.",
+	"fav.item":        "⭐ %s (%s)",
+	"fav.title":       "⭐ Favorite routes:",
+	"fav.new_version": "🆕 The route “%s” from your favorites has been updated — version %d is out.",
+	"fav.discount":    "🔥 Discount on the route “%s” from your favorites: %.0f ₽ instead of %.0f ₽.",
+	"fav.view":        "👀 View",
+
+	// Рядом со мной
+	"nearby.ask":         "📍 Send your location — I will show routes that start near you.",
+	"nearby.searching":   "Location received, looking for routes nearby…",
+	"nearby.item":        "📍 %s — %s",
+	"nearby.wider":       "🔭 Search within %d km",
+	"nearby.choose_city": "🏙 Choose a city",
+	"nearby.title":       "📍 Routes within %d km, nearest first:",
+	"nearby.empty":       "There are no routes within %d km yet.",
+	"nearby.meters":      "%d m",
+	"nearby.km":          "%.1f km",
+
+	// Старт и диплинки
+	"start.welcome":         "Hi 👋 Welcome to Walki!",
+	"start.promo_not_found": "Promo code not found",
+	"start.promo_expired":   "This promo code has expired",
+	"start.promo_failed":    "Could not activate the promo code",
+	"start.promo_activated": "🎁 Promo code %s activated: %d%% off your next purchase",
+
+	// Инлайн-режим
+	"inline.nothing_found": "Nothing found — open the catalog",
+	"inline.description":   "%s · %.1f km · %d min · %.0f RUB",
+	"inline.open":          "Open in the bot",
+
+	// Помощь
+	"help.text": "ℹ️ Help is coming soon",
+
+	// Профиль
+	"profile.my_routes":         "🚶 My routes",
+	"profile.favorites":         "⭐ Favorites",
+	"profile.invite":            "🤝 Invite a friend",
+	"profile.invite_text":       "City audio walks in Walki",
+	"profile.language":          "🌐 Language: %s",
+	"profile.title":             "👤 Your profile\n\nHere you can manage your routes and settings",
+	"profile.routes_error":      "Failed to load your routes",
+	"profile.no_routes":         "You have not bought any routes yet",
+	"profile.route_item":        "📍 %s (%s)",
+	"profile.routes_title":      "🚶 Your routes:\n\nChoose a route to see the details:",
+	"profile.access_error":      "Failed to check access to the route",
+	"profile.no_access":         "You do not have access to this route",
+	"profile.access_info_error": "Failed to load access details",
+	"profile.version_error":     "Failed to load route version details",
+	"profile.access_until":      "available until %s",
+	"profile.access_forever":    "unlimited access",
+	"profile.start_walk":        "🎯 Start the walk",
+	"profile.new_version":       "🆕 A new version (%d) is available — update? Your progress will move to the same point.",
+	"profile.upgrade":           "🔄 Update",
+	"profile.choose_language":   "🌐 Choose the bot language:",
+	"profile.language_set":      "✅ Bot language: %s",
+
+	// Названия языков (на самом языке)
+	"lang.ru":      "🇷🇺 Русский",
+	"lang_name.ru": "Russian",
+	"lang_name.en": "English",
+	"lang.en":      "🇬🇧 English",
+
+	// Покупка
+	"purchase.route_error": "Failed to load route details",
+	"purchase.order_error": "Failed to create the order",
+	"purchase.congrats":    "🎉 Thank you for your purchase!",
+	"purchase.amount":      "💰 Price: %.2f RUB",
+	"purchase.until":       "📅 Available until: %s",
+	"purchase.hint":        "To start the walk, go to \"👤 Profile\" -> \"My routes\"",
+	"purchase.promo":       "🎁 Promo code %s: %.2f RUB off",
+	"purchase.to_profile":  "👤 Go to profile",
+
+	// Поиск
+	"search.duration.any":    "any",
+	"search.duration.short":  "up to 1 hour",
+	"search.duration.medium": "1–2 hours",
+	"search.duration.long":   "over 2 hours",
+	"search.length.any":      "any",
+	"search.length.short":    "up to 3 km",
+	"search.length.medium":   "3–7 km",
+	"search.length.long":     "over 7 km",
+	"search.price.any":       "any",
+	"search.price.low":       "up to 300 RUB",
+	"search.price.medium":    "300–700 RUB",
+	"search.price.high":      "over 700 RUB",
+	"search.theme.any":       "any",
+	"search.part.theme":      "theme: %s",
+	"search.part.duration":   "time: %s",
+	"search.part.length":     "length: %s",
+	"search.part.price":      "price: %s",
+	"search.by_filters":      "🎛 Pick by filters",
+	"search.ask":             "🔍 What are we looking for? Type a word from a title, description or point name — for example, “embankment”.\n\nCancel — /cancel",
+	"search.need_text":       "Type your query as text or tap /cancel",
+	"search.title":           "🔍 Search",
+	"search.query":           ": “%s”",
+	"search.filters":         "\nFilters: %s",
+	"search.nothing":         "\n\nNothing found. Try another query or relax the filters.",
+	"search.first_n":         "\n\nFirst %d routes — refine your query to narrow it down:",
+	"search.found":           "\n\nRoutes found:",
+	"search.item":            "📍 %s, %s (%.1f km)",
+	"search.filter.theme":    "🏷 Theme",
+	"search.filter.duration": "⏱ Time",
+	"search.filter.length":   "📏 Length",
+	"search.filter.price":    "💰 Price",
+	"search.reset":           "✖️ Reset filters",
+	"search.pick_theme":      "🏷 Choose a theme:",
+	"search.pick_duration":   "⏱ How much time do you have for the walk?",
+	"search.pick_length":     "📏 How long should the route be?",
+	"search.pick_price":      "💰 What price?",
+	"search.unknown_filter":  "Unknown filter",
+	"search.back":            "⬅️ Back to results",
+
+	// Модерация
+	"mod.ban_usage":   "Usage: /ban <telegram_id> [duration: 30m, 12h, 7d] [reason]",
+	"mod.bad_id":      "Telegram ID must be a number",
+	"mod.bad_ban":     "Invalid user or duration (a year at most)",
+	"mod.banned":      "⛔️ User %d is banned until %s",
+	"mod.unban_usage": "Usage: /unban <telegram_id>",
+	"mod.not_banned":  "User %d was not banned",
+	"mod.unbanned":    "✅ User %d has been unbanned",
+	"mod.routes":      "Bot routes:\n",
+
+	// Переводы маршрута (режим автора)
+	"author.translation_btn":       "🌐 Translation: %s",
+	"author.tr_header":             "🌐 Translation of “%s”: %s\n\n",
+	"author.tr_title":              "Title: %s\n",
+	"author.tr_description":        "Description: %s\n\n",
+	"author.tr_points":             "Points:",
+	"author.tr_point_item":         "\n%d. %s → %s",
+	"author.tr_hint":               "\n\nUntranslated content is shown in the original. ✏️ — point text; after it you can translate the directions and send narration in this language.",
+	"author.tr_point_btn":          "✏️ %d",
+	"author.tr_prompt.title":       "Route title in %s",
+	"author.tr_prompt.description": "Route description in %s",
+	"author.tr_ask_point_text":     "✍️ Point “%s” in %s: the first line is the title, the rest is the story. (/cancel to cancel)",
+	"author.tr_ask_arrival":        "🧭 How to get to this point in this language? Send the directions or tap “Skip” — the original ones will be shown instead.",
+	"author.tr_skip":               "⏭ Skip",
+	"author.need_arrival":          "Send the directions as text or tap “Skip”.",
+	"author.tr_ask_audio":          "🎙 Send narration in this language or tap “Done” — the original narration will be played instead.",
+	"author.need_audio":            "Send a voice message or audio, or tap “Done”.",
+
+	// Уведомления
+	"profile.notifications":      "🔔 Notifications",
+	"notify.title":               "🔔 Notifications\n\nChoose what to be reminded about. No messages arrive during quiet hours — they come later. Times are in the %s time zone.",
+	"notify.on":                  "✅ %s",
+	"notify.off":                 "▫️ %s",
+	"notify.kind.access_expiry":  "Access ending",
+	"notify.kind.progress_nudge": "Unfinished walks",
+	"notify.kind.review_request": "Rating requests",
+	"notify.quiet":               "🌙 Quiet hours: %02d:00–%02d:00",
+	"notify.quiet_off":           "🌙 Quiet hours: off",
+	"notify.settings_btn":        "🔔 Notification settings",
+	"notify.expiry":              "⏳ Your access to “%s” ends on %s. Don't miss your walk!",
+	"notify.nudge":               "🚶 Your walk “%s” isn't finished. Continue where you left off?",
+	"notify.review":              "🏁 You've completed “%s”! Rate it — this helps other travellers and the author.",
+	"notify.star":                "%d ⭐",
+	"notify.review_thanks":       "Thanks for your rating! %s",
+}
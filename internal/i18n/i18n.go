@@ -0,0 +1,141 @@
+// Package i18n — тексты бота на нескольких языках.
+//
+// Каталог языка — map ключ -> шаблон fmt. Формы множественного числа хранятся
+// под ключами "ключ#one", "ключ#few", "ключ#many", "ключ#other" (правила CLDR).
+// Если в каталоге языка ключа нет, берётся язык по умолчанию, затем сам ключ —
+// так пропущенный перевод виден, но бот не падает.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Поддерживаемые языки
+const (
+	RU = "ru"
+	EN = "en"
+
+	Default = RU
+)
+
+var catalogs = map[string]map[string]string{
+	RU: ru,
+	EN: en,
+}
+
+// Languages — порядок языков в меню выбора
+var Languages = []string{RU, EN}
+
+// Supported — есть ли каталог для языка
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Detect — язык по language_code Telegram ("en-US", "ru", "uk"...).
+// Пустой код — язык по умолчанию; русскоязычным соседям тоже понятнее русский.
+func Detect(code string) string {
+	code = strings.ToLower(code)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	switch code {
+	case "":
+		return Default
+	case "ru", "uk", "be", "kk":
+		return RU
+	}
+	if Supported(code) {
+		return code
+	}
+	return EN
+}
+
+// Localizer — тексты на одном языке
+type Localizer struct{ lang string }
+
+// For — локализатор языка; неизвестный язык — язык по умолчанию
+func For(lang string) Localizer {
+	if !Supported(lang) {
+		lang = Default
+	}
+	return Localizer{lang: lang}
+}
+
+func (l Localizer) Lang() string {
+	if l.lang == "" {
+		return Default
+	}
+	return l.lang
+}
+
+// T — текст по ключу; args подставляются как в fmt.Sprintf
+func (l Localizer) T(key string, args ...any) string {
+	return format(l.lookup(key), args)
+}
+
+// N — текст с формой множественного числа для n. Без args подставляется само n.
+func (l Localizer) N(key string, n int, args ...any) string {
+	if len(args) == 0 {
+		args = []any{n}
+	}
+	form := pluralForm(l.Lang(), n)
+	for _, f := range []string{form, "other", "many"} {
+		if s, ok := l.find(key + "#" + f); ok {
+			return format(s, args)
+		}
+	}
+	return format(key, args)
+}
+
+func (l Localizer) lookup(key string) string {
+	if s, ok := l.find(key); ok {
+		return s
+	}
+	return key
+}
+
+func (l Localizer) find(key string) (string, bool) {
+	if s, ok := catalogs[l.Lang()][key]; ok {
+		return s, true
+	}
+	s, ok := catalogs[Default][key]
+	return s, ok
+}
+
+func format(s string, args []any) string {
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf(s, args...)
+}
+
+// All — текст ключа на всех языках без повторов: так сообщение
+// (например, нажатая кнопка reply-клавиатуры) распознаётся независимо от языка
+func All(key string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, lang := range Languages {
+		s := For(lang).lookup(key)
+		if !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+type ctxKey struct{}
+
+// WithLang — язык текущего апдейта в контексте
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// From — локализатор языка из контекста (язык по умолчанию, если не задан)
+func From(ctx context.Context) Localizer {
+	lang, _ := ctx.Value(ctxKey{}).(string)
+	return For(lang)
+}
@@ -0,0 +1,338 @@
+package i18n
+
+// ru — каталог по умолчанию: в нём должны быть все ключи
+var ru = map[string]string{
+	// Общее
+	"common.cancelled":         "Действие отменено",
+	"common.state_timeout":     "⌛️ Время ожидания ответа истекло, начните заново.",
+	"common.default":           "Выбери действие с клавиатуры ⌨️",
+	"common.cancel":            "❌ Отмена",
+	"common.back":              "⬅️ Назад",
+	"common.main_menu":         "🏠 Главное меню",
+	"menu.main":                "Главное меню",
+	"error.internal":           "Произошла ошибка, попробуйте ещё раз.",
+	"error.not_found":          "Не найдено.",
+	"error.forbidden":          "⛔️ Недостаточно прав",
+	"error.invalid":            "Некорректные данные.",
+	"error.stale_button":       "Кнопка устарела, откройте меню заново.",
+	"error.no_user":            "Ошибка: пользователь не найден, попробуйте /start",
+	"error.invalid_transition": "Это действие недоступно в текущем статусе маршрута",
+	"error.too_often":          "Слишком часто 🙂 Подождите пару секунд и попробуйте снова.",
+	"error.banned":             "⛔️ Доступ к боту ограничен до %s",
+
+	// Кнопки reply-клавиатуры
+	"btn.routes":        "🚶 Маршруты",
+	"btn.search":        "🔍 Поиск",
+	"btn.profile":       "👤 Профиль",
+	"btn.help":          "ℹ️ Помощь",
+	"btn.send_location": "📍 Отправить геопозицию",
+	"btn.main_menu":     "🏠 Главное меню",
+
+	// Прохождение маршрута
+	"run.continue":         "▶️ Продолжить",
+	"run.restart":          "🔁 Начать заново",
+	"run.already_started":  "У вас уже начат маршрут. Что сделать?",
+	"run.no_access":        "Нет доступа или маршрут пуст.",
+	"run.already_finished": "🏁 Маршрут уже завершён.",
+	"run.restart_failed":   "Не получилось начать заново.",
+	"run.upgrade_failed":   "Не получилось обновить маршрут.",
+	"run.upgraded":         "✅ Маршрут обновлён до версии %d",
+	"run.finished_last":    "🏁 Маршрут завершён! Возвращайтесь в профиль, чтобы пройти ещё раз.",
+	"run.first_point":      "Это первая точка маршрута.",
+	"run.finish_failed":    "Ошибка: не удалось завершить маршрут, обратитесь в поддержку",
+	"run.finished":         "🏁 Маршрут завершён! Спасибо за прогулку.",
+	"run.prev":             "◀️ Назад",
+	"run.next":             "▶️ Дальше",
+	"run.finish":           "🏁 Завершить",
+	"run.sample_next":      "▶️ Следующая пробная",
+	"run.sample_buy":       "💰 Купить маршрут",
+	"run.sample_back":      "⬅️ К маршруту",
+	"run.sample_note":      "👀 Пробная точка. Остальные откроются после покупки.",
+	"run.no_samples":       "У маршрута нет пробных точек",
+
+	// Каталог
+	"catalog.cities_error":      "Ошибка при загрузке городов",
+	"catalog.no_cities":         "Пока нет маршрутов ни в одном городе",
+	"catalog.nearby":            "📍 Рядом со мной",
+	"catalog.choose_city":       "Выберите город:",
+	"catalog.routes_error":      "Ошибка при загрузке маршрутов",
+	"catalog.no_routes":         "В городе %s пока нет маршрутов",
+	"catalog.route_item":        "📍 %s (%.1f км)",
+	"catalog.share":             "📤 Поделиться",
+	"catalog.share_city":        "Прогулки по городу %s",
+	"catalog.back_to_cities":    "⬅️ Назад к городам",
+	"catalog.city_routes#one":   "Маршруты в городе %[2]s — %[1]d маршрут:",
+	"catalog.city_routes#few":   "Маршруты в городе %[2]s — %[1]d маршрута:",
+	"catalog.city_routes#many":  "Маршруты в городе %[2]s — %[1]d маршрутов:",
+	"catalog.city_routes#other": "Маршруты в городе %[2]s — %[1]d маршрута:",
+
+	// Карточка маршрута
+	"route.unavailable":   "😔 Этот маршрут сейчас недоступен: его сняли с продажи или скрыли из каталога.",
+	"route.details_error": "Ошибка при загрузке информации о маршруте",
+	"route.buy":           "💰 Купить",
+	"route.sample":        "👀 Пробная точка",
+	"route.gallery":       "🖼 Фото (%d)",
+	"route.no_photos":     "Фотографии маршрута пока недоступны",
+	"card.city":           "Город:",
+	"card.description":    "Описание:",
+	"card.length":         "Протяженность:",
+	"card.duration":       "Время прогулки:",
+	"card.theme":          "Тематика:",
+	"card.price":          "Цена:",
+	"card.status":         "Статус:",
+	"card.km":             "%.1f км",
+	"card.minutes":        "%d мин",
+	"card.rub":            "%.2f руб.",
+	"route.share_text":    "🚶 %s — прогулка по городу %s",
+
+	// Публикация (админы)
+	"status.draft":                  "📝 Черновик",
+	"status.published":              "✅ Опубликован",
+	"status.archived":               "📦 В архиве",
+	"admin.route_id_usage":          "Укажите ID маршрута: /%s <id>",
+	"admin.published":               "✅ Опубликована версия %d маршрута «%s»",
+	"admin.archived":                "📦 Маршрут %d перенесён в архив. Купившие сохраняют доступ.",
+	"admin.restored":                "✅ Маршрут %d снова в каталоге",
+	"admin.shown":                   "👁 Маршрут %d показан в каталоге",
+	"admin.hidden":                  "🙈 Маршрут %d скрыт из каталога",
+	"admin.history_empty":           "История публикации пуста",
+	"admin.history_title":           "🗂 История маршрута %d:\n",
+	"admin.history_version":         " (версия #%d)",
+	"admin.history_user":            ", пользователь %d",
+	"admin.history_reason.replaced": "заменена новой версией",
+	"admin.history_reason.hidden":   "скрыт из каталога",
+	"admin.history_reason.shown":    "показан в каталоге",
+	"admin.preview_header":          "%s · версия %d",
+	"admin.route_unavailable":       "Ошибка: маршрут не найден или недоступен",
+
+	// Режим автора
+	"author.field.title":             "Название",
+	"author.field.city":              "Город",
+	"author.field.description":       "Описание",
+	"author.field.theme":             "Тематика",
+	"author.field.duration_minutes":  "Длительность",
+	"author.field.length_km":         "Протяжённость",
+	"author.field.price":             "Цена",
+	"author.prompt.title":            "Введите название маршрута",
+	"author.prompt.city":             "В каком городе маршрут?",
+	"author.prompt.description":      "Пришлите описание маршрута",
+	"author.prompt.theme":            "Тематика маршрута (например, «Архитектура»)",
+	"author.prompt.duration_minutes": "Сколько минут занимает прогулка? Только число",
+	"author.prompt.length_km":        "Протяжённость в километрах, например 3.5",
+	"author.prompt.price":            "Цена в рублях, например 490",
+	"author.prompt.default":          "Введите значение",
+	"author.cancel_hint":             " (/cancel — отмена)",
+	"author.route_item":              "✏️ %s (%s)",
+	"author.new_route":               "➕ Новый маршрут",
+	"author.menu":                    "🧭 Режим автора\n\nВыберите маршрут для редактирования или создайте новый.",
+	"author.menu_empty":              "🧭 Режим автора\n\nУ вас пока нет маршрутов — создайте первый!",
+	"author.ask_title":               "Как будет называться маршрут? (/cancel — отмена)",
+	"author.editor_header":           "✏️ %s · версия %d (%s)\n\n",
+	"author.editor_meta":             "Город: %s\nТематика: %s\n",
+	"author.editor_numbers":          "Длительность: %d мин · Протяжённость: %.1f км · Цена: %.2f руб.\n",
+	"author.editor_description":      "Описание: %s\n\n",
+	"author.editor_points#one":       "%d точка",
+	"author.editor_points#few":       "%d точки",
+	"author.editor_points#many":      "%d точек",
+	"author.editor_points#other":     "%d точки",
+	"author.editor_submitted":        "\n\n📤 Отправлен на публикацию %s",
+	"author.points_btn":              "📍 Точки (%d)",
+	"author.add_point":               "➕ Точка",
+	"author.preview":                 "👀 Предпросмотр",
+	"author.submit":                  "📤 На публикацию",
+	"author.back_to_routes":          "⬅️ К маршрутам",
+	"author.back_to_route":           "⬅️ К маршруту",
+	"author.ask_location":            "📍 Отправьте геопозицию точки: 📎 → «Геопозиция». (/cancel — отмена)",
+	"author.points_title":            "📍 Точки маршрута:\n",
+	"author.point_sample":            " — 👀 пробная",
+	"author.points_empty":            "\nПока пусто — добавьте первую точку.",
+	"author.points_sample_hint":      "\n\n👀 — открыть точку бесплатно как пробную, 🔒 — снова закрыть.",
+	"author.delete_confirm":          "🗑 Да, удалить",
+	"author.delete_cancel":           "Отмена",
+	"author.delete_ask":              "Удалить точку вместе с привязанными фото и озвучкой?",
+	"author.preview_failed":          "Не получилось начать предпросмотр: добавьте хотя бы одну точку.",
+	"author.preview_intro":           "👀 Предпросмотр: так маршрут увидит покупатель.",
+	"author.submit_incomplete":       "Перед публикацией укажите город, описание и добавьте хотя бы одну точку.",
+	"author.submitted":               "📤 Маршрут отправлен на публикацию. Мы сообщим, когда он появится в каталоге.",
+	"author.admin_submitted":         "📤 На публикацию: «%s» (маршрут %d, версия %d) от %s",
+	"author.admin_publish":           "✅ Опубликовать",
+	"author.created":                 "✅ Маршрут создан. Заполните остальные поля и добавьте точки.",
+	"author.need_location":           "Нужна геопозиция: 📎 → «Геопозиция». (/cancel — отмена)",
+	"author.new_point":               "Новая точка",
+	"author.ask_point_text":          "✍️ Теперь текст точки: первая строка — название, дальше — рассказ.",
+	"author.need_point_text":         "Пришлите текст: первая строка — название, дальше — рассказ.",
+	"author.done":                    "✅ Готово",
+	"author.ask_media":               "📷 Пришлите фото и 🎙 голосовое с рассказом. Когда закончите — нажмите «Готово».",
+	"author.photo_added":             "📷 Фото добавлено",
+	"author.audio_added":             "🎙 Озвучка добавлена",
+	"author.need_media":              "Пришлите фото или голосовое сообщение, либо нажмите «Готово».",
+	"author.media_failed":            "Не удалось сохранить файл, попробуйте ещё раз.",
+	"author.forbidden":               "⛔️ Режим автора доступен только гидам",
+	"author.published_version":       "Эта версия уже опубликована — откройте маршрут заново, чтобы создать черновик",
+	"author.invalid_value":           "Некорректное значение, попробуйте ещё раз (/cancel — отмена)",
+	"author.error":                   "Ошибка, попробуйте ещё раз",
+	"author.user_name":               "пользователь %d",
+
+	// Избранное
+	"fav.add":         "⭐ В избранное",
+	"fav.added":       "🌟 В избранном",
+	"fav.notify_on":   "🔔 Уведомлять",
+	"fav.notify_off":  "🔕 Без уведомлений",
+	"fav.error":       "Ошибка при загрузке избранного",
+	"fav.empty":       "⭐ В избранном пока пусто.\n\nДобавляйте маршруты кнопкой «⭐ В избранное» на карточке — мы сообщим о скидках и обновлениях.",
+	"fav.item":        "⭐ %s (%s)",
+	"fav.title":       "⭐ Избранные маршруты:",
+	"fav.new_version": "🆕 Маршрут «%s» из вашего избранного обновился — вышла версия %d.",
+	"fav.discount":    "🔥 Скидка на маршрут «%s» из вашего избранного: %.0f ₽ вместо %.0f ₽.",
+	"fav.view":        "👀 Посмотреть",
+
+	// Рядом со мной
+	"nearby.ask":         "📍 Отправьте геопозицию — покажу маршруты, которые начинаются рядом с вами.",
+	"nearby.searching":   "Геопозиция получена, ищу маршруты рядом…",
+	"nearby.item":        "📍 %s — %s",
+	"nearby.wider":       "🔭 Искать в радиусе %d км",
+	"nearby.choose_city": "🏙 Выбрать город",
+	"nearby.title":       "📍 Маршруты в радиусе %d км, от ближайших:",
+	"nearby.empty":       "В радиусе %d км маршрутов пока нет.",
+	"nearby.meters":      "%d м",
+	"nearby.km":          "%.1f км",
+
+	// Старт и диплинки
+	"start.welcome":         "Привет 👋 Добро пожаловать в Walki!",
+	"start.promo_not_found": "Промокод не найден",
+	"start.promo_expired":   "Срок действия промокода истёк",
+	"start.promo_failed":    "Не удалось активировать промокод",
+	"start.promo_activated": "🎁 Промокод %s активирован: скидка %d%% на следующую покупку",
+
+	// Инлайн-режим
+	"inline.nothing_found": "Ничего не нашлось — открыть каталог",
+	"inline.description":   "%s · %.1f км · %d мин · %.0f руб.",
+	"inline.open":          "Открыть в боте",
+
+	// Помощь
+	"help.text": "ℹ️ Тут будет помощь",
+
+	// Профиль
+	"profile.my_routes":         "🚶 Мои маршруты",
+	"profile.favorites":         "⭐ Избранное",
+	"profile.invite":            "🤝 Пригласить друга",
+	"profile.invite_text":       "Аудиопрогулки по городам в Walki",
+	"profile.language":          "🌐 Язык: %s",
+	"profile.title":             "👤 Ваш профиль\n\nЗдесь вы можете управлять своими маршрутами и настройки",
+	"profile.routes_error":      "Ошибка при загрузке ваших маршрутов",
+	"profile.no_routes":         "У вас пока нет купленных маршрутов",
+	"profile.route_item":        "📍 %s (%s)",
+	"profile.routes_title":      "🚶 Ваши маршруты:\n\nВыберите маршрут для просмотра деталей:",
+	"profile.access_error":      "Ошибка при проверке доступа к маршруту",
+	"profile.no_access":         "У вас нет доступа к этому маршруту",
+	"profile.access_info_error": "Ошибка при загрузке информации о доступе",
+	"profile.version_error":     "Ошибка при загрузке информации о версии маршрута",
+	"profile.access_until":      "доступен до %s",
+	"profile.access_forever":    "бессрочный доступ",
+	"profile.start_walk":        "🎯 Начать прогулку",
+	"profile.new_version":       "🆕 Доступна новая версия (%d) — обновить? Прогресс перенесётся на ту же точку.",
+	"profile.upgrade":           "🔄 Обновить",
+	"profile.choose_language":   "🌐 Выберите язык бота:",
+	"profile.language_set":      "✅ Язык бота: %s",
+
+	// Названия языков (на самом языке)
+	"lang.ru":      "🇷🇺 Русский",
+	"lang_name.ru": "русский",
+	"lang_name.en": "английский",
+	"lang.en":      "🇬🇧 English",
+
+	// Покупка
+	"purchase.route_error": "Ошибка при получении информации о маршруте",
+	"purchase.order_error": "Ошибка при создании заказа",
+	"purchase.congrats":    "🎉 Поздравляем с покупкой!",
+	"purchase.amount":      "💰 Стоимость: %.2f руб.",
+	"purchase.until":       "📅 Доступен до: %s",
+	"purchase.hint":        "Чтобы начать прогулку, перейдите в раздел \"👤 Профиль\" -> \"Мои маршруты\"",
+	"purchase.promo":       "🎁 Промокод %s: скидка %.2f руб.",
+	"purchase.to_profile":  "👤 Перейти в профиль",
+
+	// Поиск
+	"search.duration.any":    "любое",
+	"search.duration.short":  "до 1 часа",
+	"search.duration.medium": "1–2 часа",
+	"search.duration.long":   "больше 2 часов",
+	"search.length.any":      "любая",
+	"search.length.short":    "до 3 км",
+	"search.length.medium":   "3–7 км",
+	"search.length.long":     "больше 7 км",
+	"search.price.any":       "любая",
+	"search.price.low":       "до 300 руб.",
+	"search.price.medium":    "300–700 руб.",
+	"search.price.high":      "больше 700 руб.",
+	"search.theme.any":       "любая",
+	"search.part.theme":      "тематика: %s",
+	"search.part.duration":   "время: %s",
+	"search.part.length":     "длина: %s",
+	"search.part.price":      "цена: %s",
+	"search.by_filters":      "🎛 Подобрать по фильтрам",
+	"search.ask":             "🔍 Что ищем? Напишите слово из названия, описания или названия точки — например, «набережная».\n\nОтмена — /cancel",
+	"search.need_text":       "Напишите запрос текстом или нажмите /cancel",
+	"search.title":           "🔍 Поиск",
+	"search.query":           ": «%s»",
+	"search.filters":         "\nФильтры: %s",
+	"search.nothing":         "\n\nНичего не нашлось. Попробуйте другой запрос или ослабьте фильтры.",
+	"search.first_n":         "\n\nПервые %d маршрутов — уточните запрос, чтобы сузить выбор:",
+	"search.found":           "\n\nНайденные маршруты:",
+	"search.item":            "📍 %s, %s (%.1f км)",
+	"search.filter.theme":    "🏷 Тематика",
+	"search.filter.duration": "⏱ Время",
+	"search.filter.length":   "📏 Длина",
+	"search.filter.price":    "💰 Цена",
+	"search.reset":           "✖️ Сбросить фильтры",
+	"search.pick_theme":      "🏷 Выберите тематику:",
+	"search.pick_duration":   "⏱ Сколько времени на прогулку?",
+	"search.pick_length":     "📏 Какая длина маршрута?",
+	"search.pick_price":      "💰 Какая цена?",
+	"search.unknown_filter":  "Неизвестный фильтр",
+	"search.back":            "⬅️ Назад к результатам",
+
+	// Модерация
+	"mod.ban_usage":   "Использование: /ban <telegram_id> [срок: 30m, 12h, 7d] [причина]",
+	"mod.bad_id":      "Telegram ID должен быть числом",
+	"mod.bad_ban":     "Некорректный пользователь или срок (не больше года)",
+	"mod.banned":      "⛔️ Пользователь %d заблокирован до %s",
+	"mod.unban_usage": "Использование: /unban <telegram_id>",
+	"mod.not_banned":  "Пользователь %d не был заблокирован",
+	"mod.unbanned":    "✅ Пользователь %d разблокирован",
+	"mod.routes":      "Маршруты бота:\n",
+
+	// Переводы маршрута (режим автора)
+	"author.translation_btn":       "🌐 Перевод: %s",
+	"author.tr_header":             "🌐 Перевод маршрута «%s»: %s\n\n",
+	"author.tr_title":              "Название: %s\n",
+	"author.tr_description":        "Описание: %s\n\n",
+	"author.tr_points":             "Точки:",
+	"author.tr_point_item":         "\n%d. %s → %s",
+	"author.tr_hint":               "\n\nБез перевода показывается оригинал. ✏️ — текст точки, после него можно перевести подсказку «как дойти» и прислать озвучку на этом языке.",
+	"author.tr_point_btn":          "✏️ %d",
+	"author.tr_prompt.title":       "Название маршрута на языке «%s»",
+	"author.tr_prompt.description": "Описание маршрута на языке «%s»",
+	"author.tr_ask_point_text":     "✍️ Точка «%s» на языке «%s»: первая строка — название, дальше — рассказ. (/cancel — отмена)",
+	"author.tr_ask_arrival":        "🧭 Как дойти до точки на этом языке? Пришлите подсказку или нажмите «Пропустить» — тогда покажется оригинальная.",
+	"author.tr_skip":               "⏭ Пропустить",
+	"author.need_arrival":          "Пришлите подсказку текстом или нажмите «Пропустить».",
+	"author.tr_ask_audio":          "🎙 Пришлите озвучку на этом языке или нажмите «Готово» — тогда прозвучит оригинальная.",
+	"author.need_audio":            "Пришлите голосовое или аудио, либо нажмите «Готово».",
+
+	// Уведомления
+	"profile.notifications":      "🔔 Уведомления",
+	"notify.title":               "🔔 Уведомления\n\nВыберите, о чём напоминать. В тихие часы сообщения не приходят — они придут позже. Время — по часовому поясу %s.",
+	"notify.on":                  "✅ %s",
+	"notify.off":                 "▫️ %s",
+	"notify.kind.access_expiry":  "Окончание доступа",
+	"notify.kind.progress_nudge": "Незаконченные прогулки",
+	"notify.kind.review_request": "Просьбы об оценке",
+	"notify.quiet":               "🌙 Тихие часы: %02d:00–%02d:00",
+	"notify.quiet_off":           "🌙 Тихие часы: выкл.",
+	"notify.settings_btn":        "🔔 Настроить уведомления",
+	"notify.expiry":              "⏳ Доступ к маршруту «%s» закончится %s. Успейте прогуляться!",
+	"notify.nudge":               "🚶 Прогулка «%s» не закончена. Продолжим с того же места?",
+	"notify.review":              "🏁 Вы прошли маршрут «%s»! Оцените его — это поможет другим путешественникам и автору.",
+	"notify.star":                "%d ⭐",
+	"notify.review_thanks":       "Спасибо за оценку! %s",
+}
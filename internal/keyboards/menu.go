@@ -2,35 +2,60 @@ package keyboards
 
 import (
 	"walki/internal/constants"
+	"walki/internal/i18n"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
-// ButtonTexts содержит mapping констант на текст кнопок
-var ButtonTexts = map[string]string{
-	constants.BtnRoutes:  "🚶 Маршруты",
-	constants.BtnProfile: "👤 Профиль",
-	constants.BtnHelp:    "ℹ️ Помощь",
+// Text — подпись кнопки на языке l (ключи каталога "btn.<константа>")
+func Text(l i18n.Localizer, btn string) string {
+	return l.T("btn." + btn)
+}
+
+// Variants — подписи кнопки на всех языках: нажатие распознаётся
+// независимо от того, на каком языке пользователю показали клавиатуру
+func Variants(btn string) []string {
+	return i18n.All("btn." + btn)
 }
 
 // MainMenu создает клавиатуру главного меню
-func MainMenu() tgbotapi.ReplyKeyboardMarkup {
+func MainMenu(l i18n.Localizer) tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(ButtonTexts[constants.BtnRoutes]),
-			tgbotapi.NewKeyboardButton(ButtonTexts[constants.BtnProfile]),
+			tgbotapi.NewKeyboardButton(Text(l, constants.BtnRoutes)),
+			tgbotapi.NewKeyboardButton(Text(l, constants.BtnSearch)),
+		),
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButton(Text(l, constants.BtnProfile)),
+			tgbotapi.NewKeyboardButton(Text(l, constants.BtnHelp)),
+		),
+	)
+}
+
+// LocationRequest — запрос геопозиции для поиска маршрутов рядом
+func LocationRequest(l i18n.Localizer) tgbotapi.ReplyKeyboardMarkup {
+	kb := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(
+			tgbotapi.NewKeyboardButtonLocation(Text(l, constants.BtnSendLocation)),
 		),
 		tgbotapi.NewKeyboardButtonRow(
-			tgbotapi.NewKeyboardButton(ButtonTexts[constants.BtnHelp]),
+			tgbotapi.NewKeyboardButton(Text(l, constants.BtnMainMenu)),
 		),
 	)
+	kb.OneTimeKeyboard = true
+	return kb
 }
 
-// MatchButton возвращает ключ кнопки по ее тексту
+// MatchButton возвращает ключ кнопки по ее тексту на любом языке
 func MatchButton(text string) string {
-	for key, val := range ButtonTexts {
-		if val == text {
-			return key
+	for _, btn := range []string{
+		constants.BtnRoutes, constants.BtnSearch, constants.BtnProfile, constants.BtnHelp,
+		constants.BtnSendLocation, constants.BtnMainMenu,
+	} {
+		for _, v := range Variants(btn) {
+			if v == text {
+				return btn
+			}
 		}
 	}
 	return ""
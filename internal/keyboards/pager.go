@@ -0,0 +1,49 @@
+package keyboards
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// CallbackNoop — кнопка без действия (номер страницы); обработчик только гасит «часики»
+const CallbackNoop = "noop"
+
+// Pages — число страниц для total элементов (не меньше одной)
+func Pages(total, pageSize int) int {
+	if total <= 0 || pageSize <= 0 {
+		return 1
+	}
+	return (total + pageSize - 1) / pageSize
+}
+
+// PageNav — ряд «⬅️ 2 / 5 ➡️» для постраничных списков; nil, если страница одна.
+// data строит callback_data перехода на страницу (нумерация с нуля).
+func PageNav(page, pageSize, total int, data func(page int) string) []tgbotapi.InlineKeyboardButton {
+	pages := Pages(total, pageSize)
+	if pages <= 1 {
+		return nil
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("⬅️", data(page-1)))
+	}
+	row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d / %d", page+1, pages), CallbackNoop))
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("➡️", data(page+1)))
+	}
+	return row
+}
+
+// Paginated — кнопки элементов страницы по одной в ряд, навигация и хвостовые ряды (назад, меню)
+func Paginated(items []tgbotapi.InlineKeyboardButton, nav []tgbotapi.InlineKeyboardButton, tail ...[]tgbotapi.InlineKeyboardButton) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(items)+1+len(tail))
+	for _, btn := range items {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, nav)
+	}
+	rows = append(rows, tail...)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ChatState — состояние многошагового диалога в чате (FSM).
+// ExpiresAt == nil — без таймаута.
+type ChatState struct {
+	ChatID    int64
+	Name      string
+	Data      map[string]string
+	ExpiresAt *time.Time
+	UpdatedAt time.Time
+}
+
+func (s *ChatState) Expired(now time.Time) bool {
+	return s != nil && s.ExpiresAt != nil && now.After(*s.ExpiresAt)
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// Виды запланированных уведомлений (scheduled_jobs.kind)
+const (
+	JobAccessExpiry  = "access_expiry"  // ref — заказ: доступ скоро закончится
+	JobProgressNudge = "progress_nudge" // ref — прогресс: прогулка брошена на середине
+	JobReviewRequest = "review_request" // ref — прогресс: маршрут пройден, просим оценку
+)
+
+// Job — запланированное уведомление, занятое экземпляром бота
+type Job struct {
+	ID       int64
+	Kind     string
+	RefID    int
+	UserID   int
+	RunAt    time.Time
+	Attempts int // с учётом текущей
+}
+
+// NotificationSettings — что пользователь разрешил присылать и когда
+type NotificationSettings struct {
+	UserID    int
+	Reminders bool
+	Nudges    bool
+	Reviews   bool
+	// Тихие часы [QuietFrom, QuietTo) по часовому поясу бота; nil — выключены
+	QuietFrom *int
+	QuietTo   *int
+}
+
+// DefaultNotificationSettings — настройки пользователя, который их не менял
+func DefaultNotificationSettings(userID int) *NotificationSettings {
+	from, to := 22, 9
+	return &NotificationSettings{UserID: userID, Reminders: true, Nudges: true, Reviews: true, QuietFrom: &from, QuietTo: &to}
+}
+
+// Enabled — разрешён ли вид уведомлений
+func (s *NotificationSettings) Enabled(kind string) bool {
+	switch kind {
+	case JobAccessExpiry:
+		return s.Reminders
+	case JobProgressNudge:
+		return s.Nudges
+	case JobReviewRequest:
+		return s.Reviews
+	}
+	return false
+}
+
+// Quiet — попадает ли час (0–23) в тихие часы; интервал может переходить через полночь
+func (s *NotificationSettings) Quiet(hour int) bool {
+	if s.QuietFrom == nil || s.QuietTo == nil || *s.QuietFrom == *s.QuietTo {
+		return false
+	}
+	from, to := *s.QuietFrom, *s.QuietTo
+	if from < to {
+		return hour >= from && hour < to
+	}
+	return hour >= from || hour < to
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PromoCode — скидка рекламной кампании
+type PromoCode struct {
+	Code            string     `json:"code"`
+	DiscountPercent int        `json:"discount_percent"`
+	ValidUntil      *time.Time `json:"valid_until,omitempty"`
+	MaxUses         *int       `json:"max_uses,omitempty"`
+	UsedCount       int        `json:"used_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Usable — промокод не истёк и лимит использований не исчерпан
+func (p *PromoCode) Usable(now time.Time) bool {
+	if p == nil {
+		return false
+	}
+	if p.ValidUntil != nil && !now.Before(*p.ValidUntil) {
+		return false
+	}
+	return p.MaxUses == nil || p.UsedCount < *p.MaxUses
+}
+
+// Apply возвращает цену со скидкой, округлённую до копеек
+func (p *PromoCode) Apply(price float64) float64 {
+	discounted := price * float64(100-p.DiscountPercent) / 100
+	return math.Round(discounted*100) / 100
+}
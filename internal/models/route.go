@@ -2,6 +2,21 @@ package models
 
 import "time"
 
+// Статусы маршрута и версии (enum route_status)
+const (
+	RouteStatusDraft     = "draft"
+	RouteStatusPublished = "published"
+	RouteStatusArchived  = "archived"
+)
+
+// Причины в журнале публикации (route_status_history.comment) хранятся кодом,
+// текст подставляется на языке читателя (admin.history_reason.<код>)
+const (
+	HistoryReasonReplaced = "replaced" // версия заменена новой
+	HistoryReasonHidden   = "hidden"   // маршрут скрыт из каталога
+	HistoryReasonShown    = "shown"    // маршрут показан в каталоге
+)
+
 type RouteVersion struct {
 	ID              int
 	RouteID         int
@@ -13,8 +28,11 @@ type RouteVersion struct {
 	Theme           string
 	Price           float64
 	City            string
+	Status          string
+	PublishedAt     *time.Time
+	SubmittedAt     *time.Time
 	CreatedAt       time.Time
-	CoverImageURL   string
+	CoverMediaID    int64 // изображение-обложка из media; 0 — без обложки
 }
 
 type Route struct {
@@ -26,3 +44,15 @@ type Route struct {
 	UpdatedAt time.Time
 	Versions  []RouteVersion // Для полной информации о маршруте
 }
+
+// RouteStatusChange — запись журнала публикации
+type RouteStatusChange struct {
+	ID         int
+	RouteID    int
+	VersionID  *int
+	FromStatus *string
+	ToStatus   string
+	ChangedBy  *int
+	ChangedAt  time.Time
+	Comment    string
+}
@@ -0,0 +1,20 @@
+package models
+
+// RouteFilter — параметры поиска по каталогу; нулевые значения не ограничивают выборку.
+// Нижние границы строгие, верхние — включительно: соседние диапазоны не пересекаются.
+type RouteFilter struct {
+	Query       string // свободный текст: название, город, тематика, описание, точки
+	Theme       string
+	MinDuration int // минуты
+	MaxDuration int
+	MinLength   float64 // км
+	MaxLength   float64
+	MinPrice    float64 // руб.
+	MaxPrice    float64
+}
+
+// HasFilters — задан хотя бы один фильтр, кроме текста
+func (f RouteFilter) HasFilters() bool {
+	return f.Theme != "" || f.MinDuration > 0 || f.MaxDuration > 0 ||
+		f.MinLength > 0 || f.MaxLength > 0 || f.MinPrice > 0 || f.MaxPrice > 0
+}
@@ -10,5 +10,8 @@ type RoutePoint struct {
 	Description string
 	Lat         float64
 	Lon         float64
+	IsPreview   bool // пробная точка — показывается до покупки
 	CreatedAt   time.Time
+
+	ArrivalInstructions string // как дойти до точки; пусто — без подсказки
 }
@@ -0,0 +1,19 @@
+package models
+
+// VersionTranslation — перевод текстов версии маршрута на язык Language.
+// Пустое поле — перевода нет, показывается оригинал.
+type VersionTranslation struct {
+	VersionID   int
+	Language    string
+	Title       string
+	Description string
+}
+
+// PointTranslation — перевод текстов точки маршрута
+type PointTranslation struct {
+	PointID             int
+	Language            string
+	Title               string
+	Description         string
+	ArrivalInstructions string
+}
@@ -2,11 +2,27 @@ package models
 
 import "time"
 
+// Роли пользователей (enum user_role)
+const (
+	RoleUser  = "user"
+	RoleGuide = "guide"
+	RoleAdmin = "admin"
+)
+
 type User struct {
-	ID         int       `json:"id"`
-	TelegramID int64     `json:"telegram_id"`
-	Username   string    `json:"username"`
-	FullName   string    `json:"full_name"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         int    `json:"id"`
+	TelegramID int64  `json:"telegram_id"`
+	Username   string `json:"username"`
+	FullName   string `json:"full_name"`
+	Role       string `json:"role"`
+	Language   string `json:"language"` // выбран в профиле; "" — по клиенту Telegram
+	// ReferralCode — код для приглашений (ref_<код>), вместо users.id
+	ReferralCode string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
+
+func (u *User) IsAdmin() bool { return u != nil && u.Role == RoleAdmin }
+
+// IsAuthor — может создавать и редактировать маршруты
+func (u *User) IsAuthor() bool { return u != nil && (u.Role == RoleGuide || u.Role == RoleAdmin) }
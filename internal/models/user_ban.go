@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UserBan — временная блокировка по Telegram ID (пользователь может ещё не быть в users)
+type UserBan struct {
+	TelegramID int64
+	Until      time.Time
+	Reason     string
+	BannedBy   *int
+	CreatedAt  time.Time
+}
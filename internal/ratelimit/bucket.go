@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Bucket — классический token bucket: rate токенов в секунду, не больше burst в запасе
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(rate float64, burst int) *Bucket {
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Every — скорость «одно событие за d»
+func Every(d time.Duration) float64 { return float64(time.Second) / float64(d) }
+
+// Allow забирает токен, если он есть
+func (b *Bucket) Allow() bool { return b.reserve(false) == 0 }
+
+// Wait ждёт токен; при отмене ctx зарезервированный токен возвращается
+func (b *Bucket) Wait(ctx context.Context) error {
+	wait := b.reserve(true)
+	if wait == 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// reserve возвращает, сколько ждать токена; force — занять токен в долг
+func (b *Bucket) reserve(force bool) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if !force {
+		if wait == 0 {
+			wait = time.Nanosecond
+		}
+		return wait
+	}
+	b.tokens--
+	return wait
+}
+
+// full — бакет восстановился полностью и его можно забыть
+func (b *Bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens+now.Sub(b.last).Seconds()*b.rate >= b.burst
+}
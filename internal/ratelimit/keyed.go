@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Keyed — набор бакетов по ключу (чат, пользователь).
+// Полностью восстановившиеся бакеты периодически удаляются, чтобы map не росла.
+type Keyed[K comparable] struct {
+	mu        sync.Mutex
+	buckets   map[K]*Bucket
+	newBucket func(K) *Bucket
+	lastGC    time.Time
+}
+
+const gcInterval = time.Minute
+
+// NewKeyed: newBucket задаёт параметры бакета для ключа (например, свои лимиты для групп)
+func NewKeyed[K comparable](newBucket func(K) *Bucket) *Keyed[K] {
+	return &Keyed[K]{buckets: map[K]*Bucket{}, newBucket: newBucket, lastGC: time.Now()}
+}
+
+func (k *Keyed[K]) Allow(key K) bool { return k.get(key).Allow() }
+
+func (k *Keyed[K]) Wait(ctx context.Context, key K) error { return k.get(key).Wait(ctx) }
+
+func (k *Keyed[K]) get(key K) *Bucket {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := time.Now()
+	if now.Sub(k.lastGC) > gcInterval {
+		for key, b := range k.buckets {
+			if b.full(now) {
+				delete(k.buckets, key)
+			}
+		}
+		k.lastGC = now
+	}
+	b, ok := k.buckets[key]
+	if !ok {
+		b = k.newBucket(key)
+		k.buckets[key] = b
+	}
+	return b
+}
@@ -0,0 +1,128 @@
+// Package render — тексты экранов по шаблонам в HTML-разметке Telegram.
+//
+// Шаблоны (templates/*.tmpl) исполняются html/template: значения из данных
+// экранируются автоматически, поэтому «_», «*» или «<» в названии маршрута
+// не ломают сообщение. Подписи из каталога берутся функцией t ({{t "ключ" арг...}}).
+//
+// Длина считается так же, как у Telegram — видимый текст без тегов в UTF-16.
+// Если текст не влезает в лимит, укорачиваются «гибкие» поля экрана
+// (описание, подсказка), а не обрезается готовая разметка.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"html"
+	"html/template"
+	"log"
+	"regexp"
+	"unicode/utf16"
+
+	"walki/internal/i18n"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ParseMode — режим разметки всех сообщений из шаблонов
+const ParseMode = tgbotapi.ModeHTML
+
+// Лимиты Telegram
+const (
+	TextLimit    = 4096 // текст сообщения
+	CaptionLimit = 1024 // подпись к фото
+)
+
+//go:embed templates/*.tmpl
+var files embed.FS
+
+// base — разобранные шаблоны; t подставляется для языка при каждом рендере
+var base = template.Must(template.New("").
+	Funcs(template.FuncMap{"t": func(string, ...any) string { return "" }}).
+	ParseFS(files, "templates/*.tmpl"))
+
+// execute — шаблон name с подписями на языке l
+func execute(l i18n.Localizer, name string, data any) (string, error) {
+	tpl, err := base.Clone()
+	if err != nil {
+		return "", err
+	}
+	tpl.Funcs(template.FuncMap{"t": l.T})
+	var buf bytes.Buffer
+	if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// fit рендерит шаблон и укладывает его в limit, укорачивая flex по порядку
+// (сначала первое поле). Ничего не помогло — простой текст без разметки.
+func fit(l i18n.Localizer, name string, data any, limit int, flex ...*string) string {
+	for {
+		out, err := execute(l, name, data)
+		if err != nil {
+			log.Printf("render %s: %v", name, err)
+			return ""
+		}
+		excess := Len(out) - limit
+		if excess <= 0 {
+			return out
+		}
+		if !shrink(flex, excess) {
+			return html.EscapeString(cut(visible(out), limit))
+		}
+	}
+}
+
+// shrink укорачивает первое непустое поле на excess символов
+func shrink(flex []*string, excess int) bool {
+	for _, f := range flex {
+		n := len([]rune(*f))
+		if n == 0 {
+			continue
+		}
+		if n-excess < 2 {
+			*f = ""
+		} else {
+			*f = truncate(*f, n-excess)
+		}
+		return true
+	}
+	return false
+}
+
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// visible — текст, который увидит пользователь
+func visible(s string) string {
+	return html.UnescapeString(tags.ReplaceAllString(s, ""))
+}
+
+// Len — длина сообщения в разметке так, как её считает Telegram
+func Len(s string) int {
+	n := 0
+	for _, r := range visible(s) {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// cut — не больше limit единиц UTF-16, с многоточием при обрезке
+func cut(s string, limit int) string {
+	n := 0
+	for i, r := range s {
+		n += utf16.RuneLen(r)
+		if n > limit-1 {
+			return s[:i] + "…"
+		}
+	}
+	return s
+}
+
+// truncate — не больше n символов, с многоточием при обрезке
+func truncate(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) <= n {
+		return s
+	}
+	return string(rs[:n-1]) + "…"
+}
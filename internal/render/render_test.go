@@ -0,0 +1,123 @@
+package render
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"walki/internal/i18n"
+	"walki/internal/models"
+)
+
+// go test ./internal/render -update — перезаписать testdata/*.golden
+var update = flag.Bool("update", false, "rewrite golden files")
+
+// special — символы, которые ломали Markdown-разметку и должны экранироваться в HTML
+const special = "Улицы _старого_ *города* <центр> & порт"
+
+func version(title, description string) *models.RouteVersion {
+	return &models.RouteVersion{
+		VersionNumber:   3,
+		Title:           title,
+		City:            "Казань",
+		Description:     description,
+		LengthKm:        4.5,
+		DurationMinutes: 90,
+		Theme:           "История",
+		Price:           490,
+	}
+}
+
+func TestScreens(t *testing.T) {
+	l := i18n.For(i18n.RU)
+	long := strings.Repeat("Очень длинное описание маршрута. ", 200)
+
+	tests := []struct {
+		name   string
+		limit  int
+		render func() string
+	}{
+		{"route_card", CaptionLimit, func() string {
+			return RouteCard(l, version(special, "Прогулка по <старому> центру & набережной"), CaptionLimit)
+		}},
+		{"route_card_caption_limit", CaptionLimit, func() string {
+			return RouteCard(l, version(special, long), CaptionLimit)
+		}},
+		{"route_card_text_limit", TextLimit, func() string {
+			return RouteCard(l, version(special, long+long), TextLimit)
+		}},
+		{"purchase_done", TextLimit, func() string {
+			return PurchaseDone(l, Purchase{Title: special, Amount: 441, AccessUntil: "01.11.2026", PromoCode: "WALK_10", Discount: 10})
+		}},
+		{"purchase_done_text_limit", TextLimit, func() string {
+			return PurchaseDone(l, Purchase{Title: strings.Repeat("Маршрут & <город> ", 300), Amount: 490, AccessUntil: "01.11.2026"})
+		}},
+		{"purchased_route", TextLimit, func() string {
+			return PurchasedRouteCard(l, PurchasedRoute{Version: version(special, "Описание"), Access: "доступен до 01.11.2026", NewVersion: 4})
+		}},
+		{"point_caption", CaptionLimit, func() string {
+			return PointCaption(l, Point{
+				Title: special, Arrival: "Через арку <слева> & вверх", Description: "Здесь стоял *первый* _дом_",
+				Lat: 55.796127, Lon: 49.106405, Sample: true,
+			}, CaptionLimit)
+		}},
+		{"point_caption_limit", CaptionLimit, func() string {
+			return PointCaption(l, Point{
+				Title: special, Arrival: strings.Repeat("налево, ", 50), Description: long,
+				Lat: 55.796127, Lon: 49.106405,
+			}, CaptionLimit)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.render()
+			if n := Len(got); n > tt.limit {
+				t.Errorf("length %d exceeds limit %d", n, tt.limit)
+			}
+			if strings.Contains(got, "<центр>") || strings.Contains(got, "<город>") {
+				t.Errorf("user text is not escaped:\n%s", got)
+			}
+			golden(t, tt.name, got)
+		})
+	}
+}
+
+func golden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func TestLen(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"<b>abc</b>", 3},
+		{"a &amp; b", 5},
+		{"&lt;центр&gt;", 7},
+		{"🚶", 2}, // вне BMP — две единицы UTF-16
+	}
+	for _, tt := range tests {
+		if got := Len(tt.in); got != tt.want {
+			t.Errorf("Len(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
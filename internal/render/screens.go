@@ -0,0 +1,101 @@
+package render
+
+import (
+	"net/url"
+	"strconv"
+
+	"walki/internal/i18n"
+	"walki/internal/models"
+)
+
+// routeCardView — карточка маршрута (каталог, предпросмотр, inline)
+type routeCardView struct {
+	Title           string
+	City            string
+	Description     string
+	LengthKm        float64
+	DurationMinutes int
+	Theme           string
+	Price           float64
+}
+
+func newRouteCard(v *models.RouteVersion) *routeCardView {
+	return &routeCardView{
+		Title: v.Title, City: v.City, Description: v.Description,
+		LengthKm: v.LengthKm, DurationMinutes: v.DurationMinutes, Theme: v.Theme, Price: v.Price,
+	}
+}
+
+// RouteCard — карточка маршрута; limit — CaptionLimit для карточки с обложкой
+func RouteCard(l i18n.Localizer, v *models.RouteVersion, limit int) string {
+	card := newRouteCard(v)
+	return fit(l, "route_card", card, limit, &card.Description)
+}
+
+// RoutePreview — карточка версии для автора или администратора со статусом
+func RoutePreview(l i18n.Localizer, status string, v *models.RouteVersion) string {
+	card := newRouteCard(v)
+	data := struct {
+		Status        string
+		VersionNumber int
+		Card          *routeCardView
+	}{status, v.VersionNumber, card}
+	return fit(l, "route_preview", data, TextLimit, &card.Description)
+}
+
+// Purchase — подтверждение покупки
+type Purchase struct {
+	Title       string
+	Amount      float64
+	AccessUntil string
+	PromoCode   string // пусто — без промокода
+	Discount    float64
+}
+
+// PurchaseDone — сообщение об успешной покупке
+func PurchaseDone(l i18n.Localizer, p Purchase) string {
+	return fit(l, "purchase_done", &p, TextLimit, &p.Title)
+}
+
+// PurchasedRoute — купленный маршрут в профиле
+type PurchasedRoute struct {
+	Version    *models.RouteVersion
+	Access     string // «доступен до …» или «бессрочный доступ»
+	NewVersion int    // номер новой версии, 0 — обновления нет
+}
+
+// PurchasedRouteCard — карточка купленного маршрута
+func PurchasedRouteCard(l i18n.Localizer, p PurchasedRoute) string {
+	card := newRouteCard(p.Version)
+	data := struct {
+		*routeCardView
+		Access     string
+		NewVersion int
+	}{card, p.Access, p.NewVersion}
+	return fit(l, "purchased_route", data, TextLimit, &card.Description)
+}
+
+// Point — точка маршрута при прохождении или в пробном режиме
+type Point struct {
+	Title       string
+	Arrival     string // как дойти; пусто — без подсказки
+	Description string
+	Lat, Lon    float64
+	Sample      bool // пробная точка: приписка о покупке
+}
+
+// PointCaption — подпись точки; limit — CaptionLimit, если точка уходит с фото.
+// Первым укорачивается описание, затем подсказка «как дойти».
+func PointCaption(l i18n.Localizer, p Point, limit int) string {
+	p.Arrival = truncate(p.Arrival, 200)
+	data := struct {
+		Point
+		MapsURL string
+	}{p, mapsURL(p.Lat, p.Lon)}
+	return fit(l, "point_caption", &data, limit, &data.Description, &data.Arrival)
+}
+
+func mapsURL(lat, lon float64) string {
+	q := strconv.FormatFloat(lat, 'f', 6, 64) + "," + strconv.FormatFloat(lon, 'f', 6, 64)
+	return "https://maps.google.com/?q=" + url.QueryEscape(q)
+}
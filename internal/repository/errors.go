@@ -0,0 +1,12 @@
+package repository
+
+import "errors"
+
+// ErrPromoUnavailable — промокод истёк или исчерпан к моменту оформления заказа
+var ErrPromoUnavailable = errors.New("promo code is no longer available")
+
+// ErrStatusChanged — статус маршрута или версии уже изменён параллельным запросом
+var ErrStatusChanged = errors.New("route status has already changed")
+
+// ErrRouteUnavailable — маршрута нет в каталоге: удалён, скрыт или в архиве
+var ErrRouteUnavailable = errors.New("route is not available in the catalog")
@@ -8,40 +8,138 @@ import (
 )
 
 type RouteRepository interface {
-	Cities(ctx context.Context) ([]string, error)
-	ByCity(ctx context.Context, city string) ([]models.RouteVersion, error)
+	Cities(ctx context.Context, limit, offset int) (cities []string, total int, err error)
+	ByCity(ctx context.Context, city string, limit, offset int) (routes []models.RouteVersion, total int, err error)
 	Details(ctx context.Context, routeID int) (*models.RouteVersion, error)
 	VersionByID(ctx context.Context, versionID int) (*models.RouteVersion, error)
+	ActiveVersion(ctx context.Context, routeID int) (*models.RouteVersion, error)
+	LatestVersion(ctx context.Context, routeID int) (*models.RouteVersion, error)
+	RouteByID(ctx context.Context, routeID int) (*models.Route, error)
+	Search(ctx context.Context, f models.RouteFilter, limit, offset int) ([]models.RouteVersion, error)
+	Themes(ctx context.Context) ([]string, error)
+	GalleryMediaIDs(ctx context.Context, versionID int) ([]int64, error)
+	Nearby(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]domain.NearbyRoute, error)
+}
+
+type PublicationRepository interface {
+	PublishVersion(ctx context.Context, versionID, actorID int) error
+	SetRouteStatus(ctx context.Context, routeID int, from, to string, actorID int, comment string) error
+	SetVisible(ctx context.Context, routeID int, visible bool, actorID int) error
+	History(ctx context.Context, routeID, limit int) ([]models.RouteStatusChange, error)
 }
 
 type OrderRepository interface {
-	Create(ctx context.Context, userID, routeID int, amount float64) (versionID int, accessExpiry *time.Time, err error)
+	// Create оформляет заказ; непустой promoCode списывается в той же транзакции
+	Create(ctx context.Context, userID, routeID int, amount float64, promoCode string) (versionID int, accessExpiry *time.Time, err error)
 	ListByUser(ctx context.Context, userID int) ([]domain.OrderSummary, error)
+	ListByUserPage(ctx context.Context, userID, limit, offset int) (orders []domain.OrderSummary, total int, err error)
 	UserHasAccess(ctx context.Context, userID, routeID int) (bool, error)
+	PinnedVersion(ctx context.Context, userID, routeID int) (versionID int, ok bool, err error)
 }
 
 type UserRepository interface {
-	Upsert(ctx context.Context, u *models.User) error
+	// Upsert заполняет u.ID и u.ReferralCode; created — пользователь появился впервые
+	Upsert(ctx context.Context, u *models.User) (created bool, err error)
+	SetReferrer(ctx context.Context, userID int, referralCode string) (bool, error)
+	SetLanguage(ctx context.Context, userID int, lang string) error
 	ByTelegramID(ctx context.Context, tgID int64) (*models.User, error)
+	ByRole(ctx context.Context, role string) ([]models.User, error)
 }
 
 type RouteRunRepository interface {
 	FirstPoint(ctx context.Context, versionID int) (*models.RoutePoint, error)
+	Points(ctx context.Context, versionID int) ([]models.RoutePoint, error)
+	PreviewPoints(ctx context.Context, versionID int) ([]models.RoutePoint, error)
 	PointByIndex(ctx context.Context, versionID, idx int) (*models.RoutePoint, error)
 	NextIndex(ctx context.Context, versionID, after int) (int, bool, error)
 	PrevIndex(ctx context.Context, versionID, before int) (int, bool, error)
-	PointMediaIDs(ctx context.Context, pointID int) (photoIDs []int64, audioIDs []int64, err error)
+	PointMediaIDs(ctx context.Context, pointID int, lang string) (photoIDs []int64, audioIDs []int64, err error)
 	UpsertProgress(ctx context.Context, userID, routeID, versionID int, idx int) error
+	// GetProgress: (nil, nil) — прогресса по версии нет
 	GetProgress(ctx context.Context, userID, versionID int) (*models.RouteProgress, error)
+	DraftProgress(ctx context.Context, userID, routeID int) (*models.RouteProgress, error)
 	Finish(ctx context.Context, userID, versionID int) error
 	UpdateMessageIDs(ctx context.Context, userID, versionID int, contentMsgID, voiceMsgID *int) error
+	// UpgradeWalk переводит прогулку на новую версию; idx == nil — прогресса нет
+	UpgradeWalk(ctx context.Context, userID, routeID, fromVersionID, toVersionID int, idx *int) error
 }
 
 type MediaRepository interface {
 	GetByID(ctx context.Context, id int64) (*models.Media, error)
+	Create(ctx context.Context, m *models.Media) (int64, error)
 
 	UpdateStorage(ctx context.Context, mediaID int64, bucket, key, mimeType string, sizeBytes int64) error
 
 	GetTelegramFileID(ctx context.Context, mediaID int64) (fileID string, ok bool, err error)
 	UpsertTelegramFileID(ctx context.Context, mediaID int64, fileID, contentType string, chatID *int64) error
 }
+
+type AuthoringRepository interface {
+	CreateRoute(ctx context.Context, authorID int, title string) (routeID, versionID int, err error)
+	RoutesByAuthor(ctx context.Context, authorID int) ([]models.RouteVersion, error)
+	EnsureDraftVersion(ctx context.Context, routeID int) (versionID int, err error)
+	UpdateVersionField(ctx context.Context, versionID int, field string, value any) error
+	AddPoint(ctx context.Context, versionID int, title string, lat, lon float64) (*models.RoutePoint, error)
+	PointByID(ctx context.Context, pointID int) (*models.RoutePoint, error)
+	UpdatePointText(ctx context.Context, pointID int, title, description string) error
+	SetPointPreview(ctx context.Context, pointID int, preview bool) error
+	// lang — язык озвучки; "" — оригинал
+	AttachPointMedia(ctx context.Context, pointID int, mediaID int64, lang string) error
+	DeletePoint(ctx context.Context, pointID int) error
+	MovePoint(ctx context.Context, pointID, delta int) (moved bool, err error)
+	SubmitVersion(ctx context.Context, versionID, authorID int) error
+}
+
+type ChatStateRepository interface {
+	Get(ctx context.Context, chatID int64) (*models.ChatState, error)
+	Set(ctx context.Context, s *models.ChatState) error
+	Delete(ctx context.Context, chatID int64) error
+}
+
+type BanRepository interface {
+	Ban(ctx context.Context, b *models.UserBan) error
+	Unban(ctx context.Context, telegramID int64) (bool, error)
+	Active(ctx context.Context, telegramID int64) (*models.UserBan, error)
+}
+
+type PromoRepository interface {
+	ByCode(ctx context.Context, code string) (*models.PromoCode, error)
+	Activate(ctx context.Context, userID int, code string) error
+	Pending(ctx context.Context, userID int) (*models.PromoCode, error)
+}
+
+type FavoriteRepository interface {
+	Add(ctx context.Context, userID, routeID int) error
+	Remove(ctx context.Context, userID, routeID int) error
+	Get(ctx context.Context, userID, routeID int) (fav, notify bool, err error)
+	SetNotify(ctx context.Context, userID, routeID int, notify bool) error
+	ListByUser(ctx context.Context, userID, limit, offset int) (routes []models.RouteVersion, total int, err error)
+	Subscribers(ctx context.Context, routeID int) ([]models.User, error)
+}
+
+// TranslationRepository — переводы контента маршрутов (оригинал — на языке по умолчанию)
+type TranslationRepository interface {
+	Versions(ctx context.Context, lang string, versionIDs []int) (map[int]models.VersionTranslation, error)
+	Points(ctx context.Context, lang string, pointIDs []int) (map[int]models.PointTranslation, error)
+	SetVersionField(ctx context.Context, versionID int, lang, field, value string) error
+	SetPointText(ctx context.Context, pointID int, lang, title, description string) error
+	SetPointArrival(ctx context.Context, pointID int, lang, arrival string) error
+}
+
+// NotificationRepository — настройки уведомлений и очередь запланированных уведомлений
+type NotificationRepository interface {
+	Settings(ctx context.Context, userID int) (*models.NotificationSettings, error)
+	SaveSettings(ctx context.Context, s *models.NotificationSettings) error
+	PlanAccessExpiry(ctx context.Context, before time.Duration) (int64, error)
+	PlanProgressNudges(ctx context.Context, idle time.Duration) (int64, error)
+	PlanReviewRequests(ctx context.Context, delay time.Duration) (int64, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error)
+	Done(ctx context.Context, id int64, lastErr string) error
+	Retry(ctx context.Context, id int64, after time.Duration, lastErr string) error
+	Postpone(ctx context.Context, id int64, after time.Duration) error
+	Target(ctx context.Context, job models.Job) (*domain.JobTarget, error)
+}
+
+type ReviewRepository interface {
+	Rate(ctx context.Context, userID, routeID, rating int) (bool, error)
+}
@@ -0,0 +1,287 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type AuthoringRepo struct{ db *pgxpool.Pool }
+
+func NewAuthoringRepo(db *pgxpool.Pool) *AuthoringRepo { return &AuthoringRepo{db: db} }
+
+// Поля версии, которые автор может менять из бота
+var versionFields = map[string]string{
+	"title":            "title",
+	"city":             "city",
+	"description":      "description",
+	"theme":            "theme",
+	"duration_minutes": "duration_minutes",
+	"length_km":        "length_km",
+	"price":            "price",
+}
+
+// CreateRoute создаёт маршрут-черновик с первой версией
+func (r *AuthoringRepo) CreateRoute(ctx context.Context, authorID int, title string) (routeID, versionID int, err error) {
+	err = pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx,
+			`INSERT INTO routes (status, is_visible, created_by) VALUES ('draft', true, $1) RETURNING id`,
+			authorID).Scan(&routeID); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO route_versions (route_id, version_number, title, description, price, city, status)
+			VALUES ($1, 1, $2, '', 0, '', 'draft')
+			RETURNING id`, routeID, title).Scan(&versionID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, qLogStatus, routeID, versionID, nil, models.RouteStatusDraft, authorID, "создан автором")
+		return err
+	})
+	return routeID, versionID, err
+}
+
+// RoutesByAuthor — последняя версия каждого маршрута автора
+func (r *AuthoringRepo) RoutesByAuthor(ctx context.Context, authorID int) ([]models.RouteVersion, error) {
+	const q = `
+	SELECT DISTINCT ON (rv.route_id)
+	       rv.id, rv.route_id, rv.version_number, COALESCE(rv.title,''), rv.city, rv.status::text
+	FROM route_versions rv
+	JOIN routes r ON r.id = rv.route_id
+	WHERE r.created_by = $1
+	ORDER BY rv.route_id DESC, rv.version_number DESC`
+	rows, err := r.db.Query(ctx, q, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.RouteVersion
+	for rows.Next() {
+		var v models.RouteVersion
+		if err := rows.Scan(&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.City, &v.Status); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// EnsureDraftVersion возвращает черновик маршрута; если последняя версия уже опубликована —
+// копирует её (вместе с точками и медиа) в новую версию-черновик.
+func (r *AuthoringRepo) EnsureDraftVersion(ctx context.Context, routeID int) (int, error) {
+	var draftID int
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			lastID int
+			status string
+		)
+		const qLast = `
+		SELECT rv.id, rv.status::text
+		FROM route_versions rv
+		JOIN routes r ON r.id = rv.route_id
+		WHERE rv.route_id = $1
+		ORDER BY rv.version_number DESC
+		LIMIT 1
+		FOR UPDATE OF r`
+		if err := tx.QueryRow(ctx, qLast, routeID).Scan(&lastID, &status); err != nil {
+			return err
+		}
+		if status == models.RouteStatusDraft {
+			draftID = lastID
+			return nil
+		}
+
+		if err := tx.QueryRow(ctx, `
+			INSERT INTO route_versions (route_id, version_number, title, description, duration_minutes,
+			                            length_km, theme, price, city, status)
+			SELECT route_id, version_number + 1, title, description, duration_minutes,
+			       length_km, theme, price, city, 'draft'
+			FROM route_versions WHERE id = $1
+			RETURNING id`, lastID).Scan(&draftID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO route_version_media (route_version_id, media_id, is_cover, display_order)
+			SELECT $2, media_id, is_cover, display_order FROM route_version_media WHERE route_version_id = $1`,
+			lastID, draftID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO route_version_translations (version_id, language, title, description)
+			SELECT $2, language, title, description FROM route_version_translations WHERE version_id = $1`,
+			lastID, draftID); err != nil {
+			return err
+		}
+		// точки копируем по одной: RETURNING даёт соответствие старый id → новый,
+		// по нему переносим привязанные медиа и переводы
+		rows, err := tx.Query(ctx, `SELECT id FROM route_points WHERE version_id = $1 ORDER BY order_index`, lastID)
+		if err != nil {
+			return err
+		}
+		var srcIDs []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			srcIDs = append(srcIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		newIDs := make([]int, len(srcIDs))
+		for i, srcID := range srcIDs {
+			if err := tx.QueryRow(ctx, `
+				INSERT INTO route_points (version_id, title, description, latitude, longitude, order_index, status, arrival_instructions, is_preview)
+				SELECT $2, title, description, latitude, longitude, order_index, status, arrival_instructions, is_preview
+				FROM route_points WHERE id = $1
+				RETURNING id`, srcID, draftID).Scan(&newIDs[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO route_point_media (route_point_id, media_id, language)
+			SELECT m.new_id, rpm.media_id, rpm.language
+			FROM unnest($1::int[], $2::int[]) AS m(src_id, new_id)
+			JOIN route_point_media rpm ON rpm.route_point_id = m.src_id`, srcIDs, newIDs); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO route_point_translations (point_id, language, title, description, arrival_instructions)
+			SELECT m.new_id, t.language, t.title, t.description, t.arrival_instructions
+			FROM unnest($1::int[], $2::int[]) AS m(src_id, new_id)
+			JOIN route_point_translations t ON t.point_id = m.src_id`, srcIDs, newIDs)
+		return err
+	})
+	return draftID, err
+}
+
+func (r *AuthoringRepo) UpdateVersionField(ctx context.Context, versionID int, field string, value any) error {
+	col, ok := versionFields[field]
+	if !ok {
+		return fmt.Errorf("unknown version field %q", field)
+	}
+	q := fmt.Sprintf(`UPDATE route_versions SET %s = $2 WHERE id = $1 AND status = 'draft'`, col)
+	ct, err := r.db.Exec(ctx, q, versionID, value)
+	if err != nil {
+		return err
+	}
+	if ct.RowsAffected() == 0 {
+		return errors.New("draft version not found")
+	}
+	return nil
+}
+
+func (r *AuthoringRepo) AddPoint(ctx context.Context, versionID int, title string, lat, lon float64) (*models.RoutePoint, error) {
+	const q = `
+	INSERT INTO route_points (version_id, title, description, latitude, longitude, order_index)
+	VALUES ($1, $2, '', $3, $4,
+	        (SELECT COALESCE(MAX(order_index) + 1, 0) FROM route_points WHERE version_id = $1))
+	RETURNING id, version_id, order_index, title, description, latitude, longitude, is_preview, created_at`
+	var p models.RoutePoint
+	if err := r.db.QueryRow(ctx, q, versionID, title, lat, lon).
+		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *AuthoringRepo) PointByID(ctx context.Context, pointID int) (*models.RoutePoint, error) {
+	const q = `SELECT id, version_id, order_index, title, COALESCE(description,''), latitude, longitude, is_preview, created_at
+	           FROM route_points WHERE id = $1`
+	var p models.RoutePoint
+	if err := r.db.QueryRow(ctx, q, pointID).
+		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *AuthoringRepo) UpdatePointText(ctx context.Context, pointID int, title, description string) error {
+	const q = `UPDATE route_points SET title = $2, description = $3, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, pointID, title, description)
+	return err
+}
+
+func (r *AuthoringRepo) SetPointPreview(ctx context.Context, pointID int, preview bool) error {
+	const q = `UPDATE route_points SET is_preview = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, pointID, preview)
+	return err
+}
+
+func (r *AuthoringRepo) AttachPointMedia(ctx context.Context, pointID int, mediaID int64, lang string) error {
+	const q = `INSERT INTO route_point_media (route_point_id, media_id, language) VALUES ($1, $2, NULLIF($3, '')) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(ctx, q, pointID, mediaID, lang)
+	return err
+}
+
+// DeletePoint удаляет точку и сдвигает индексы последующих точек
+func (r *AuthoringRepo) DeletePoint(ctx context.Context, pointID int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var versionID, idx int
+		const qDel = `DELETE FROM route_points WHERE id = $1 RETURNING version_id, order_index`
+		if err := tx.QueryRow(ctx, qDel, pointID).Scan(&versionID, &idx); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`UPDATE route_points SET order_index = order_index - 1 WHERE version_id = $1 AND order_index > $2`,
+			versionID, idx)
+		return err
+	})
+}
+
+// MovePoint меняет точку местами с соседней (delta = -1 — выше, +1 — ниже).
+// Возвращает false, если соседа нет.
+func (r *AuthoringRepo) MovePoint(ctx context.Context, pointID, delta int) (bool, error) {
+	moved := false
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var versionID, idx int
+		if err := tx.QueryRow(ctx,
+			`SELECT version_id, order_index FROM route_points WHERE id = $1 FOR UPDATE`, pointID).
+			Scan(&versionID, &idx); err != nil {
+			return err
+		}
+		var q string
+		if delta < 0 {
+			q = `SELECT id, order_index FROM route_points WHERE version_id=$1 AND order_index<$2 ORDER BY order_index DESC LIMIT 1 FOR UPDATE`
+		} else {
+			q = `SELECT id, order_index FROM route_points WHERE version_id=$1 AND order_index>$2 ORDER BY order_index ASC LIMIT 1 FOR UPDATE`
+		}
+		var otherID, otherIdx int
+		if err := tx.QueryRow(ctx, q, versionID, idx).Scan(&otherID, &otherIdx); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE route_points SET order_index = $2 WHERE id = $1`, pointID, otherIdx); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE route_points SET order_index = $2 WHERE id = $1`, otherID, idx); err != nil {
+			return err
+		}
+		moved = true
+		return nil
+	})
+	return moved, err
+}
+
+// SubmitVersion помечает черновик как отправленный на публикацию
+func (r *AuthoringRepo) SubmitVersion(ctx context.Context, versionID, authorID int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var routeID int
+		const q = `UPDATE route_versions SET submitted_at = NOW() WHERE id = $1 AND status = 'draft' RETURNING route_id`
+		if err := tx.QueryRow(ctx, q, versionID).Scan(&routeID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, qLogStatus, routeID, versionID, models.RouteStatusDraft, models.RouteStatusDraft, authorID, "отправлен на публикацию")
+		return err
+	})
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type BanRepo struct{ db *pgxpool.Pool }
+
+func NewBanRepo(db *pgxpool.Pool) *BanRepo { return &BanRepo{db: db} }
+
+// Ban создаёт или продлевает блокировку
+func (r *BanRepo) Ban(ctx context.Context, b *models.UserBan) error {
+	const q = `
+	INSERT INTO user_bans (telegram_id, until, reason, banned_by)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (telegram_id) DO UPDATE
+	SET until = EXCLUDED.until, reason = EXCLUDED.reason, banned_by = EXCLUDED.banned_by, created_at = NOW()`
+	_, err := r.db.Exec(ctx, q, b.TelegramID, b.Until, b.Reason, b.BannedBy)
+	return err
+}
+
+// Unban возвращает false, если блокировки не было
+func (r *BanRepo) Unban(ctx context.Context, telegramID int64) (bool, error) {
+	ct, err := r.db.Exec(ctx, `DELETE FROM user_bans WHERE telegram_id = $1`, telegramID)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+// Active — действующая блокировка или (nil, nil)
+func (r *BanRepo) Active(ctx context.Context, telegramID int64) (*models.UserBan, error) {
+	const q = `SELECT telegram_id, until, reason, banned_by, created_at
+	           FROM user_bans WHERE telegram_id = $1 AND until > NOW()`
+	var b models.UserBan
+	if err := r.db.QueryRow(ctx, q, telegramID).
+		Scan(&b.TelegramID, &b.Until, &b.Reason, &b.BannedBy, &b.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &b, nil
+}
@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CallbackPayloadRepo — хранилище длинных callback-данных (реализует mux.PayloadStore)
+type CallbackPayloadRepo struct{ db *pgxpool.Pool }
+
+func NewCallbackPayloadRepo(db *pgxpool.Pool) *CallbackPayloadRepo {
+	return &CallbackPayloadRepo{db: db}
+}
+
+// PutPayload: ключ — хеш содержимого, поэтому повторная запись просто освежает created_at
+func (r *CallbackPayloadRepo) PutPayload(ctx context.Context, id, payload string) error {
+	const q = `
+	INSERT INTO callback_payloads (id, payload) VALUES ($1, $2)
+	ON CONFLICT (id) DO UPDATE SET created_at = NOW()`
+	_, err := r.db.Exec(ctx, q, id, payload)
+	return err
+}
+
+func (r *CallbackPayloadRepo) GetPayload(ctx context.Context, id string) (string, bool, error) {
+	var payload string
+	err := r.db.QueryRow(ctx, `SELECT payload FROM callback_payloads WHERE id = $1`, id).Scan(&payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return payload, true, nil
+}
+
+// DeleteExpired удаляет payload'ы старше ttl: кнопки с ними отвечают «кнопка устарела»
+func (r *CallbackPayloadRepo) DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error) {
+	const q = `DELETE FROM callback_payloads WHERE created_at < NOW() - $1 * INTERVAL '1 second'`
+	ct, err := r.db.Exec(ctx, q, ttl.Seconds())
+	return ct.RowsAffected(), err
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type ChatStateRepo struct{ db *pgxpool.Pool }
+
+func NewChatStateRepo(db *pgxpool.Pool) *ChatStateRepo { return &ChatStateRepo{db: db} }
+
+// Get возвращает (nil, nil), если состояния нет
+func (r *ChatStateRepo) Get(ctx context.Context, chatID int64) (*models.ChatState, error) {
+	const q = `SELECT chat_id, state, data, expires_at, updated_at FROM chat_states WHERE chat_id = $1`
+	var s models.ChatState
+	if err := r.db.QueryRow(ctx, q, chatID).Scan(&s.ChatID, &s.Name, &s.Data, &s.ExpiresAt, &s.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *ChatStateRepo) Set(ctx context.Context, s *models.ChatState) error {
+	const q = `
+	INSERT INTO chat_states (chat_id, state, data, expires_at, updated_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (chat_id) DO UPDATE
+	SET state      = EXCLUDED.state,
+	    data       = EXCLUDED.data,
+	    expires_at = EXCLUDED.expires_at,
+	    updated_at = NOW()`
+	data := s.Data
+	if data == nil {
+		data = map[string]string{}
+	}
+	_, err := r.db.Exec(ctx, q, s.ChatID, s.Name, data, s.ExpiresAt)
+	return err
+}
+
+func (r *ChatStateRepo) Delete(ctx context.Context, chatID int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM chat_states WHERE chat_id = $1`, chatID)
+	return err
+}
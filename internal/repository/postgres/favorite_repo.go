@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type FavoriteRepo struct{ db *pgxpool.Pool }
+
+func NewFavoriteRepo(db *pgxpool.Pool) *FavoriteRepo { return &FavoriteRepo{db: db} }
+
+func (r *FavoriteRepo) Add(ctx context.Context, userID, routeID int) error {
+	const q = `INSERT INTO favorites (user_id, route_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.db.Exec(ctx, q, userID, routeID)
+	return err
+}
+
+func (r *FavoriteRepo) Remove(ctx context.Context, userID, routeID int) error {
+	const q = `DELETE FROM favorites WHERE user_id = $1 AND route_id = $2`
+	_, err := r.db.Exec(ctx, q, userID, routeID)
+	return err
+}
+
+// Get — в избранном ли маршрут и включены ли уведомления
+func (r *FavoriteRepo) Get(ctx context.Context, userID, routeID int) (fav, notify bool, err error) {
+	const q = `SELECT notify FROM favorites WHERE user_id = $1 AND route_id = $2`
+	err = r.db.QueryRow(ctx, q, userID, routeID).Scan(&notify)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return true, notify, nil
+}
+
+func (r *FavoriteRepo) SetNotify(ctx context.Context, userID, routeID int, notify bool) error {
+	const q = `UPDATE favorites SET notify = $3 WHERE user_id = $1 AND route_id = $2`
+	_, err := r.db.Exec(ctx, q, userID, routeID, notify)
+	return err
+}
+
+// ListByUser — страница избранного: актуальные версии маршрутов, новые отметки первыми.
+// Маршруты, ушедшие из каталога, не показываются, но отметка сохраняется.
+func (r *FavoriteRepo) ListByUser(ctx context.Context, userID, limit, offset int) ([]models.RouteVersion, int, error) {
+	const q = `WITH ` + currentVersionsCTE + `
+	SELECT ` + versionColumns + `, count(*) OVER ()
+	FROM favorites f
+	JOIN route_versions rv ON rv.route_id = f.route_id
+	JOIN cur ON cur.id = rv.id
+	` + coverLateral + `
+	WHERE f.user_id = $1
+	ORDER BY f.created_at DESC, f.route_id
+	LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, q, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		out   []models.RouteVersion
+		total int
+	)
+	for rows.Next() {
+		var v models.RouteVersion
+		if err := rows.Scan(
+			&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.Description,
+			&v.DurationMinutes, &v.LengthKm, &v.Theme, &v.Price, &v.City,
+			&v.Status, &v.PublishedAt, &v.SubmittedAt, &v.CreatedAt, &v.CoverMediaID, &total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan favorite: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, total, rows.Err()
+}
+
+// Subscribers — те, кто ждёт новостей о маршруте: telegram_id и язык для текста
+func (r *FavoriteRepo) Subscribers(ctx context.Context, routeID int) ([]models.User, error) {
+	const q = `
+	SELECT u.id, u.telegram_id, COALESCE(u.language, '')
+	FROM favorites f
+	JOIN users u ON u.id = f.user_id
+	WHERE f.route_id = $1 AND f.notify
+	ORDER BY f.created_at`
+	rows, err := r.db.Query(ctx, q, routeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.TelegramID, &u.Language); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -47,6 +47,23 @@ WHERE id = $1`
 	return &m, nil
 }
 
+// Create регистрирует загруженный файл; возвращает id новой записи
+func (r *MediaRepo) Create(ctx context.Context, m *models.Media) (int64, error) {
+	const q = `
+INSERT INTO media (type, url, filename, size_bytes, description, uploaded_by, is_public,
+                   mime_type, s3_bucket, s3_key)
+VALUES ($1::media_type, $2, $3, $4, $5, NULLIF($6, 0), $7, $8, $9, $10)
+RETURNING id`
+	var id int64
+	if err := r.db.QueryRow(ctx, q,
+		m.Type, m.URL, m.Filename, m.SizeBytes, m.Description, m.UploadedBy, m.IsPublic,
+		m.MimeType, m.S3Bucket, m.S3Key,
+	).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
 // --- S3 storage (original) ---
 
 func (r *MediaRepo) UpdateStorage(ctx context.Context, mediaID int64, bucket, key, mimeType string, sizeBytes int64) error {
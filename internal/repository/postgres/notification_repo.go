@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/domain"
+	"walki/internal/models"
+)
+
+type NotificationRepo struct{ db *pgxpool.Pool }
+
+func NewNotificationRepo(db *pgxpool.Pool) *NotificationRepo { return &NotificationRepo{db: db} }
+
+// Settings — настройки пользователя; не менял — настройки по умолчанию
+func (r *NotificationRepo) Settings(ctx context.Context, userID int) (*models.NotificationSettings, error) {
+	const q = `SELECT reminders, nudges, reviews, quiet_from, quiet_to FROM notification_settings WHERE user_id = $1`
+	s := models.NotificationSettings{UserID: userID}
+	var from, to *int16
+	err := r.db.QueryRow(ctx, q, userID).Scan(&s.Reminders, &s.Nudges, &s.Reviews, &from, &to)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.DefaultNotificationSettings(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if from != nil && to != nil {
+		f, t := int(*from), int(*to)
+		s.QuietFrom, s.QuietTo = &f, &t
+	}
+	return &s, nil
+}
+
+func (r *NotificationRepo) SaveSettings(ctx context.Context, s *models.NotificationSettings) error {
+	const q = `
+	  INSERT INTO notification_settings (user_id, reminders, nudges, reviews, quiet_from, quiet_to, updated_at)
+	  VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	  ON CONFLICT (user_id) DO UPDATE
+	    SET reminders = EXCLUDED.reminders,
+	        nudges = EXCLUDED.nudges,
+	        reviews = EXCLUDED.reviews,
+	        quiet_from = EXCLUDED.quiet_from,
+	        quiet_to = EXCLUDED.quiet_to,
+	        updated_at = NOW()`
+	_, err := r.db.Exec(ctx, q, s.UserID, s.Reminders, s.Nudges, s.Reviews, s.QuietFrom, s.QuietTo)
+	return err
+}
+
+// === Планирование: идемпотентно, можно запускать с любого экземпляра
+
+// PlanAccessExpiry — напоминания за before до окончания доступа; планируются
+// не раньше чем за сутки до отправки, продлённые повторной покупкой заказы пропускаются
+func (r *NotificationRepo) PlanAccessExpiry(ctx context.Context, before time.Duration) (int64, error) {
+	const q = `
+	  INSERT INTO scheduled_jobs (kind, ref_id, user_id, run_at)
+	  SELECT $1, o.id, o.user_id, o.access_expiry - $2 * INTERVAL '1 second'
+	  FROM orders o
+	  WHERE o.status = 'paid'
+	    AND o.access_expiry > NOW()
+	    AND o.access_expiry - $2 * INTERVAL '1 second' <= NOW() + INTERVAL '1 day'
+	    AND NOT EXISTS (` + longerOrder + `)
+	  ON CONFLICT (kind, ref_id) DO NOTHING`
+	ct, err := r.db.Exec(ctx, q, models.JobAccessExpiry, before.Seconds())
+	return ct.RowsAffected(), err
+}
+
+// PlanProgressNudges — незаконченные прогулки без действий дольше idle
+// (брошенные больше недели назад не тревожим)
+func (r *NotificationRepo) PlanProgressNudges(ctx context.Context, idle time.Duration) (int64, error) {
+	const q = `
+	  INSERT INTO scheduled_jobs (kind, ref_id, user_id, run_at)
+	  SELECT $1, p.id, p.user_id, p.last_activity_at + $2 * INTERVAL '1 second'
+	  FROM route_progress p
+	  JOIN route_versions rv ON rv.id = p.version_id AND rv.status <> 'draft'
+	  WHERE p.finished_at IS NULL
+	    AND p.last_activity_at <= NOW() - $2 * INTERVAL '1 second'
+	    AND p.last_activity_at > NOW() - $2 * INTERVAL '1 second' - INTERVAL '7 days'
+	  ON CONFLICT (kind, ref_id) DO NOTHING`
+	ct, err := r.db.Exec(ctx, q, models.JobProgressNudge, idle.Seconds())
+	return ct.RowsAffected(), err
+}
+
+// PlanReviewRequests — просьба об оценке через delay после завершения прогулки
+func (r *NotificationRepo) PlanReviewRequests(ctx context.Context, delay time.Duration) (int64, error) {
+	const q = `
+	  INSERT INTO scheduled_jobs (kind, ref_id, user_id, run_at)
+	  SELECT $1, p.id, p.user_id, p.finished_at + $2 * INTERVAL '1 second'
+	  FROM route_progress p
+	  JOIN route_versions rv ON rv.id = p.version_id AND rv.status <> 'draft'
+	  WHERE p.finished_at IS NOT NULL
+	    AND p.finished_at > NOW() - INTERVAL '7 days'
+	    AND NOT EXISTS (SELECT 1 FROM reviews rw WHERE rw.user_id = p.user_id AND rw.route_id = p.route_id)
+	  ON CONFLICT (kind, ref_id) DO NOTHING`
+	ct, err := r.db.Exec(ctx, q, models.JobReviewRequest, delay.Seconds())
+	return ct.RowsAffected(), err
+}
+
+// longerOrder — у пользователя есть другой заказ маршрута с доступом дольше, чем у o
+const longerOrder = `
+	SELECT 1 FROM orders o2
+	WHERE o2.user_id = o.user_id AND o2.route_id = o.route_id AND o2.status = 'paid' AND o2.id <> o.id
+	  AND (o2.access_expiry IS NULL OR o2.access_expiry > o.access_expiry)`
+
+// === Исполнение
+
+// Claim занимает до limit наступивших заданий на lease. Занятые другим экземпляром
+// строки пропускаются (SKIP LOCKED); не закрытое за lease задание заберут снова.
+func (r *NotificationRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.Job, error) {
+	const q = `
+	  UPDATE scheduled_jobs j
+	  SET locked_until = NOW() + $2 * INTERVAL '1 second', attempts = j.attempts + 1
+	  WHERE j.id IN (
+	    SELECT id FROM scheduled_jobs
+	    WHERE done_at IS NULL AND run_at <= NOW()
+	      AND (locked_until IS NULL OR locked_until < NOW())
+	    ORDER BY run_at
+	    LIMIT $1
+	    FOR UPDATE SKIP LOCKED)
+	  RETURNING j.id, j.kind, j.ref_id, j.user_id, j.run_at, j.attempts`
+	rows, err := r.db.Query(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.Job
+	for rows.Next() {
+		var j models.Job
+		if err := rows.Scan(&j.ID, &j.Kind, &j.RefID, &j.UserID, &j.RunAt, &j.Attempts); err != nil {
+			return nil, err
+		}
+		out = append(out, j)
+	}
+	return out, rows.Err()
+}
+
+// Done закрывает задание; lastErr — почему закрыто без отправки (пусто — отправлено)
+func (r *NotificationRepo) Done(ctx context.Context, id int64, lastErr string) error {
+	const q = `UPDATE scheduled_jobs SET done_at = NOW(), locked_until = NULL, last_error = NULLIF($2, '') WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, id, lastErr)
+	return err
+}
+
+// Retry возвращает задание в очередь через after после ошибки
+func (r *NotificationRepo) Retry(ctx context.Context, id int64, after time.Duration, lastErr string) error {
+	const q = `UPDATE scheduled_jobs SET run_at = NOW() + $2 * INTERVAL '1 second', locked_until = NULL, last_error = $3 WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, id, after.Seconds(), lastErr)
+	return err
+}
+
+// Postpone откладывает задание на after (тихие часы, пользователь вернулся); попыткой это не считается
+func (r *NotificationRepo) Postpone(ctx context.Context, id int64, after time.Duration) error {
+	const q = `UPDATE scheduled_jobs SET run_at = NOW() + $2 * INTERVAL '1 second', locked_until = NULL, attempts = attempts - 1 WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, id, after.Seconds())
+	return err
+}
+
+// Target — получатель и предмет уведомления; nil — уведомление уже неактуально
+func (r *NotificationRepo) Target(ctx context.Context, job models.Job) (*domain.JobTarget, error) {
+	var q string
+	switch job.Kind {
+	case models.JobAccessExpiry:
+		q = `
+		  SELECT ` + targetUserColumns + `, o.route_id, COALESCE(o.version_id, 0), o.access_expiry, 0::float8
+		  FROM orders o
+		  JOIN users u ON u.id = o.user_id
+		  WHERE o.id = $1 AND o.status = 'paid' AND o.access_expiry > NOW()
+		    AND NOT EXISTS (` + longerOrder + `)`
+	case models.JobProgressNudge:
+		q = `
+		  SELECT ` + targetUserColumns + `, p.route_id, p.version_id, NULL::timestamp, EXTRACT(EPOCH FROM NOW() - p.last_activity_at)::float8
+		  FROM route_progress p
+		  JOIN users u ON u.id = p.user_id
+		  WHERE p.id = $1 AND p.finished_at IS NULL
+		    AND EXISTS (SELECT 1 FROM orders o
+		                WHERE o.user_id = p.user_id AND o.route_id = p.route_id AND o.status = 'paid'
+		                  AND (o.access_expiry IS NULL OR o.access_expiry >= NOW()))`
+	case models.JobReviewRequest:
+		q = `
+		  SELECT ` + targetUserColumns + `, p.route_id, p.version_id, NULL::timestamp, 0::float8
+		  FROM route_progress p
+		  JOIN users u ON u.id = p.user_id
+		  WHERE p.id = $1 AND p.finished_at IS NOT NULL
+		    AND NOT EXISTS (SELECT 1 FROM reviews rw WHERE rw.user_id = p.user_id AND rw.route_id = p.route_id)`
+	default:
+		return nil, fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	var t domain.JobTarget
+	var idle float64
+	err := r.db.QueryRow(ctx, q, job.RefID).Scan(
+		&t.User.ID, &t.User.TelegramID, &t.User.Language,
+		&t.RouteID, &t.VersionID, &t.AccessExpiry, &idle,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Idle = time.Duration(idle * float64(time.Second))
+	return &t, nil
+}
+
+const targetUserColumns = `u.id, u.telegram_id, COALESCE(u.language, '')`
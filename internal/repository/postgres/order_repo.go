@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 	"walki/internal/domain"
+	"walki/internal/repository"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,12 +15,12 @@ type OrderRepo struct{ db *pgxpool.Pool }
 
 func NewOrderRepo(db *pgxpool.Pool) *OrderRepo { return &OrderRepo{db: db} }
 
-func (r *OrderRepo) Create(ctx context.Context, userID, routeID int, amount float64) (int, *time.Time, error) {
-	// берём актуальную версию маршрута
+func (r *OrderRepo) Create(ctx context.Context, userID, routeID int, amount float64, promoCode string) (int, *time.Time, error) {
+	// берём актуальную опубликованную версию маршрута
 	const qVersion = `
 	  SELECT id
 	  FROM route_versions
-	  WHERE route_id = $1
+	  WHERE route_id = $1 AND status = 'published'
 	  ORDER BY version_number DESC
 	  LIMIT 1;
 	`
@@ -29,11 +32,32 @@ func (r *OrderRepo) Create(ctx context.Context, userID, routeID int, amount floa
 	// создаём заказ как "paid" с доступом 30 дней (если хочешь — поставь NULL = бессрочно)
 	expiry := time.Now().Add(30 * 24 * time.Hour)
 	const qInsert = `
-	  INSERT INTO orders (user_id, version_id, route_id, status, amount, paid_at, access_expiry)
-	  VALUES ($1,$2,$3,'paid',$4,NOW(),$5)
+	  INSERT INTO orders (user_id, version_id, route_id, status, amount, paid_at, access_expiry, promo_code)
+	  VALUES ($1,$2,$3,'paid',$4,NOW(),$5,NULLIF($6,''))
 	  RETURNING access_expiry;
 	`
-	if err := r.db.QueryRow(ctx, qInsert, userID, versionID, routeID, amount, expiry).Scan(&expiry); err != nil {
+	// промокод списывается вместе с заказом: лимит использований не превысить гонкой
+	const qUsePromo = `
+	  UPDATE promo_codes SET used_count = used_count + 1
+	  WHERE code = $1
+	    AND (valid_until IS NULL OR valid_until > NOW())
+	    AND (max_uses IS NULL OR used_count < max_uses)`
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if promoCode != "" {
+			ct, err := tx.Exec(ctx, qUsePromo, promoCode)
+			if err != nil {
+				return err
+			}
+			if ct.RowsAffected() == 0 {
+				return repository.ErrPromoUnavailable
+			}
+			if _, err := tx.Exec(ctx, `DELETE FROM user_promos WHERE user_id = $1 AND code = $2`, userID, promoCode); err != nil {
+				return err
+			}
+		}
+		return tx.QueryRow(ctx, qInsert, userID, versionID, routeID, amount, expiry, promoCode).Scan(&expiry)
+	})
+	if err != nil {
 		return 0, nil, err
 	}
 	return versionID, &expiry, nil
@@ -41,9 +65,9 @@ func (r *OrderRepo) Create(ctx context.Context, userID, routeID int, amount floa
 
 func (r *OrderRepo) ListByUser(ctx context.Context, userID int) ([]domain.OrderSummary, error) {
 	const q = `
-	  SELECT o.route_id, rv.title, rv.city, o.version_id, o.access_expiry
+	  SELECT o.route_id, rv.title, rv.city, rv.id, o.access_expiry
 	  FROM orders o
-	  JOIN route_versions rv ON rv.id = o.version_id
+	  JOIN route_versions rv ON rv.id = COALESCE(o.walk_version_id, o.version_id)
 	  WHERE o.user_id = $1 AND o.status = 'paid'
 	  ORDER BY o.created_at DESC;
 	`
@@ -64,6 +88,36 @@ func (r *OrderRepo) ListByUser(ctx context.Context, userID int) ([]domain.OrderS
 	return out, rows.Err()
 }
 
+// ListByUserPage — страница оплаченных заказов пользователя и их общее число
+func (r *OrderRepo) ListByUserPage(ctx context.Context, userID, limit, offset int) ([]domain.OrderSummary, int, error) {
+	const q = `
+	  SELECT o.route_id, rv.title, rv.city, rv.id, o.access_expiry, count(*) OVER ()
+	  FROM orders o
+	  JOIN route_versions rv ON rv.id = COALESCE(o.walk_version_id, o.version_id)
+	  WHERE o.user_id = $1 AND o.status = 'paid'
+	  ORDER BY o.created_at DESC, o.id DESC
+	  LIMIT $2 OFFSET $3;
+	`
+	rows, err := r.db.Query(ctx, q, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		out   []domain.OrderSummary
+		total int
+	)
+	for rows.Next() {
+		var s domain.OrderSummary
+		if err := rows.Scan(&s.RouteID, &s.RouteTitle, &s.RouteCity, &s.VersionID, &s.AccessExpiry, &total); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, s)
+	}
+	return out, total, rows.Err()
+}
+
 func (r *OrderRepo) UserHasAccess(ctx context.Context, userID, routeID int) (bool, error) {
 	const q = `
 	  SELECT EXISTS(
@@ -78,3 +132,28 @@ func (r *OrderRepo) UserHasAccess(ctx context.Context, userID, routeID int) (boo
 	}
 	return ok, nil
 }
+
+// Последний действующий заказ пользователя на маршрут
+const qActiveOrder = `
+	  SELECT id FROM orders
+	  WHERE user_id=$1 AND route_id=$2 AND status='paid'
+	    AND (access_expiry IS NULL OR access_expiry >= NOW())
+	  ORDER BY created_at DESC
+	  LIMIT 1`
+
+// PinnedVersion — версия, по которой пользователь проходит маршрут:
+// купленная либо выбранная им при обновлении
+func (r *OrderRepo) PinnedVersion(ctx context.Context, userID, routeID int) (int, bool, error) {
+	const q = `
+	  SELECT COALESCE(walk_version_id, version_id)
+	  FROM orders
+	  WHERE id = (` + qActiveOrder + `)`
+	var versionID int
+	if err := r.db.QueryRow(ctx, q, userID, routeID).Scan(&versionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return versionID, true, nil
+}
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type PromoRepo struct{ db *pgxpool.Pool }
+
+func NewPromoRepo(db *pgxpool.Pool) *PromoRepo { return &PromoRepo{db: db} }
+
+const promoColumns = `p.code, p.discount_percent, p.valid_until, p.max_uses, p.used_count, p.created_at`
+
+func scanPromo(row pgx.Row) (*models.PromoCode, error) {
+	var p models.PromoCode
+	if err := row.Scan(&p.Code, &p.DiscountPercent, &p.ValidUntil, &p.MaxUses, &p.UsedCount, &p.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ByCode — промокод без учёта регистра или (nil, nil)
+func (r *PromoRepo) ByCode(ctx context.Context, code string) (*models.PromoCode, error) {
+	return scanPromo(r.db.QueryRow(ctx,
+		`SELECT `+promoColumns+` FROM promo_codes p WHERE upper(p.code) = upper($1)`, code))
+}
+
+// Activate запоминает промокод до следующей покупки, заменяя прежний
+func (r *PromoRepo) Activate(ctx context.Context, userID int, code string) error {
+	const q = `
+	INSERT INTO user_promos (user_id, code) VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET code = EXCLUDED.code, activated_at = NOW()`
+	_, err := r.db.Exec(ctx, q, userID, code)
+	return err
+}
+
+// Pending — активированный и ещё не использованный промокод или (nil, nil)
+func (r *PromoRepo) Pending(ctx context.Context, userID int) (*models.PromoCode, error) {
+	return scanPromo(r.db.QueryRow(ctx,
+		`SELECT `+promoColumns+` FROM user_promos up JOIN promo_codes p ON p.code = up.code WHERE up.user_id = $1`, userID))
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+type PublicationRepo struct{ db *pgxpool.Pool }
+
+func NewPublicationRepo(db *pgxpool.Pool) *PublicationRepo { return &PublicationRepo{db: db} }
+
+const qLogStatus = `
+INSERT INTO route_status_history (route_id, version_id, from_status, to_status, changed_by, comment)
+VALUES ($1, $2, $3::route_status, $4::route_status, NULLIF($5, 0), NULLIF($6, ''))`
+
+// PublishVersion публикует версию-черновик: прежние опубликованные версии уходят в архив,
+// сам маршрут становится published. Все переходы пишутся в журнал.
+// Версия не в черновике (например, её уже опубликовал параллельный запрос) —
+// repository.ErrStatusChanged.
+func (r *PublicationRepo) PublishVersion(ctx context.Context, versionID, actorID int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var (
+			routeID       int
+			versionStatus string
+			routeStatus   string
+		)
+		const qLock = `
+		SELECT rv.route_id, rv.status::text, r.status::text
+		FROM route_versions rv
+		JOIN routes r ON r.id = rv.route_id
+		WHERE rv.id = $1
+		FOR UPDATE OF r, rv`
+		if err := tx.QueryRow(ctx, qLock, versionID).Scan(&routeID, &versionStatus, &routeStatus); err != nil {
+			return err
+		}
+		if versionStatus != models.RouteStatusDraft {
+			return repository.ErrStatusChanged
+		}
+
+		// старые опубликованные версии -> archived
+		rows, err := tx.Query(ctx, `
+			UPDATE route_versions SET status = 'archived'
+			WHERE route_id = $1 AND status = 'published' AND id <> $2
+			RETURNING id`, routeID, versionID)
+		if err != nil {
+			return err
+		}
+		var archived []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			archived = append(archived, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, id := range archived {
+			if _, err := tx.Exec(ctx, qLogStatus, routeID, id, models.RouteStatusPublished, models.RouteStatusArchived, actorID, models.HistoryReasonReplaced); err != nil {
+				return err
+			}
+		}
+
+		ct, err := tx.Exec(ctx, `
+			UPDATE route_versions
+			SET status = 'published', published_at = NOW(), published_by = NULLIF($2, 0)
+			WHERE id = $1 AND status = 'draft'`, versionID, actorID)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return repository.ErrStatusChanged
+		}
+		if _, err := tx.Exec(ctx, qLogStatus, routeID, versionID, versionStatus, models.RouteStatusPublished, actorID, ""); err != nil {
+			return err
+		}
+
+		if routeStatus != models.RouteStatusPublished {
+			if _, err := tx.Exec(ctx, `UPDATE routes SET status = 'published', updated_at = NOW() WHERE id = $1`, routeID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, qLogStatus, routeID, nil, routeStatus, models.RouteStatusPublished, actorID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetRouteStatus переводит маршрут из статуса from в to и пишет переход в журнал.
+// Маршрут уже не в статусе from (или не найден) — repository.ErrStatusChanged.
+func (r *PublicationRepo) SetRouteStatus(ctx context.Context, routeID int, from, to string, actorID int, comment string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		ct, err := tx.Exec(ctx, `
+			UPDATE routes SET status = $3::route_status, updated_at = NOW()
+			WHERE id = $1 AND status = $2::route_status`, routeID, from, to)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return repository.ErrStatusChanged
+		}
+		_, err = tx.Exec(ctx, qLogStatus, routeID, nil, from, to, actorID, comment)
+		return err
+	})
+}
+
+// SetVisible скрывает/показывает маршрут в каталоге; статус не меняется, но факт фиксируется в журнале
+func (r *PublicationRepo) SetVisible(ctx context.Context, routeID int, visible bool, actorID int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		var status string
+		const q = `UPDATE routes SET is_visible = $2, updated_at = NOW() WHERE id = $1 RETURNING status::text`
+		if err := tx.QueryRow(ctx, q, routeID, visible).Scan(&status); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("route not found")
+			}
+			return err
+		}
+		comment := models.HistoryReasonHidden
+		if visible {
+			comment = models.HistoryReasonShown
+		}
+		_, err := tx.Exec(ctx, qLogStatus, routeID, nil, status, status, actorID, comment)
+		return err
+	})
+}
+
+func (r *PublicationRepo) History(ctx context.Context, routeID, limit int) ([]models.RouteStatusChange, error) {
+	const q = `
+	SELECT id, route_id, version_id, from_status::text, to_status::text, changed_by, changed_at, COALESCE(comment, '')
+	FROM route_status_history
+	WHERE route_id = $1
+	ORDER BY changed_at DESC, id DESC
+	LIMIT $2`
+	rows, err := r.db.Query(ctx, q, routeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.RouteStatusChange
+	for rows.Next() {
+		var c models.RouteStatusChange
+		if err := rows.Scan(&c.ID, &c.RouteID, &c.VersionID, &c.FromStatus, &c.ToStatus, &c.ChangedBy, &c.ChangedAt, &c.Comment); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
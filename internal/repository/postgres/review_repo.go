@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReviewRepo struct{ db *pgxpool.Pool }
+
+func NewReviewRepo(db *pgxpool.Pool) *ReviewRepo { return &ReviewRepo{db: db} }
+
+// Rate ставит или меняет оценку маршрута пользователем; отзыв привязывается
+// к последнему оплаченному заказу. false — заказа нет, оценивать нечего.
+// Одновременные оценки сходятся в одну строку благодаря uq_reviews_user_route.
+func (r *ReviewRepo) Rate(ctx context.Context, userID, routeID, rating int) (bool, error) {
+	const q = `
+	  INSERT INTO reviews (order_id, route_id, user_id, rating)
+	  SELECT o.id, $2, $1, $3
+	  FROM orders o
+	  WHERE o.user_id = $1 AND o.route_id = $2 AND o.status = 'paid'
+	  ORDER BY o.created_at DESC
+	  LIMIT 1
+	  ON CONFLICT (user_id, route_id) DO UPDATE
+	    SET rating = EXCLUDED.rating, order_id = EXCLUDED.order_id, updated_at = NOW()`
+	ct, err := r.db.Exec(ctx, q, userID, routeID, rating)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
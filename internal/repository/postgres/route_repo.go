@@ -2,115 +2,381 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"unicode"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/domain"
 	"walki/internal/models"
+	"walki/internal/repository"
 )
 
 type RouteRepo struct{ db *pgxpool.Pool }
 
 func NewRouteRepo(db *pgxpool.Pool) *RouteRepo { return &RouteRepo{db: db} }
 
-func (r *RouteRepo) Cities(ctx context.Context) ([]string, error) {
-	rows, err := r.db.Query(ctx, `SELECT DISTINCT city FROM route_versions ORDER BY city`)
-	if err != nil {
+// Обложка версии: помеченная is_cover, иначе первое изображение по display_order.
+// LATERAL — ровно одна строка на версию, без размножения при нескольких фото.
+const coverLateral = `LEFT JOIN LATERAL (
+		SELECT m.id
+		FROM route_version_media rvm
+		JOIN media m ON m.id = rvm.media_id AND m.type = 'image'
+		WHERE rvm.route_version_id = rv.id
+		ORDER BY rvm.is_cover DESC NULLS LAST, rvm.display_order NULLS LAST, rvm.media_id
+		LIMIT 1
+	) cover ON true`
+
+// Колонки для scanVersion поверх route_versions rv и обложки cover
+const versionColumns = `rv.id, rv.route_id, rv.version_number, rv.title, rv.description,
+	       COALESCE(rv.duration_minutes,0), COALESCE(rv.length_km,0),
+	       COALESCE(rv.theme,''), COALESCE(rv.price,0), rv.city,
+	       rv.status::text, rv.published_at, rv.submitted_at, rv.created_at,
+	       COALESCE(cover.id, 0)`
+
+// Общая выборка версии с обложкой; условия дописываются вызывающим методом
+const versionSelect = `
+	SELECT ` + versionColumns + `
+	FROM route_versions rv
+	JOIN routes r ON r.id = rv.route_id
+	` + coverLateral + `
+`
+
+// Условие «маршрут виден в каталоге»
+const catalogFilter = `r.status = 'published' AND r.is_visible AND rv.status = 'published'`
+
+func scanVersion(row pgx.Row) (*models.RouteVersion, error) {
+	var v models.RouteVersion
+	if err := row.Scan(
+		&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.Description,
+		&v.DurationMinutes, &v.LengthKm, &v.Theme, &v.Price, &v.City,
+		&v.Status, &v.PublishedAt, &v.SubmittedAt, &v.CreatedAt, &v.CoverMediaID,
+	); err != nil {
 		return nil, err
 	}
+	return &v, nil
+}
+
+// Cities — страница городов каталога и их общее число
+func (r *RouteRepo) Cities(ctx context.Context, limit, offset int) ([]string, int, error) {
+	const q = `
+	SELECT c.city, count(*) OVER ()
+	FROM (
+		SELECT DISTINCT rv.city
+		FROM route_versions rv
+		JOIN routes r ON r.id = rv.route_id
+		WHERE ` + catalogFilter + `
+	) c
+	ORDER BY c.city
+	LIMIT $1 OFFSET $2`
+	rows, err := r.db.Query(ctx, q, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
 	defer rows.Close()
 
-	var out []string
+	var (
+		out   []string
+		total int
+	)
 	for rows.Next() {
 		var c string
-		if err := rows.Scan(&c); err != nil {
-			return nil, err
+		if err := rows.Scan(&c, &total); err != nil {
+			return nil, 0, err
 		}
 		out = append(out, c)
 	}
-	return out, rows.Err()
+	return out, total, rows.Err()
 }
 
-func (r *RouteRepo) ByCity(ctx context.Context, city string) ([]models.RouteVersion, error) {
+// ByCity — страница маршрутов города и их общее число
+func (r *RouteRepo) ByCity(ctx context.Context, city string, limit, offset int) ([]models.RouteVersion, int, error) {
+	// по одной (последней опубликованной) версии на маршрут
 	const q = `
-	SELECT id,
-		   route_id,
-		   version_number,
-		   title,
-		   description,
-		   duration_minutes,
-		   length_km,
-		   theme,
-		   price,
-		   city,
-		   created_at
-	FROM route_versions
-	WHERE city = $1
-	ORDER BY created_at DESC
+	SELECT v.*, count(*) OVER () FROM (
+		SELECT DISTINCT ON (rv.route_id)
+		       rv.id, rv.route_id, rv.version_number, rv.title, rv.description,
+		       COALESCE(rv.duration_minutes,0), COALESCE(rv.length_km,0),
+		       COALESCE(rv.theme,''), COALESCE(rv.price,0), rv.city,
+		       rv.status::text, rv.published_at, rv.created_at
+		FROM route_versions rv
+		JOIN routes r ON r.id = rv.route_id
+		WHERE rv.city = $1 AND ` + catalogFilter + `
+		ORDER BY rv.route_id, rv.version_number DESC
+	) v
+	ORDER BY v.created_at DESC, v.route_id
+	LIMIT $2 OFFSET $3
 	`
-	rows, err := r.db.Query(ctx, q, city)
+	rows, err := r.db.Query(ctx, q, city, limit, offset)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
-	var out []models.RouteVersion
+	var (
+		out   []models.RouteVersion
+		total int
+	)
 	for rows.Next() {
 		var r models.RouteVersion
 		if err := rows.Scan(
 			&r.ID, &r.RouteID, &r.VersionNumber, &r.Title, &r.Description,
-			&r.DurationMinutes, &r.LengthKm, &r.Theme, &r.Price, &r.City, &r.CreatedAt,
+			&r.DurationMinutes, &r.LengthKm, &r.Theme, &r.Price, &r.City,
+			&r.Status, &r.PublishedAt, &r.CreatedAt, &total,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan route: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan route: %w", err)
 		}
 		out = append(out, r)
 	}
+	return out, total, rows.Err()
+}
+
+// Актуальные версии каталога: последняя опубликованная версия каждого видимого маршрута
+const currentVersionsCTE = `cur AS (
+		SELECT DISTINCT ON (rv.route_id) rv.id
+		FROM route_versions rv
+		JOIN routes r ON r.id = rv.route_id
+		WHERE ` + catalogFilter + `
+		ORDER BY rv.route_id, rv.version_number DESC
+	)`
+
+// Search — полнотекстовый поиск по каталогу с фильтрами (русская морфология, префиксы слов).
+// Участвует только актуальная опубликованная версия маршрута; с текстом — по релевантности.
+func (r *RouteRepo) Search(ctx context.Context, f models.RouteFilter, limit, offset int) ([]models.RouteVersion, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	rank := "0"
+	if tsq := prefixTSQuery(f.Query); tsq != "" {
+		q := "to_tsquery('russian', " + arg(tsq) + ")"
+		conds = append(conds, "rv.search_tsv @@ "+q)
+		rank = "ts_rank(rv.search_tsv, " + q + ")"
+	}
+	if f.Theme != "" {
+		conds = append(conds, "lower(rv.theme) = lower("+arg(f.Theme)+")")
+	}
+	if f.MinDuration > 0 {
+		conds = append(conds, "rv.duration_minutes > "+arg(f.MinDuration))
+	}
+	if f.MaxDuration > 0 {
+		conds = append(conds, "rv.duration_minutes <= "+arg(f.MaxDuration))
+	}
+	if f.MinLength > 0 {
+		conds = append(conds, "rv.length_km > "+arg(f.MinLength))
+	}
+	if f.MaxLength > 0 {
+		conds = append(conds, "rv.length_km <= "+arg(f.MaxLength))
+	}
+	if f.MinPrice > 0 {
+		conds = append(conds, "rv.price > "+arg(f.MinPrice))
+	}
+	if f.MaxPrice > 0 {
+		conds = append(conds, "rv.price <= "+arg(f.MaxPrice))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	q := `WITH ` + currentVersionsCTE + `
+	SELECT ` + versionColumns + `
+	FROM cur
+	JOIN route_versions rv ON rv.id = cur.id
+	` + coverLateral + `
+	` + where + `
+	ORDER BY ` + rank + ` DESC, rv.published_at DESC NULLS LAST, rv.route_id
+	LIMIT ` + arg(limit) + ` OFFSET ` + arg(offset)
+
+	rows, err := r.db.Query(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.RouteVersion
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan route: %w", err)
+		}
+		out = append(out, *v)
+	}
 	return out, rows.Err()
 }
 
-func (r *RouteRepo) Details(ctx context.Context, routeID int) (*models.RouteVersion, error) {
+// prefixTSQuery: «старый гор» -> «старый:* & гор:*». Ввод режется на буквы и цифры,
+// поэтому спецсимволы to_tsquery из него не попадают.
+func prefixTSQuery(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, w := range words {
+		words[i] = w + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// Nearby — маршруты каталога, чья первая точка с координатами лежит в радиусе radiusKm,
+// от ближайших к дальним. Расстояние — по формуле гаверсинусов, без PostGIS;
+// грубая рамка по широте и долготе отсекает заведомо далёкие точки до расчёта.
+func (r *RouteRepo) Nearby(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]domain.NearbyRoute, error) {
+	const q = `WITH ` + currentVersionsCTE + `,
+	params AS (SELECT $1::float8 AS lat, $2::float8 AS lon, $3::float8 AS radius),
+	start AS (
+		SELECT DISTINCT ON (p.version_id) p.version_id, p.latitude::float8 AS lat, p.longitude::float8 AS lon
+		FROM route_points p
+		JOIN cur ON cur.id = p.version_id
+		WHERE p.latitude IS NOT NULL AND p.longitude IS NOT NULL
+		ORDER BY p.version_id, p.order_index
+	),
+	dist AS (
+		SELECT s.version_id,
+		       2 * 6371 * asin(sqrt(
+		           power(sin(radians(s.lat - x.lat) / 2), 2) +
+		           cos(radians(x.lat)) * cos(radians(s.lat)) * power(sin(radians(s.lon - x.lon) / 2), 2)
+		       )) AS km
+		FROM start s, params x
+		WHERE s.lat BETWEEN x.lat - x.radius / 111.0 AND x.lat + x.radius / 111.0
+		  AND s.lon BETWEEN x.lon - x.radius / (111.0 * greatest(cos(radians(x.lat)), 0.01))
+		                AND x.lon + x.radius / (111.0 * greatest(cos(radians(x.lat)), 0.01))
+	)
+	SELECT ` + versionColumns + `, d.km
+	FROM dist d
+	JOIN route_versions rv ON rv.id = d.version_id
+	` + coverLateral + `
+	WHERE d.km <= $3
+	ORDER BY d.km
+	LIMIT $4`
+	rows, err := r.db.Query(ctx, q, lat, lon, radiusKm, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.NearbyRoute
+	for rows.Next() {
+		var n domain.NearbyRoute
+		v := &n.Route
+		if err := rows.Scan(
+			&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.Description,
+			&v.DurationMinutes, &v.LengthKm, &v.Theme, &v.Price, &v.City,
+			&v.Status, &v.PublishedAt, &v.SubmittedAt, &v.CreatedAt, &v.CoverMediaID,
+			&n.DistanceKm,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan route: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// Themes — тематики маршрутов каталога (для фильтра)
+func (r *RouteRepo) Themes(ctx context.Context) ([]string, error) {
 	const q = `
-	SELECT rv.id, rv.route_id, rv.version_number, rv.title, rv.description,
-	       COALESCE(rv.duration_minutes,0), COALESCE(rv.length_km,0),
-	       COALESCE(rv.theme,''), COALESCE(rv.price,0), rv.city,
-	       rv.created_at,
-	       COALESCE(m.url, '') AS cover_image_url
+	SELECT DISTINCT rv.theme
 	FROM route_versions rv
-	LEFT JOIN route_version_media rvm ON rvm.route_version_id = rv.id
-	LEFT JOIN media m ON m.id = rvm.media_id AND m.type = 'image'
-	WHERE rv.route_id = $1
+	JOIN routes r ON r.id = rv.route_id
+	WHERE ` + catalogFilter + ` AND COALESCE(rv.theme, '') <> ''
+	ORDER BY rv.theme`
+	rows, err := r.db.Query(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// GalleryMediaIDs — изображения версии для превью: сначала обложка, затем по display_order
+func (r *RouteRepo) GalleryMediaIDs(ctx context.Context, versionID int) ([]int64, error) {
+	const q = `
+	SELECT m.id
+	FROM route_version_media rvm
+	JOIN media m ON m.id = rvm.media_id AND m.type = 'image'
+	WHERE rvm.route_version_id = $1
+	ORDER BY rvm.is_cover DESC NULLS LAST, rvm.display_order NULLS LAST, rvm.media_id`
+	rows, err := r.db.Query(ctx, q, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// Details — карточка маршрута в каталоге: только опубликованные и видимые маршруты
+func (r *RouteRepo) Details(ctx context.Context, routeID int) (*models.RouteVersion, error) {
+	const q = versionSelect + `
+	WHERE rv.route_id = $1 AND ` + catalogFilter + `
 	ORDER BY rv.version_number DESC
 	LIMIT 1;
 	`
-	var v models.RouteVersion
-	if err := r.db.QueryRow(ctx, q, routeID).Scan(
-		&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.Description,
-		&v.DurationMinutes, &v.LengthKm, &v.Theme, &v.Price, &v.City,
-		&v.CreatedAt, &v.CoverImageURL,
-	); err != nil {
-		return nil, err
+	ver, err := scanVersion(r.db.QueryRow(ctx, q, routeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrRouteUnavailable
 	}
-	return &v, nil
+	return ver, err
+}
+
+// ActiveVersion — последняя опубликованная версия независимо от статуса маршрута.
+// Нужна покупателям архивных и скрытых маршрутов.
+func (r *RouteRepo) ActiveVersion(ctx context.Context, routeID int) (*models.RouteVersion, error) {
+	const q = versionSelect + `
+	WHERE rv.route_id = $1 AND rv.status = 'published'
+	ORDER BY rv.version_number DESC
+	LIMIT 1;
+	`
+	return scanVersion(r.db.QueryRow(ctx, q, routeID))
+}
+
+// LatestVersion — самая свежая версия в любом статусе (черновики для авторов)
+func (r *RouteRepo) LatestVersion(ctx context.Context, routeID int) (*models.RouteVersion, error) {
+	const q = versionSelect + `
+	WHERE rv.route_id = $1
+	ORDER BY rv.version_number DESC
+	LIMIT 1;
+	`
+	return scanVersion(r.db.QueryRow(ctx, q, routeID))
 }
 
 func (r *RouteRepo) VersionByID(ctx context.Context, versionID int) (*models.RouteVersion, error) {
-	const q = `
-	SELECT rv.id, rv.route_id, rv.version_number, rv.title, rv.description,
-	       COALESCE(rv.duration_minutes,0), COALESCE(rv.length_km,0),
-	       COALESCE(rv.theme,''), COALESCE(rv.price,0), rv.city,
-	       rv.created_at,
-	       COALESCE(m.url, '') AS cover_image_url
-	FROM route_versions rv
-	LEFT JOIN route_version_media rvm ON rvm.route_version_id = rv.id
-	LEFT JOIN media m ON m.id = rvm.media_id AND m.type = 'image'
-	WHERE rv.id = $1;
+	const q = versionSelect + `
+	WHERE rv.id = $1
+	LIMIT 1;
 	`
-	var v models.RouteVersion
-	if err := r.db.QueryRow(ctx, q, versionID).Scan(
-		&v.ID, &v.RouteID, &v.VersionNumber, &v.Title, &v.Description,
-		&v.DurationMinutes, &v.LengthKm, &v.Theme, &v.Price, &v.City,
-		&v.CreatedAt, &v.CoverImageURL,
+	return scanVersion(r.db.QueryRow(ctx, q, versionID))
+}
+
+func (r *RouteRepo) RouteByID(ctx context.Context, routeID int) (*models.Route, error) {
+	const q = `SELECT id, status::text, is_visible, COALESCE(created_by,0), created_at, updated_at
+	           FROM routes WHERE id=$1`
+	var rt models.Route
+	if err := r.db.QueryRow(ctx, q, routeID).Scan(
+		&rt.ID, &rt.Status, &rt.IsVisible, &rt.CreatedBy, &rt.CreatedAt, &rt.UpdatedAt,
 	); err != nil {
 		return nil, err
 	}
-	return &v, nil
+	return &rt, nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"strings"
 	"walki/internal/models"
@@ -14,27 +15,72 @@ type RouteRunRepo struct{ db *pgxpool.Pool }
 func NewRouteRunRepo(db *pgxpool.Pool) *RouteRunRepo { return &RouteRunRepo{db: db} }
 
 func (r *RouteRunRepo) FirstPoint(ctx context.Context, versionID int) (*models.RoutePoint, error) {
-	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, created_at
+	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, is_preview, created_at,
+                      COALESCE(arrival_instructions, '')
                FROM route_points WHERE version_id=$1 ORDER BY order_index ASC LIMIT 1`
 	var p models.RoutePoint
 	if err := r.db.QueryRow(ctx, q, versionID).
-		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.CreatedAt); err != nil {
+		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt, &p.ArrivalInstructions); err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
 func (r *RouteRunRepo) PointByIndex(ctx context.Context, versionID, idx int) (*models.RoutePoint, error) {
-	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, created_at
+	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, is_preview, created_at,
+                      COALESCE(arrival_instructions, '')
                FROM route_points WHERE version_id=$1 AND order_index=$2`
 	var p models.RoutePoint
 	if err := r.db.QueryRow(ctx, q, versionID, idx).
-		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.CreatedAt); err != nil {
+		Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt, &p.ArrivalInstructions); err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
+func (r *RouteRunRepo) Points(ctx context.Context, versionID int) ([]models.RoutePoint, error) {
+	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, is_preview, created_at,
+                      COALESCE(arrival_instructions, '')
+               FROM route_points WHERE version_id=$1 ORDER BY order_index ASC`
+	rows, err := r.db.Query(ctx, q, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.RoutePoint
+	for rows.Next() {
+		var p models.RoutePoint
+		if err := rows.Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt, &p.ArrivalInstructions); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// PreviewPoints — пробные точки версии в порядке маршрута
+func (r *RouteRunRepo) PreviewPoints(ctx context.Context, versionID int) ([]models.RoutePoint, error) {
+	const q = `SELECT id, version_id, order_index, title, description, latitude, longitude, is_preview, created_at,
+                      COALESCE(arrival_instructions, '')
+               FROM route_points WHERE version_id=$1 AND is_preview ORDER BY order_index ASC`
+	rows, err := r.db.Query(ctx, q, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.RoutePoint
+	for rows.Next() {
+		var p models.RoutePoint
+		if err := rows.Scan(&p.ID, &p.VersionID, &p.Idx, &p.Title, &p.Description, &p.Lat, &p.Lon, &p.IsPreview, &p.CreatedAt, &p.ArrivalInstructions); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
 func (r *RouteRunRepo) NextIndex(ctx context.Context, versionID, after int) (int, bool, error) {
 	const q = `SELECT order_index FROM route_points WHERE version_id=$1 AND order_index>$2 ORDER BY order_index ASC LIMIT 1`
 	var idx int
@@ -54,13 +100,21 @@ func (r *RouteRunRepo) PrevIndex(ctx context.Context, versionID, before int) (in
 	return idx, true, nil
 }
 
-func (r *RouteRunRepo) PointMediaIDs(ctx context.Context, pointID int) (photoIDs []int64, audioIDs []int64, err error) {
+// PointMediaIDs — фото точки и озвучка на языке lang; если её нет — оригинальная
+func (r *RouteRunRepo) PointMediaIDs(ctx context.Context, pointID int, lang string) (photoIDs []int64, audioIDs []int64, err error) {
 	const q = `
 SELECT m.id, m.type
 FROM route_point_media rpm
 JOIN media m ON m.id = rpm.media_id
-WHERE rpm.route_point_id = $1`
-	rows, err := r.db.Query(ctx, q, pointID)
+WHERE rpm.route_point_id = $1
+  AND (m.type <> 'audio' OR rpm.language IS NOT DISTINCT FROM (
+        SELECT a.language
+        FROM route_point_media a
+        JOIN media am ON am.id = a.media_id AND am.type = 'audio'
+        WHERE a.route_point_id = $1 AND (a.language = $2 OR a.language IS NULL)
+        ORDER BY a.language IS NULL
+        LIMIT 1))`
+	rows, err := r.db.Query(ctx, q, pointID, lang)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -90,11 +144,13 @@ func (r *RouteRunRepo) UpsertProgress(ctx context.Context, userID, routeID, vers
     VALUES ($1,$2,$3,$4)
     ON CONFLICT (user_id, version_id) DO UPDATE
       SET current_idx = EXCLUDED.current_idx,
-          finished_at = NULL`
+          finished_at = NULL,
+          last_activity_at = NOW()`
 	_, err := r.db.Exec(ctx, q, userID, routeID, versionID, idx)
 	return err
 }
 
+// GetProgress возвращает (nil, nil), если прогулка по версии не начата
 func (r *RouteRunRepo) GetProgress(ctx context.Context, userID, versionID int) (*models.RouteProgress, error) {
 	const q = `SELECT id, user_id, route_id, version_id, current_idx, started_at, finished_at,
 	                  content_msg_id, voice_msg_id
@@ -106,12 +162,35 @@ func (r *RouteRunRepo) GetProgress(ctx context.Context, userID, versionID int) (
 		&p.ID, &p.UserID, &p.RouteID, &p.VersionID, &p.CurrentIdx, &p.StartedAt, &p.FinishedAt,
 		&p.ContentMsgID, &p.VoiceMsgID,
 	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	return &p, nil
 }
 
+// DraftProgress — прогресс предпросмотра: по черновой версии маршрута
+func (r *RouteRunRepo) DraftProgress(ctx context.Context, userID, routeID int) (*models.RouteProgress, error) {
+	const q = `SELECT p.id, p.user_id, p.route_id, p.version_id, p.current_idx, p.started_at, p.finished_at,
+	                  p.content_msg_id, p.voice_msg_id
+	           FROM route_progress p
+	           JOIN route_versions rv ON rv.id = p.version_id AND rv.status = 'draft'
+	           WHERE p.user_id=$1 AND p.route_id=$2
+	           ORDER BY rv.version_number DESC
+	           LIMIT 1`
+
+	var p models.RouteProgress
+	if err := r.db.QueryRow(ctx, q, userID, routeID).Scan(
+		&p.ID, &p.UserID, &p.RouteID, &p.VersionID, &p.CurrentIdx, &p.StartedAt, &p.FinishedAt,
+		&p.ContentMsgID, &p.VoiceMsgID,
+	); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
 func (r *RouteRunRepo) Finish(ctx context.Context, userID, versionID int) error {
 	const q = `UPDATE route_progress SET finished_at=NOW() WHERE user_id=$1 AND version_id=$2`
 	ct, err := r.db.Exec(ctx, q, userID, versionID)
@@ -124,6 +203,33 @@ func (r *RouteRunRepo) Finish(ctx context.Context, userID, versionID int) error
 	return nil
 }
 
+// UpgradeWalk одной транзакцией закрепляет за активным заказом новую версию и,
+// если idx не nil, переносит на неё прогресс (message_id сохраняются, чтобы хендлер
+// мог убрать старые карточки; прежний прогресс по целевой версии удаляется).
+func (r *RouteRunRepo) UpgradeWalk(ctx context.Context, userID, routeID, fromVersionID, toVersionID int, idx *int) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		ct, err := tx.Exec(ctx, `UPDATE orders SET walk_version_id = $3 WHERE id = (`+qActiveOrder+`)`,
+			userID, routeID, toVersionID)
+		if err != nil {
+			return err
+		}
+		if ct.RowsAffected() == 0 {
+			return errors.New("order not found")
+		}
+		if idx == nil {
+			return nil
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM route_progress WHERE user_id=$1 AND version_id=$2`, userID, toVersionID); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE route_progress
+			SET version_id = $3, current_idx = $4, last_activity_at = NOW()
+			WHERE user_id = $1 AND version_id = $2`, userID, fromVersionID, toVersionID, *idx)
+		return err
+	})
+}
+
 // postgres/route_run_repo.go
 func (r *RouteRunRepo) UpdateMessageIDs(
 	ctx context.Context,
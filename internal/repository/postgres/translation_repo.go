@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"walki/internal/models"
+)
+
+type TranslationRepo struct{ db *pgxpool.Pool }
+
+func NewTranslationRepo(db *pgxpool.Pool) *TranslationRepo { return &TranslationRepo{db: db} }
+
+// Переводимые поля версии -> колонка route_version_translations
+var versionTranslationFields = map[string]string{
+	"title":       "title",
+	"description": "description",
+}
+
+// Versions — переводы версий на язык lang по version_id
+func (r *TranslationRepo) Versions(ctx context.Context, lang string, versionIDs []int) (map[int]models.VersionTranslation, error) {
+	const q = `
+	SELECT version_id, language, title, description
+	FROM route_version_translations
+	WHERE language = $1 AND version_id = ANY($2)`
+	rows, err := r.db.Query(ctx, q, lang, versionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]models.VersionTranslation)
+	for rows.Next() {
+		var t models.VersionTranslation
+		if err := rows.Scan(&t.VersionID, &t.Language, &t.Title, &t.Description); err != nil {
+			return nil, err
+		}
+		out[t.VersionID] = t
+	}
+	return out, rows.Err()
+}
+
+// Points — переводы точек на язык lang по point_id
+func (r *TranslationRepo) Points(ctx context.Context, lang string, pointIDs []int) (map[int]models.PointTranslation, error) {
+	const q = `
+	SELECT point_id, language, title, description, arrival_instructions
+	FROM route_point_translations
+	WHERE language = $1 AND point_id = ANY($2)`
+	rows, err := r.db.Query(ctx, q, lang, pointIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int]models.PointTranslation)
+	for rows.Next() {
+		var t models.PointTranslation
+		if err := rows.Scan(&t.PointID, &t.Language, &t.Title, &t.Description, &t.ArrivalInstructions); err != nil {
+			return nil, err
+		}
+		out[t.PointID] = t
+	}
+	return out, rows.Err()
+}
+
+// SetVersionField сохраняет одно поле перевода версии, остальные не трогает
+func (r *TranslationRepo) SetVersionField(ctx context.Context, versionID int, lang, field, value string) error {
+	col, ok := versionTranslationFields[field]
+	if !ok {
+		return fmt.Errorf("unknown translation field %q", field)
+	}
+	q := fmt.Sprintf(`
+	INSERT INTO route_version_translations (version_id, language, %[1]s)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (version_id, language) DO UPDATE SET %[1]s = EXCLUDED.%[1]s`, col)
+	_, err := r.db.Exec(ctx, q, versionID, lang, value)
+	return err
+}
+
+// SetPointText сохраняет перевод названия и рассказа точки
+func (r *TranslationRepo) SetPointText(ctx context.Context, pointID int, lang, title, description string) error {
+	const q = `
+	INSERT INTO route_point_translations (point_id, language, title, description)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (point_id, language) DO UPDATE
+	  SET title = EXCLUDED.title, description = EXCLUDED.description`
+	_, err := r.db.Exec(ctx, q, pointID, lang, title, description)
+	return err
+}
+
+// SetPointArrival сохраняет перевод подсказки «как дойти» к точке
+func (r *TranslationRepo) SetPointArrival(ctx context.Context, pointID int, lang, arrival string) error {
+	const q = `
+	INSERT INTO route_point_translations (point_id, language, arrival_instructions)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (point_id, language) DO UPDATE
+	  SET arrival_instructions = EXCLUDED.arrival_instructions`
+	_, err := r.db.Exec(ctx, q, pointID, lang, arrival)
+	return err
+}
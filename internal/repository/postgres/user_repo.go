@@ -11,25 +11,70 @@ type UserRepo struct{ db *pgxpool.Pool }
 
 func NewUserRepo(db *pgxpool.Pool) *UserRepo { return &UserRepo{db: db} }
 
-func (r *UserRepo) Upsert(ctx context.Context, u *models.User) error {
+func (r *UserRepo) Upsert(ctx context.Context, u *models.User) (bool, error) {
+	// xmax = 0 только у строки, вставленной этим запросом
 	const q = `
 	  INSERT INTO users (telegram_id, username, full_name, created_at, updated_at)
 	  VALUES ($1,$2,$3,NOW(),NOW())
 	  ON CONFLICT (telegram_id) DO UPDATE
 	    SET username=EXCLUDED.username,
 	        full_name=EXCLUDED.full_name,
-	        updated_at=NOW();
+	        updated_at=NOW()
+	  RETURNING id, referral_code, (xmax = 0);
 	`
-	_, err := r.db.Exec(ctx, q, u.TelegramID, u.Username, u.FullName)
+	var created bool
+	err := r.db.QueryRow(ctx, q, u.TelegramID, u.Username, u.FullName).Scan(&u.ID, &u.ReferralCode, &created)
+	return created, err
+}
+
+// SetReferrer привязывает пригласившего (по его реферальному коду) один раз;
+// false — привязка уже есть, код не найден или принадлежит самому пользователю
+func (r *UserRepo) SetReferrer(ctx context.Context, userID int, referralCode string) (bool, error) {
+	const q = `
+	  UPDATE users u SET referred_by = ref.id, updated_at = NOW()
+	  FROM users ref
+	  WHERE u.id = $1 AND u.referred_by IS NULL
+	    AND ref.referral_code = $2 AND ref.id <> u.id`
+	ct, err := r.db.Exec(ctx, q, userID, referralCode)
+	if err != nil {
+		return false, err
+	}
+	return ct.RowsAffected() > 0, nil
+}
+
+func (r *UserRepo) SetLanguage(ctx context.Context, userID int, lang string) error {
+	const q = `UPDATE users SET language = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Exec(ctx, q, userID, lang)
 	return err
 }
 
 func (r *UserRepo) ByTelegramID(ctx context.Context, tgID int64) (*models.User, error) {
-	const q = `SELECT id, telegram_id, username, full_name, created_at, updated_at FROM users WHERE telegram_id=$1`
+	const q = `SELECT id, telegram_id, COALESCE(username,''), COALESCE(full_name,''), role::text, COALESCE(language,''), referral_code, created_at, updated_at
+	           FROM users WHERE telegram_id=$1`
 	var u models.User
 	if err := r.db.QueryRow(ctx, q, tgID).
-		Scan(&u.ID, &u.TelegramID, &u.Username, &u.FullName, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		Scan(&u.ID, &u.TelegramID, &u.Username, &u.FullName, &u.Role, &u.Language, &u.ReferralCode, &u.CreatedAt, &u.UpdatedAt); err != nil {
 		return nil, err
 	}
 	return &u, nil
 }
+
+func (r *UserRepo) ByRole(ctx context.Context, role string) ([]models.User, error) {
+	const q = `SELECT id, telegram_id, COALESCE(username,''), COALESCE(full_name,''), role::text, COALESCE(language,''), referral_code, created_at, updated_at
+	           FROM users WHERE role = $1::user_role ORDER BY id`
+	rows, err := r.db.Query(ctx, q, role)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.TelegramID, &u.Username, &u.FullName, &u.Role, &u.Language, &u.ReferralCode, &u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,123 @@
+// Package scheduler — фоновая отправка запланированных уведомлений и уборка
+// устаревших данных.
+//
+// Очередь живёт в БД (scheduled_jobs), поэтому планировщик можно запускать
+// на каждом экземпляре бота: задания добавляются идемпотентно, а забираются
+// через SKIP LOCKED — одно уведомление уходит один раз. Уборка тоже
+// идемпотентна: лишний DELETE с другого экземпляра ничего не ломает.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"walki/internal/models"
+	"walki/internal/service"
+)
+
+// Параметры цикла
+const (
+	interval     = time.Minute
+	batchSize    = 50
+	cleanupEvery = time.Hour
+	payloadTTL   = 30 * 24 * time.Hour // длинные данные inline-кнопок (callback_payloads)
+)
+
+// Notifier отправляет уведомление пользователю
+type Notifier interface {
+	Notify(ctx context.Context, n *service.Notification) error
+}
+
+// PayloadCleaner удаляет устаревшие данные inline-кнопок
+type PayloadCleaner interface {
+	DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error)
+}
+
+type Scheduler struct {
+	svc      *service.NotificationService
+	out      Notifier
+	payloads PayloadCleaner
+
+	lastCleanup time.Time
+}
+
+func New(svc *service.NotificationService, out Notifier, payloads PayloadCleaner) *Scheduler {
+	return &Scheduler{svc: svc, out: out, payloads: payloads}
+}
+
+// Run раз в interval планирует и рассылает уведомления, пока не отменён ctx
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		s.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	if time.Since(s.lastCleanup) >= cleanupEvery {
+		s.cleanup(ctx)
+	}
+	if err := s.svc.Plan(ctx); err != nil {
+		log.Printf("scheduler: %v", err)
+	}
+	for ctx.Err() == nil {
+		jobs, err := s.svc.Claim(ctx, batchSize)
+		if err != nil {
+			log.Printf("scheduler: claim: %v", err)
+			return
+		}
+		for _, job := range jobs {
+			s.run(ctx, job)
+		}
+		if len(jobs) < batchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job models.Job) {
+	n, err := s.svc.Prepare(ctx, job, time.Now())
+	if err != nil {
+		log.Printf("scheduler: prepare job %d (%s): %v", job.ID, job.Kind, err)
+		s.failed(ctx, job, err)
+		return
+	}
+	if n == nil {
+		return // отложено или закрыто
+	}
+	if err := s.out.Notify(ctx, n); err != nil {
+		log.Printf("scheduler: notify job %d (%s): %v", job.ID, job.Kind, err)
+		s.failed(ctx, job, err)
+		return
+	}
+	if err := s.svc.Sent(ctx, job); err != nil {
+		log.Printf("scheduler: job %d: %v", job.ID, err)
+	}
+}
+
+// failed — повтор с паузой; после последней попытки задание закрывается
+func (s *Scheduler) failed(ctx context.Context, job models.Job, err error) {
+	if err := s.svc.Failed(ctx, job, err); err != nil {
+		log.Printf("scheduler: job %d: %v", job.ID, err)
+	}
+}
+
+// cleanup — раз в cleanupEvery: кнопки старше payloadTTL отвечают «кнопка устарела»
+func (s *Scheduler) cleanup(ctx context.Context) {
+	s.lastCleanup = time.Now()
+	n, err := s.payloads.DeleteExpired(ctx, payloadTTL)
+	if err != nil {
+		log.Printf("scheduler: cleanup callback payloads: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("scheduler: removed %d expired callback payloads", n)
+	}
+}
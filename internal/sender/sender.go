@@ -0,0 +1,271 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"walki/internal/ratelimit"
+)
+
+/*
+Sender — единая точка исходящих запросов к Telegram (реализует mux.Sender).
+
+  - глобальный лимит (~30 сообщений в секунду на бота) и лимит на чат
+    (~1 в секунду в личке, 20 в минуту в группах);
+  - 429 Too Many Requests: ждём retry_after и повторяем;
+  - 5xx и ошибки соединения: повтор с экспоненциальной паузой. Таймаут или
+    обрыв после отправки не повторяем — сообщение могло дойти, повтор бы его задвоил;
+  - Enqueue — очередь для несрочных сообщений (рассылки, напоминания):
+    они идут с меньшей скоростью, оставляя запас интерактивным ответам.
+*/
+
+// Лимиты Telegram Bot API
+const (
+	globalRate  = 30
+	globalBurst = 30
+	chatBurst   = 3
+	bulkRate    = 20 // доля глобального лимита для очереди
+
+	maxAttempts = 4
+	baseBackoff = 500 * time.Millisecond
+	maxBackoff  = 10 * time.Second
+	maxWait     = time.Minute // дольше retry_after не ждём, отдаём ошибку
+)
+
+var (
+	metrics      = expvar.NewMap("sender")
+	sentTotal    = new(expvar.Int)
+	retriesTotal = new(expvar.Int)
+	failedTotal  = new(expvar.Int)
+	queueDepth   = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("sent", sentTotal)
+	metrics.Set("retries", retriesTotal)
+	metrics.Set("failed", failedTotal)
+	metrics.Set("queue_depth", queueDepth)
+}
+
+// ErrQueueFull — очередь несрочных сообщений переполнена
+var ErrQueueFull = errors.New("sender queue is full")
+
+// ErrClosed — отправитель остановлен
+var ErrClosed = errors.New("sender is closed")
+
+type Sender struct {
+	api *tgbotapi.BotAPI
+
+	global *ratelimit.Bucket
+	chats  *ratelimit.Keyed[int64]
+	bulk   *ratelimit.Bucket
+
+	ctx    context.Context // отменяется при Close: прерывает ожидания
+	cancel context.CancelFunc
+
+	queue   chan tgbotapi.Chattable
+	mu      sync.RWMutex
+	closed  bool
+	drained chan struct{}
+}
+
+func New(api *tgbotapi.BotAPI, queueSize int) *Sender {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Sender{
+		api:    api,
+		global: ratelimit.NewBucket(globalRate, globalBurst),
+		chats: ratelimit.NewKeyed(func(chatID int64) *ratelimit.Bucket {
+			if chatID < 0 { // группы и каналы
+				return ratelimit.NewBucket(ratelimit.Every(3*time.Second), chatBurst)
+			}
+			return ratelimit.NewBucket(1, chatBurst)
+		}),
+		bulk:    ratelimit.NewBucket(bulkRate, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+		queue:   make(chan tgbotapi.Chattable, queueSize),
+		drained: make(chan struct{}),
+	}
+	go s.worker()
+	return s
+}
+
+// Send отправляет сообщение с учётом лимитов и повторами
+func (s *Sender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	err := s.do(c, func() error {
+		var err error
+		msg, err = s.api.Send(c)
+		return err
+	})
+	return msg, err
+}
+
+// Request — служебные запросы (ответ на callback, удаление, редактирование).
+// Редактирование и удаление Telegram считает в лимит чата, поэтому лимит чата
+// применяется ко всем запросам с ChatID; ответы на callback и inline его не имеют.
+func (s *Sender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	var resp *tgbotapi.APIResponse
+	err := s.do(c, func() error {
+		var err error
+		resp, err = s.api.Request(c)
+		return err
+	})
+	return resp, err
+}
+
+// GetFileDirectURL — ссылка на файл из Telegram (для импорта медиа)
+func (s *Sender) GetFileDirectURL(fileID string) (string, error) {
+	if err := s.global.Wait(s.ctx); err != nil {
+		return "", err
+	}
+	return s.api.GetFileDirectURL(fileID)
+}
+
+// Self — аккаунт бота (username нужен для ссылок t.me)
+func (s *Sender) Self() tgbotapi.User { return s.api.Self }
+
+// Enqueue ставит несрочное сообщение в очередь, не блокируя вызывающего
+func (s *Sender) Enqueue(c tgbotapi.Chattable) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrClosed
+	}
+	select {
+	case s.queue <- c:
+		queueDepth.Add(1)
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close перестаёт принимать сообщения в очередь и отправляет оставшиеся, пока не истёк ctx
+func (s *Sender) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	defer s.cancel()
+	select {
+	case <-s.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Sender) worker() {
+	defer close(s.drained)
+	for c := range s.queue {
+		queueDepth.Add(-1)
+		if err := s.bulk.Wait(s.ctx); err != nil {
+			failedTotal.Add(1)
+			continue
+		}
+		if _, err := s.Send(c); err != nil {
+			log.Printf("sender: queued message: %v", err)
+		}
+	}
+}
+
+// do выполняет запрос: ждёт лимиты, повторяет при 429/5xx и ошибках соединения
+func (s *Sender) do(c tgbotapi.Chattable, call func() error) error {
+	chatID, hasChat := chatIDOf(c)
+	backoff := baseBackoff
+
+	for attempt := 1; ; attempt++ {
+		if hasChat {
+			if err := s.chats.Wait(s.ctx, chatID); err != nil {
+				return err
+			}
+		}
+		if err := s.global.Wait(s.ctx); err != nil {
+			return err
+		}
+
+		err := call()
+		if err == nil {
+			sentTotal.Add(1)
+			return nil
+		}
+
+		wait, retry := retryDelay(err, backoff)
+		if !retry || attempt >= maxAttempts || wait > maxWait {
+			failedTotal.Add(1)
+			return err
+		}
+		retriesTotal.Add(1)
+		log.Printf("sender: chat=%d attempt %d failed (%v), retry in %s", chatID, attempt, err, wait)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-s.ctx.Done():
+			t.Stop()
+			return err
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// retryDelay решает, повторять ли запрос и через сколько
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == 429:
+			if apiErr.RetryAfter > 0 {
+				return time.Duration(apiErr.RetryAfter) * time.Second, true
+			}
+			return backoff, true
+		case apiErr.Code >= 500:
+			return backoff, true
+		}
+		// 400/403 и прочие — повтор не поможет
+		return 0, false
+	}
+	if notSent(err) {
+		return backoff, true
+	}
+	// таймаут, обрыв, не-JSON ответ прокси: запрос мог выполниться
+	return 0, false
+}
+
+// notSent — запрос точно не ушёл в Telegram: не удалось соединиться
+func notSent(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
+
+// chatIDOf достаёт ChatID из конфига (у сообщений он во встроенном BaseChat,
+// у редактирования — в BaseEdit)
+func chatIDOf(c tgbotapi.Chattable) (int64, bool) {
+	v := reflect.ValueOf(c)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	f := v.FieldByName("ChatID")
+	if !f.IsValid() || f.Kind() != reflect.Int64 || f.Int() == 0 {
+		return 0, false
+	}
+	return f.Int(), true
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+var ErrInvalidValue = errors.New("invalid field value")
+
+// Поля метаданных версии, редактируемые из бота
+const (
+	FieldTitle       = "title"
+	FieldCity        = "city"
+	FieldDescription = "description"
+	FieldTheme       = "theme"
+	FieldDuration    = "duration_minutes"
+	FieldLength      = "length_km"
+	FieldPrice       = "price"
+)
+
+// AuthoringService — создание и редактирование маршрутов гидами прямо в боте.
+// Редактировать можно только черновик; опубликованная версия копируется в новый черновик.
+type AuthoringService struct {
+	repo   repository.AuthoringRepository
+	routes repository.RouteRepository
+	runs   repository.RouteRunRepository
+	trans  repository.TranslationRepository
+}
+
+func NewAuthoringService(a repository.AuthoringRepository, r repository.RouteRepository, run repository.RouteRunRepository, tr repository.TranslationRepository) *AuthoringService {
+	return &AuthoringService{repo: a, routes: r, runs: run, trans: tr}
+}
+
+// Translation — перевод черновика на язык lang: тексты версии и точек
+type Translation struct {
+	Lang    string
+	Version models.VersionTranslation
+	Points  []models.RoutePoint // оригинал, в порядке маршрута
+	Texts   map[int]models.PointTranslation
+}
+
+func (s *AuthoringService) MyRoutes(ctx context.Context, actor *models.User) ([]models.RouteVersion, error) {
+	if !actor.IsAuthor() {
+		return nil, ErrForbidden
+	}
+	return s.repo.RoutesByAuthor(ctx, actor.ID)
+}
+
+// CreateRoute создаёт маршрут и возвращает его черновую версию
+func (s *AuthoringService) CreateRoute(ctx context.Context, actor *models.User, title string) (*models.RouteVersion, error) {
+	if !actor.IsAuthor() {
+		return nil, ErrForbidden
+	}
+	title = strings.TrimSpace(title)
+	if title == "" || len([]rune(title)) > 255 {
+		return nil, ErrInvalidValue
+	}
+	_, versionID, err := s.repo.CreateRoute(ctx, actor.ID, title)
+	if err != nil {
+		return nil, fmt.Errorf("create route: %w", err)
+	}
+	return s.routes.VersionByID(ctx, versionID)
+}
+
+// EditRoute открывает маршрут на редактирование: возвращает (при необходимости создаёт) черновик
+func (s *AuthoringService) EditRoute(ctx context.Context, actor *models.User, routeID int) (*models.RouteVersion, error) {
+	if err := s.ensureOwner(ctx, actor, routeID); err != nil {
+		return nil, err
+	}
+	versionID, err := s.repo.EnsureDraftVersion(ctx, routeID)
+	if err != nil {
+		return nil, fmt.Errorf("ensure draft: %w", err)
+	}
+	return s.routes.VersionByID(ctx, versionID)
+}
+
+// Draft — черновая версия с проверкой прав
+func (s *AuthoringService) Draft(ctx context.Context, actor *models.User, versionID int) (*models.RouteVersion, error) {
+	ver, err := s.routes.VersionByID(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("get version: %w", err)
+	}
+	if err := s.ensureOwner(ctx, actor, ver.RouteID); err != nil {
+		return nil, err
+	}
+	if ver.Status != models.RouteStatusDraft {
+		return nil, ErrInvalidTransition
+	}
+	return ver, nil
+}
+
+// SetField валидирует ввод автора и сохраняет поле черновика
+func (s *AuthoringService) SetField(ctx context.Context, actor *models.User, versionID int, field, raw string) error {
+	if _, err := s.Draft(ctx, actor, versionID); err != nil {
+		return err
+	}
+	raw = strings.TrimSpace(raw)
+	var value any
+	switch field {
+	case FieldTitle, FieldCity, FieldTheme:
+		limit := 100 // city, theme: VARCHAR(100)
+		if field == FieldTitle {
+			limit = 255
+		}
+		if raw == "" || len([]rune(raw)) > limit {
+			return ErrInvalidValue
+		}
+		value = raw
+	case FieldDescription:
+		if raw == "" {
+			return ErrInvalidValue
+		}
+		value = raw
+	case FieldDuration:
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return ErrInvalidValue
+		}
+		value = n
+	case FieldLength, FieldPrice:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", "."), 64)
+		if err != nil || f < 0 || field == FieldLength && f >= 1000 {
+			return ErrInvalidValue
+		}
+		value = f
+	default:
+		return ErrInvalidValue
+	}
+	return s.repo.UpdateVersionField(ctx, versionID, field, value)
+}
+
+func (s *AuthoringService) Points(ctx context.Context, actor *models.User, versionID int) ([]models.RoutePoint, error) {
+	if _, err := s.Draft(ctx, actor, versionID); err != nil {
+		return nil, err
+	}
+	return s.runs.Points(ctx, versionID)
+}
+
+// AddPoint добавляет точку в конец маршрута по присланной геопозиции;
+// title — временное название на языке автора
+func (s *AuthoringService) AddPoint(ctx context.Context, actor *models.User, versionID int, title string, lat, lon float64) (*models.RoutePoint, error) {
+	if _, err := s.Draft(ctx, actor, versionID); err != nil {
+		return nil, err
+	}
+	return s.repo.AddPoint(ctx, versionID, title, lat, lon)
+}
+
+// SetPointText: первая строка — название, остальное — описание
+func (s *AuthoringService) SetPointText(ctx context.Context, actor *models.User, pointID int, text string) error {
+	if _, err := s.draftPoint(ctx, actor, pointID); err != nil {
+		return err
+	}
+	title, desc, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(title)
+	if title == "" || len([]rune(title)) > 255 {
+		return ErrInvalidValue
+	}
+	return s.repo.UpdatePointText(ctx, pointID, title, strings.TrimSpace(desc))
+}
+
+// TogglePreview делает точку пробной или снимает отметку; возвращает версию точки
+func (s *AuthoringService) TogglePreview(ctx context.Context, actor *models.User, pointID int) (int, error) {
+	p, err := s.draftPoint(ctx, actor, pointID)
+	if err != nil {
+		return 0, err
+	}
+	return p.VersionID, s.repo.SetPointPreview(ctx, pointID, !p.IsPreview)
+}
+
+func (s *AuthoringService) AttachMedia(ctx context.Context, actor *models.User, pointID int, mediaID int64) error {
+	if _, err := s.draftPoint(ctx, actor, pointID); err != nil {
+		return err
+	}
+	return s.repo.AttachPointMedia(ctx, pointID, mediaID, "")
+}
+
+// Translation — текущий перевод черновика на lang
+func (s *AuthoringService) Translation(ctx context.Context, actor *models.User, versionID int, lang string) (*Translation, error) {
+	if err := checkTranslationLang(lang); err != nil {
+		return nil, err
+	}
+	points, err := s.Points(ctx, actor, versionID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := s.trans.Versions(ctx, lang, []int{versionID})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(points))
+	for i, p := range points {
+		ids[i] = p.ID
+	}
+	texts, err := s.trans.Points(ctx, lang, ids)
+	if err != nil {
+		return nil, err
+	}
+	return &Translation{Lang: lang, Version: versions[versionID], Points: points, Texts: texts}, nil
+}
+
+// SetTranslationField сохраняет перевод названия или описания черновика
+func (s *AuthoringService) SetTranslationField(ctx context.Context, actor *models.User, versionID int, lang, field, raw string) error {
+	if err := checkTranslationLang(lang); err != nil {
+		return err
+	}
+	if _, err := s.Draft(ctx, actor, versionID); err != nil {
+		return err
+	}
+	raw = strings.TrimSpace(raw)
+	switch field {
+	case FieldTitle:
+		if raw == "" || len([]rune(raw)) > 255 {
+			return ErrInvalidValue
+		}
+	case FieldDescription:
+		if raw == "" {
+			return ErrInvalidValue
+		}
+	default:
+		return ErrInvalidValue
+	}
+	return s.trans.SetVersionField(ctx, versionID, lang, field, raw)
+}
+
+// SetPointTranslation — перевод текста точки в формате SetPointText
+func (s *AuthoringService) SetPointTranslation(ctx context.Context, actor *models.User, pointID int, lang, text string) error {
+	if err := checkTranslationLang(lang); err != nil {
+		return err
+	}
+	if _, err := s.draftPoint(ctx, actor, pointID); err != nil {
+		return err
+	}
+	title, desc, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	title = strings.TrimSpace(title)
+	if title == "" || len([]rune(title)) > 255 {
+		return ErrInvalidValue
+	}
+	return s.trans.SetPointText(ctx, pointID, lang, title, strings.TrimSpace(desc))
+}
+
+// SetPointArrivalTranslation — перевод подсказки «как дойти» к точке
+func (s *AuthoringService) SetPointArrivalTranslation(ctx context.Context, actor *models.User, pointID int, lang, text string) error {
+	if err := checkTranslationLang(lang); err != nil {
+		return err
+	}
+	if _, err := s.draftPoint(ctx, actor, pointID); err != nil {
+		return err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrInvalidValue
+	}
+	return s.trans.SetPointArrival(ctx, pointID, lang, text)
+}
+
+// AttachTranslatedAudio привязывает к точке озвучку на языке перевода
+func (s *AuthoringService) AttachTranslatedAudio(ctx context.Context, actor *models.User, pointID int, lang string, mediaID int64) error {
+	if err := checkTranslationLang(lang); err != nil {
+		return err
+	}
+	if _, err := s.draftPoint(ctx, actor, pointID); err != nil {
+		return err
+	}
+	return s.repo.AttachPointMedia(ctx, pointID, mediaID, lang)
+}
+
+// checkTranslationLang: переводить можно только на поддерживаемый язык, кроме языка оригинала
+func checkTranslationLang(lang string) error {
+	if lang == i18n.Default || !i18n.Supported(lang) {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// MovePoint возвращает версию точки, чтобы UI перерисовал список
+func (s *AuthoringService) MovePoint(ctx context.Context, actor *models.User, pointID, delta int) (int, error) {
+	p, err := s.draftPoint(ctx, actor, pointID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.repo.MovePoint(ctx, pointID, delta); err != nil {
+		return 0, err
+	}
+	return p.VersionID, nil
+}
+
+func (s *AuthoringService) DeletePoint(ctx context.Context, actor *models.User, pointID int) (int, error) {
+	p, err := s.draftPoint(ctx, actor, pointID)
+	if err != nil {
+		return 0, err
+	}
+	return p.VersionID, s.repo.DeletePoint(ctx, pointID)
+}
+
+// Submit отправляет черновик на публикацию; пустые маршруты не принимаем
+func (s *AuthoringService) Submit(ctx context.Context, actor *models.User, versionID int) (*models.RouteVersion, error) {
+	ver, err := s.Draft(ctx, actor, versionID)
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.runs.Points(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 || ver.City == "" || ver.Description == "" {
+		return nil, ErrInvalidValue
+	}
+	if err := s.repo.SubmitVersion(ctx, versionID, actor.ID); err != nil {
+		return nil, fmt.Errorf("submit version: %w", err)
+	}
+	return ver, nil
+}
+
+func (s *AuthoringService) draftPoint(ctx context.Context, actor *models.User, pointID int) (*models.RoutePoint, error) {
+	p, err := s.repo.PointByID(ctx, pointID)
+	if err != nil {
+		return nil, fmt.Errorf("get point: %w", err)
+	}
+	if _, err := s.Draft(ctx, actor, p.VersionID); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (s *AuthoringService) ensureOwner(ctx context.Context, actor *models.User, routeID int) error {
+	if !actor.IsAuthor() {
+		return ErrForbidden
+	}
+	if actor.IsAdmin() {
+		return nil
+	}
+	rt, err := s.routes.RouteByID(ctx, routeID)
+	if err != nil {
+		return fmt.Errorf("get route: %w", err)
+	}
+	if rt.CreatedBy != actor.ID {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+
+	"walki/internal/domain"
+	"walki/internal/i18n"
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+// contentLocalizer подставляет в маршруты и точки переводы на язык из контекста
+// (i18n.From). Оригинал написан на языке по умолчанию: для него и для пустых
+// полей перевода остаётся исходный текст.
+type contentLocalizer struct {
+	repo repository.TranslationRepository
+}
+
+// lang — язык перевода; "" — нужен оригинал
+func (c contentLocalizer) lang(ctx context.Context) string {
+	if lang := i18n.From(ctx).Lang(); lang != i18n.Default {
+		return lang
+	}
+	return ""
+}
+
+// Versions переводит версии на месте
+func (c contentLocalizer) Versions(ctx context.Context, vs []models.RouteVersion) error {
+	lang := c.lang(ctx)
+	if lang == "" || len(vs) == 0 {
+		return nil
+	}
+	ids := make([]int, len(vs))
+	for i := range vs {
+		ids[i] = vs[i].ID
+	}
+	tr, err := c.repo.Versions(ctx, lang, ids)
+	if err != nil {
+		return err
+	}
+	for i := range vs {
+		if t, ok := tr[vs[i].ID]; ok {
+			applyVersion(&vs[i], t)
+		}
+	}
+	return nil
+}
+
+func (c contentLocalizer) Version(ctx context.Context, v *models.RouteVersion) error {
+	if v == nil {
+		return nil
+	}
+	vs := []models.RouteVersion{*v}
+	if err := c.Versions(ctx, vs); err != nil {
+		return err
+	}
+	*v = vs[0]
+	return nil
+}
+
+// Orders переводит названия маршрутов в списке заказов
+func (c contentLocalizer) Orders(ctx context.Context, orders []domain.OrderSummary) error {
+	lang := c.lang(ctx)
+	if lang == "" || len(orders) == 0 {
+		return nil
+	}
+	ids := make([]int, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].VersionID
+	}
+	tr, err := c.repo.Versions(ctx, lang, ids)
+	if err != nil {
+		return err
+	}
+	for i := range orders {
+		if t := tr[orders[i].VersionID]; t.Title != "" {
+			orders[i].RouteTitle = t.Title
+		}
+	}
+	return nil
+}
+
+// Point переводит точку на месте
+func (c contentLocalizer) Point(ctx context.Context, p *models.RoutePoint) error {
+	lang := c.lang(ctx)
+	if lang == "" || p == nil {
+		return nil
+	}
+	tr, err := c.repo.Points(ctx, lang, []int{p.ID})
+	if err != nil {
+		return err
+	}
+	if t, ok := tr[p.ID]; ok {
+		applyPoint(p, t)
+	}
+	return nil
+}
+
+func applyVersion(v *models.RouteVersion, t models.VersionTranslation) {
+	if t.Title != "" {
+		v.Title = t.Title
+	}
+	if t.Description != "" {
+		v.Description = t.Description
+	}
+}
+
+func applyPoint(p *models.RoutePoint, t models.PointTranslation) {
+	if t.Title != "" {
+		p.Title = t.Title
+	}
+	if t.Description != "" {
+		p.Description = t.Description
+	}
+	if t.ArrivalInstructions != "" {
+		p.ArrivalInstructions = t.ArrivalInstructions
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+// Проверка бана идёт на каждый апдейт, поэтому результат кэшируем ненадолго
+const banCacheTTL = 30 * time.Second
+
+// Ограничения на срок блокировки из бота
+const (
+	DefaultBanDuration = 24 * time.Hour
+	MaxBanDuration     = 365 * 24 * time.Hour
+)
+
+// ModerationService — временные блокировки пользователей админами
+type ModerationService struct {
+	repo repository.BanRepository
+
+	mu    sync.Mutex
+	cache map[int64]banCacheEntry
+}
+
+type banCacheEntry struct {
+	ban     *models.UserBan
+	checked time.Time
+}
+
+func NewModerationService(repo repository.BanRepository) *ModerationService {
+	return &ModerationService{repo: repo, cache: map[int64]banCacheEntry{}}
+}
+
+func (s *ModerationService) Ban(ctx context.Context, actor *models.User, telegramID int64, d time.Duration, reason string) (*models.UserBan, error) {
+	if !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+	if telegramID == 0 || telegramID == actor.TelegramID || d <= 0 || d > MaxBanDuration {
+		return nil, ErrInvalidValue
+	}
+	b := &models.UserBan{
+		TelegramID: telegramID,
+		Until:      time.Now().Add(d),
+		Reason:     reason,
+		BannedBy:   &actor.ID,
+	}
+	if err := s.repo.Ban(ctx, b); err != nil {
+		return nil, err
+	}
+	s.remember(telegramID, b)
+	return b, nil
+}
+
+// Unban возвращает false, если пользователь не был заблокирован
+func (s *ModerationService) Unban(ctx context.Context, actor *models.User, telegramID int64) (bool, error) {
+	if !actor.IsAdmin() {
+		return false, ErrForbidden
+	}
+	ok, err := s.repo.Unban(ctx, telegramID)
+	if err != nil {
+		return false, err
+	}
+	s.remember(telegramID, nil)
+	return ok, nil
+}
+
+// ActiveBan — действующая блокировка пользователя или nil
+func (s *ModerationService) ActiveBan(ctx context.Context, telegramID int64) (*models.UserBan, error) {
+	now := time.Now()
+	s.mu.Lock()
+	e, ok := s.cache[telegramID]
+	s.mu.Unlock()
+	if ok && now.Sub(e.checked) < banCacheTTL {
+		if e.ban != nil && now.After(e.ban.Until) {
+			return nil, nil
+		}
+		return e.ban, nil
+	}
+
+	b, err := s.repo.Active(ctx, telegramID)
+	if err != nil {
+		return nil, err
+	}
+	s.remember(telegramID, b)
+	return b, nil
+}
+
+func (s *ModerationService) remember(telegramID int64, b *models.UserBan) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if len(s.cache) > 10000 {
+		for id, e := range s.cache {
+			if now.Sub(e.checked) >= banCacheTTL {
+				delete(s.cache, id)
+			}
+		}
+	}
+	s.cache[telegramID] = banCacheEntry{ban: b, checked: now}
+}
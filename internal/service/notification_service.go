@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"walki/internal/domain"
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+/*
+Запланированные уведомления: напоминание об окончании доступа, о брошенной
+прогулке и просьба оценить пройденный маршрут.
+
+Plan находит поводы и добавляет задания в очередь, Claim забирает наступившие.
+Актуальность, настройки пользователя и тихие часы проверяются в Prepare —
+перед самой отправкой, а не при планировании.
+*/
+
+// Параметры уведомлений
+const (
+	nudgeIdle           = 24 * time.Hour // прогулка без действий — напоминаем
+	reviewDelay         = time.Hour      // после завершения прогулки — просим оценку
+	jobLease            = 5 * time.Minute
+	jobMaxAttempts      = 5
+	jobRetryBackoff     = 5 * time.Minute
+	defaultExpiryNotice = 3 // дней до окончания доступа
+)
+
+// QuietPresets — варианты тихих часов в настройках (по кругу, затем «выключены»)
+var QuietPresets = [][2]int{{22, 9}, {23, 8}, {0, 10}}
+
+// Notification — уведомление к отправке
+type Notification struct {
+	Job    models.Job
+	Target domain.JobTarget
+}
+
+type NotificationService struct {
+	repo       repository.NotificationRepository
+	loc        *time.Location // часовой пояс тихих часов
+	expiryDays int
+}
+
+// NewNotificationService: loc — часовой пояс тихих часов, expiryDays — за сколько дней
+// предупреждать об окончании доступа (0 — по умолчанию)
+func NewNotificationService(repo repository.NotificationRepository, loc *time.Location, expiryDays int) *NotificationService {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if expiryDays <= 0 {
+		expiryDays = defaultExpiryNotice
+	}
+	return &NotificationService{repo: repo, loc: loc, expiryDays: expiryDays}
+}
+
+// Location — часовой пояс тихих часов
+func (s *NotificationService) Location() *time.Location { return s.loc }
+
+// === Настройки пользователя
+
+func (s *NotificationService) Settings(ctx context.Context, userID int) (*models.NotificationSettings, error) {
+	return s.repo.Settings(ctx, userID)
+}
+
+// Toggle включает или выключает вид уведомлений
+func (s *NotificationService) Toggle(ctx context.Context, userID int, kind string) error {
+	st, err := s.repo.Settings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case models.JobAccessExpiry:
+		st.Reminders = !st.Reminders
+	case models.JobProgressNudge:
+		st.Nudges = !st.Nudges
+	case models.JobReviewRequest:
+		st.Reviews = !st.Reviews
+	default:
+		return ErrInvalidValue
+	}
+	return s.repo.SaveSettings(ctx, st)
+}
+
+// CycleQuietHours переключает тихие часы на следующий вариант из QuietPresets
+func (s *NotificationService) CycleQuietHours(ctx context.Context, userID int) error {
+	st, err := s.repo.Settings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	next := 0
+	if st.QuietFrom != nil && st.QuietTo != nil {
+		next = len(QuietPresets) // незнакомый вариант — выключаем
+		for i, p := range QuietPresets {
+			if p[0] == *st.QuietFrom && p[1] == *st.QuietTo {
+				next = i + 1
+			}
+		}
+	}
+	if next < len(QuietPresets) {
+		from, to := QuietPresets[next][0], QuietPresets[next][1]
+		st.QuietFrom, st.QuietTo = &from, &to
+	} else {
+		st.QuietFrom, st.QuietTo = nil, nil
+	}
+	return s.repo.SaveSettings(ctx, st)
+}
+
+// === Очередь
+
+// Plan добавляет в очередь новые поводы для уведомлений
+func (s *NotificationService) Plan(ctx context.Context) error {
+	if _, err := s.repo.PlanAccessExpiry(ctx, time.Duration(s.expiryDays)*24*time.Hour); err != nil {
+		return fmt.Errorf("plan access expiry: %w", err)
+	}
+	if _, err := s.repo.PlanProgressNudges(ctx, nudgeIdle); err != nil {
+		return fmt.Errorf("plan progress nudges: %w", err)
+	}
+	if _, err := s.repo.PlanReviewRequests(ctx, reviewDelay); err != nil {
+		return fmt.Errorf("plan review requests: %w", err)
+	}
+	return nil
+}
+
+// Claim забирает до limit наступивших заданий; другие экземпляры их не получат
+func (s *NotificationService) Claim(ctx context.Context, limit int) ([]models.Job, error) {
+	return s.repo.Claim(ctx, limit, jobLease)
+}
+
+// Prepare проверяет задание перед отправкой. nil — отправлять нечего: задание
+// отложено (тихие часы, пользователь вернулся к прогулке) или закрыто
+// (неактуально, вид уведомлений выключен, неизвестный вид).
+// Ошибку вызывающий передаёт в Failed, как и ошибку отправки.
+func (s *NotificationService) Prepare(ctx context.Context, job models.Job, now time.Time) (*Notification, error) {
+	switch job.Kind {
+	case models.JobAccessExpiry, models.JobProgressNudge, models.JobReviewRequest:
+	default:
+		// повтор не поможет
+		return nil, s.repo.Done(ctx, job.ID, "unknown kind")
+	}
+	target, err := s.repo.Target(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, s.repo.Done(ctx, job.ID, "obsolete")
+	}
+	// пользователь вернулся к прогулке — напомним, если снова пропадёт
+	if job.Kind == models.JobProgressNudge {
+		if target.Idle < nudgeIdle {
+			return nil, s.repo.Postpone(ctx, job.ID, nudgeIdle-target.Idle)
+		}
+	}
+
+	st, err := s.repo.Settings(ctx, job.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !st.Enabled(job.Kind) {
+		return nil, s.repo.Done(ctx, job.ID, "disabled by user")
+	}
+	if st.Quiet(now.In(s.loc).Hour()) {
+		return nil, s.repo.Postpone(ctx, job.ID, s.quietEnd(st, now).Sub(now))
+	}
+	return &Notification{Job: job, Target: *target}, nil
+}
+
+// quietEnd — ближайший конец тихих часов после now
+func (s *NotificationService) quietEnd(st *models.NotificationSettings, now time.Time) time.Time {
+	local := now.In(s.loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), *st.QuietTo, 0, 0, 0, s.loc)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// Sent закрывает отправленное задание
+func (s *NotificationService) Sent(ctx context.Context, job models.Job) error {
+	return s.repo.Done(ctx, job.ID, "")
+}
+
+// Failed — подготовка или отправка не удалась: повтор с растущей паузой, после jobMaxAttempts — закрываем
+func (s *NotificationService) Failed(ctx context.Context, job models.Job, sendErr error) error {
+	if job.Attempts >= jobMaxAttempts {
+		return s.repo.Done(ctx, job.ID, sendErr.Error())
+	}
+	backoff := jobRetryBackoff * time.Duration(1<<max(job.Attempts-1, 0))
+	return s.repo.Retry(ctx, job.ID, backoff, sendErr.Error())
+}
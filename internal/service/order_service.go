@@ -2,24 +2,35 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
+	"walki/internal/models"
 	"walki/internal/repository"
 )
 
+var (
+	ErrPromoNotFound = errors.New("promo code not found")
+	ErrPromoExpired  = errors.New("promo code expired or exhausted")
+)
+
 type OrderService struct {
 	orders repository.OrderRepository
 	routes repository.RouteRepository
+	promos repository.PromoRepository
 }
 
-func NewOrderService(o repository.OrderRepository, r repository.RouteRepository) *OrderService {
-	return &OrderService{orders: o, routes: r}
+func NewOrderService(o repository.OrderRepository, r repository.RouteRepository, p repository.PromoRepository) *OrderService {
+	return &OrderService{orders: o, routes: r, promos: p}
 }
 
 type PurchaseResult struct {
 	VersionID    int
 	AccessExpiry *time.Time
 	Amount       float64
+	// PromoCode и Discount заполнены, если при покупке списан промокод
+	PromoCode string
+	Discount  float64
 }
 
 func (s *OrderService) Purchase(ctx context.Context, userID, routeID int) (*PurchaseResult, error) {
@@ -27,9 +38,44 @@ func (s *OrderService) Purchase(ctx context.Context, userID, routeID int) (*Purc
 	if err != nil {
 		return nil, fmt.Errorf("get route details: %w", err)
 	}
-	verID, expiry, err := s.orders.Create(ctx, userID, routeID, route.Price)
+
+	res := &PurchaseResult{Amount: route.Price}
+	promo, err := s.promos.Pending(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get pending promo: %w", err)
+	}
+	if promo.Usable(time.Now()) && route.Price > 0 {
+		res.PromoCode = promo.Code
+		res.Amount = promo.Apply(route.Price)
+		res.Discount = route.Price - res.Amount
+	}
+
+	res.VersionID, res.AccessExpiry, err = s.orders.Create(ctx, userID, routeID, res.Amount, res.PromoCode)
+	if errors.Is(err, repository.ErrPromoUnavailable) {
+		// промокод закончился между проверкой и оплатой — продаём по полной цене
+		res = &PurchaseResult{Amount: route.Price}
+		res.VersionID, res.AccessExpiry, err = s.orders.Create(ctx, userID, routeID, res.Amount, "")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("create order: %w", err)
 	}
-	return &PurchaseResult{VersionID: verID, AccessExpiry: expiry, Amount: route.Price}, nil
+	return res, nil
+}
+
+// ActivatePromo сохраняет промокод до следующей покупки пользователя
+func (s *OrderService) ActivatePromo(ctx context.Context, userID int, code string) (*models.PromoCode, error) {
+	promo, err := s.promos.ByCode(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("get promo: %w", err)
+	}
+	if promo == nil {
+		return nil, ErrPromoNotFound
+	}
+	if !promo.Usable(time.Now()) {
+		return nil, ErrPromoExpired
+	}
+	if err := s.promos.Activate(ctx, userID, promo.Code); err != nil {
+		return nil, fmt.Errorf("activate promo: %w", err)
+	}
+	return promo, nil
 }
@@ -8,20 +8,103 @@ import (
 )
 
 type ProfileService struct {
-	orders repository.OrderRepository
-	routes repository.RouteRepository
+	orders    repository.OrderRepository
+	routes    repository.RouteRepository
+	favorites repository.FavoriteRepository
+	reviews   repository.ReviewRepository
+	content   contentLocalizer
 }
 
-func NewProfileService(o repository.OrderRepository, r repository.RouteRepository) *ProfileService {
-	return &ProfileService{orders: o, routes: r}
+func NewProfileService(o repository.OrderRepository, r repository.RouteRepository, f repository.FavoriteRepository, rv repository.ReviewRepository, tr repository.TranslationRepository) *ProfileService {
+	return &ProfileService{orders: o, routes: r, favorites: f, reviews: rv, content: contentLocalizer{tr}}
+}
+
+// FavoriteNotice — новость об избранном маршруте для рассылки подписчикам
+type FavoriteNotice struct {
+	Version     *models.RouteVersion
+	OldPrice    float64
+	Discount    bool // новая версия дешевле прежней
+	Subscribers []models.User
 }
 
 func (s *ProfileService) MyOrders(ctx context.Context, userID int) ([]domain.OrderSummary, error) {
 	return s.orders.ListByUser(ctx, userID)
 }
+func (s *ProfileService) MyOrdersPage(ctx context.Context, userID, limit, offset int) ([]domain.OrderSummary, int, error) {
+	orders, total, err := s.orders.ListByUserPage(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, s.content.Orders(ctx, orders)
+}
 func (s *ProfileService) HasAccess(ctx context.Context, userID, routeID int) (bool, error) {
 	return s.orders.UserHasAccess(ctx, userID, routeID)
 }
 func (s *ProfileService) VersionByID(ctx context.Context, versionID int) (*models.RouteVersion, error) {
 	return s.routes.VersionByID(ctx, versionID)
 }
+
+// ToggleFavorite добавляет маршрут в избранное или убирает; возвращает новое состояние
+func (s *ProfileService) ToggleFavorite(ctx context.Context, userID, routeID int) (bool, error) {
+	fav, _, err := s.favorites.Get(ctx, userID, routeID)
+	if err != nil {
+		return false, err
+	}
+	if fav {
+		return false, s.favorites.Remove(ctx, userID, routeID)
+	}
+	return true, s.favorites.Add(ctx, userID, routeID)
+}
+
+// ToggleFavoriteNotify включает или выключает уведомления по избранному маршруту
+func (s *ProfileService) ToggleFavoriteNotify(ctx context.Context, userID, routeID int) error {
+	fav, notify, err := s.favorites.Get(ctx, userID, routeID)
+	if err != nil || !fav {
+		return err
+	}
+	return s.favorites.SetNotify(ctx, userID, routeID, !notify)
+}
+
+func (s *ProfileService) Favorite(ctx context.Context, userID, routeID int) (fav, notify bool, err error) {
+	return s.favorites.Get(ctx, userID, routeID)
+}
+func (s *ProfileService) FavoritesPage(ctx context.Context, userID, limit, offset int) ([]models.RouteVersion, int, error) {
+	routes, total, err := s.favorites.ListByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return routes, total, s.content.Versions(ctx, routes)
+}
+
+// FavoriteNotice собирает новость о публикации ver на смену prev.
+// nil — сообщать нечего: первая публикация или нет подписчиков.
+func (s *ProfileService) FavoriteNotice(ctx context.Context, ver, prev *models.RouteVersion) (*FavoriteNotice, error) {
+	if ver == nil || prev == nil {
+		return nil, nil
+	}
+	subs, err := s.favorites.Subscribers(ctx, ver.RouteID)
+	if err != nil || len(subs) == 0 {
+		return nil, err
+	}
+	return &FavoriteNotice{
+		Version:     ver,
+		OldPrice:    prev.Price,
+		Discount:    ver.Price < prev.Price,
+		Subscribers: subs,
+	}, nil
+}
+
+// Rate — оценка маршрута от 1 до 5; оценивать можно только купленный маршрут
+func (s *ProfileService) Rate(ctx context.Context, userID, routeID, rating int) error {
+	if rating < 1 || rating > 5 {
+		return ErrInvalidValue
+	}
+	ok, err := s.reviews.Rate(ctx, userID, routeID, rating)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNoAccess
+	}
+	return nil
+}
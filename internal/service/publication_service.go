@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"walki/internal/models"
+	"walki/internal/repository"
+)
+
+var (
+	ErrForbidden         = errors.New("action is not allowed for the user")
+	ErrInvalidTransition = errors.New("invalid route status transition")
+)
+
+// PublicationService — жизненный цикл маршрута: draft -> published -> archived
+type PublicationService struct {
+	routes repository.RouteRepository
+	pub    repository.PublicationRepository
+}
+
+func NewPublicationService(r repository.RouteRepository, p repository.PublicationRepository) *PublicationService {
+	return &PublicationService{routes: r, pub: p}
+}
+
+// Publish публикует последнюю версию маршрута (только администратор).
+// prev — версия, которая была в продаже до публикации (nil при первой публикации).
+func (s *PublicationService) Publish(ctx context.Context, actor *models.User, routeID int) (ver, prev *models.RouteVersion, err error) {
+	if !actor.IsAdmin() {
+		return nil, nil, ErrForbidden
+	}
+	ver, err = s.routes.LatestVersion(ctx, routeID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("latest version: %w", err)
+	}
+	if ver.Status != models.RouteStatusDraft {
+		return nil, nil, ErrInvalidTransition
+	}
+	prev, _ = s.routes.ActiveVersion(ctx, routeID)
+	// статус проверяется ещё раз под блокировкой: двойное нажатие не опубликует версию дважды
+	if err := s.pub.PublishVersion(ctx, ver.ID, actor.ID); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, nil, ErrInvalidTransition
+		}
+		return nil, nil, fmt.Errorf("publish version: %w", err)
+	}
+	ver, err = s.routes.VersionByID(ctx, ver.ID)
+	return ver, prev, err
+}
+
+// Archive убирает маршрут из продажи; купившие сохраняют доступ
+func (s *PublicationService) Archive(ctx context.Context, actor *models.User, routeID int) error {
+	return s.transition(ctx, actor, routeID, models.RouteStatusPublished, models.RouteStatusArchived)
+}
+
+// Restore возвращает архивный маршрут в каталог
+func (s *PublicationService) Restore(ctx context.Context, actor *models.User, routeID int) error {
+	if _, err := s.routes.ActiveVersion(ctx, routeID); err != nil {
+		return ErrInvalidTransition // нечего возвращать: нет опубликованной версии
+	}
+	return s.transition(ctx, actor, routeID, models.RouteStatusArchived, models.RouteStatusPublished)
+}
+
+func (s *PublicationService) SetVisible(ctx context.Context, actor *models.User, routeID int, visible bool) error {
+	if !actor.IsAdmin() {
+		return ErrForbidden
+	}
+	return s.pub.SetVisible(ctx, routeID, visible, actor.ID)
+}
+
+// Preview — последняя версия в любом статусе; доступна автору маршрута и администраторам
+func (s *PublicationService) Preview(ctx context.Context, actor *models.User, routeID int) (*models.RouteVersion, error) {
+	if err := s.ensureCanView(ctx, actor, routeID); err != nil {
+		return nil, err
+	}
+	return s.routes.LatestVersion(ctx, routeID)
+}
+
+func (s *PublicationService) History(ctx context.Context, actor *models.User, routeID int) ([]models.RouteStatusChange, error) {
+	if err := s.ensureCanView(ctx, actor, routeID); err != nil {
+		return nil, err
+	}
+	return s.pub.History(ctx, routeID, 20)
+}
+
+func (s *PublicationService) transition(ctx context.Context, actor *models.User, routeID int, from, to string) error {
+	if !actor.IsAdmin() {
+		return ErrForbidden
+	}
+	// from проверяется в том же UPDATE — параллельный переход не пройдёт дважды
+	err := s.pub.SetRouteStatus(ctx, routeID, from, to, actor.ID, "")
+	if errors.Is(err, repository.ErrStatusChanged) {
+		return ErrInvalidTransition
+	}
+	return err
+}
+
+func (s *PublicationService) ensureCanView(ctx context.Context, actor *models.User, routeID int) error {
+	if actor == nil {
+		return ErrForbidden
+	}
+	if actor.IsAdmin() {
+		return nil
+	}
+	rt, err := s.routes.RouteByID(ctx, routeID)
+	if err != nil {
+		return fmt.Errorf("get route: %w", err)
+	}
+	if rt.CreatedBy != actor.ID {
+		return ErrForbidden
+	}
+	return nil
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 
+	"walki/internal/i18n"
 	"walki/internal/models"
 	"walki/internal/repository"
 )
@@ -12,16 +15,18 @@ import (
 // Доменно-значимые ошибки
 var (
 	ErrNoAccess = errors.New("user has no access to the route")
+	ErrNoSample = errors.New("route has no preview points")
 )
 
 type RouteRunService struct {
 	routes  repository.RouteRepository
 	orders  repository.OrderRepository
 	runRepo repository.RouteRunRepository
+	content contentLocalizer
 }
 
-func NewRouteRunService(rr repository.RouteRepository, or repository.OrderRepository, run repository.RouteRunRepository) *RouteRunService {
-	return &RouteRunService{routes: rr, orders: or, runRepo: run}
+func NewRouteRunService(rr repository.RouteRepository, or repository.OrderRepository, run repository.RouteRunRepository, tr repository.TranslationRepository) *RouteRunService {
+	return &RouteRunService{routes: rr, orders: or, runRepo: run, content: contentLocalizer{tr}}
 }
 
 // DTO, который отдаём телеграм-слою
@@ -36,6 +41,10 @@ type PointWithMedia struct {
 	HasNext      bool
 	ContentMsgID *int // message_id «контент»-сообщения (фото/текст)
 	VoiceMsgID   *int // message_id voice-сообщения
+
+	// Sample — пробная точка до покупки: Idx, HasPrev и HasNext считаются
+	// среди пробных точек, прогресс не ведётся
+	Sample bool
 }
 
 /* ==========================
@@ -50,9 +59,55 @@ func (s *RouteRunService) Start(ctx context.Context, userID, routeID int) (*Poin
 	return s.moveFirst(ctx, userID, routeID)
 }
 
+// Preview: прохождение черновика автором — без проверки покупки.
+// Права на версию проверяет вызывающий (AuthoringService.Draft).
+func (s *RouteRunService) Preview(ctx context.Context, userID int, ver *models.RouteVersion) (*PointWithMedia, error) {
+	if ver.Status != models.RouteStatusDraft {
+		return nil, ErrNoAccess
+	}
+	return s.moveFirstOf(ctx, userID, ver)
+}
+
+// Sample: n-я пробная точка опубликованной версии — без проверки покупки
+func (s *RouteRunService) Sample(ctx context.Context, routeID, n int) (*PointWithMedia, error) {
+	ver, err := s.routes.ActiveVersion(ctx, routeID)
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.runRepo.PreviewPoints(ctx, ver.ID)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n >= len(points) {
+		return nil, ErrNoSample
+	}
+	p := points[n]
+	photoIds, voiceIds, err := s.localizedPoint(ctx, &p)
+	if err != nil {
+		return nil, err
+	}
+	return &PointWithMedia{
+		Point:     &p,
+		PhotoIds:  photoIds,
+		VoiceIds:  voiceIds,
+		VersionID: ver.ID,
+		RouteID:   routeID,
+		Idx:       n,
+		HasPrev:   n > 0,
+		HasNext:   n+1 < len(points),
+		Sample:    true,
+	}, nil
+}
+
+// SampleCount — сколько пробных точек у версии (для кнопки на карточке)
+func (s *RouteRunService) SampleCount(ctx context.Context, versionID int) (int, error) {
+	points, err := s.runRepo.PreviewPoints(ctx, versionID)
+	return len(points), err
+}
+
 // Текущий прогресс (nil, nil если не найден — это поведение часто удобно наверху)
 func (s *RouteRunService) Progress(ctx context.Context, userID, routeID int) (*models.RouteProgress, error) {
-	ver, err := s.getVersion(ctx, routeID)
+	ver, err := s.getVersion(ctx, userID, routeID)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +122,7 @@ func (s *RouteRunService) Progress(ctx context.Context, userID, routeID int) (*m
 
 // Continue: шаг вперёд от текущего индекса; если дальше нет точек — завершаем прогресс.
 func (s *RouteRunService) Continue(ctx context.Context, userID, routeID int) (*PointWithMedia, bool, error) {
-	ver, err := s.getVersion(ctx, routeID)
+	ver, err := s.getVersion(ctx, userID, routeID)
 	if err != nil {
 		return nil, false, err
 	}
@@ -95,6 +150,72 @@ func (s *RouteRunService) Continue(ctx context.Context, userID, routeID int) (*P
 	return pm, true, nil
 }
 
+// UpdateAvailable: опубликована ли версия новее той, по которой идёт пользователь.
+// Возвращает текущую и новую версии, если обновление есть.
+func (s *RouteRunService) UpdateAvailable(ctx context.Context, userID, routeID int) (cur, latest *models.RouteVersion, ok bool, err error) {
+	cur, err = s.getVersion(ctx, userID, routeID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	latest, err = s.routes.ActiveVersion(ctx, routeID)
+	if err != nil {
+		// нет опубликованных версий — обновляться не на что
+		return cur, nil, false, nil
+	}
+	if latest.VersionNumber <= cur.VersionNumber {
+		return cur, nil, false, nil
+	}
+	return cur, latest, true, nil
+}
+
+// Upgrade: переводит пользователя на последнюю опубликованную версию.
+// Прогресс переносится на соответствующую точку новой версии.
+// Возвращает точку для показа, если прогулка была начата и не завершена (иначе nil).
+func (s *RouteRunService) Upgrade(ctx context.Context, userID, routeID int) (*models.RouteVersion, *PointWithMedia, error) {
+	cur, latest, ok, err := s.UpdateAvailable(ctx, userID, routeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return cur, nil, nil
+	}
+
+	var target *models.RoutePoint
+	// pr == nil — прогулка не начата, переносить нечего; ошибка БД — не «нет прогресса»
+	pr, err := s.runRepo.GetProgress(ctx, userID, cur.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get progress: %w", err)
+	}
+	if pr != nil {
+		points, err := s.runRepo.Points(ctx, latest.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("new version points: %w", err)
+		}
+		if from, err := s.runRepo.PointByIndex(ctx, cur.ID, pr.CurrentIdx); err == nil {
+			target = matchPoint(from, points)
+		} else if len(points) > 0 {
+			target = &points[0]
+		}
+	}
+
+	var idx *int
+	if target != nil {
+		idx = &target.Idx
+	}
+	if err := s.runRepo.UpgradeWalk(ctx, userID, routeID, cur.ID, latest.ID, idx); err != nil {
+		return nil, nil, fmt.Errorf("upgrade walk: %w", err)
+	}
+
+	if target == nil || pr.FinishedAt != nil {
+		return latest, nil, nil
+	}
+	pm, err := s.pack(ctx, userID, latest.ID, routeID, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return latest, pm, nil
+}
+
 // Restart: сброс на первую точку
 func (s *RouteRunService) Restart(ctx context.Context, userID, routeID int) (*PointWithMedia, error) {
 	return s.moveFirst(ctx, userID, routeID)
@@ -102,7 +223,7 @@ func (s *RouteRunService) Restart(ctx context.Context, userID, routeID int) (*Po
 
 // Prev: шаг назад от текущего индекса
 func (s *RouteRunService) Prev(ctx context.Context, userID, routeID int) (*PointWithMedia, bool, error) {
-	ver, err := s.getVersion(ctx, routeID)
+	ver, err := s.getVersion(ctx, userID, routeID)
 	if err != nil {
 		return nil, false, err
 	}
@@ -129,6 +250,15 @@ func (s *RouteRunService) Finish(ctx context.Context, userID, routeVerID int) er
 	return s.runRepo.Finish(ctx, userID, routeVerID)
 }
 
+// FinishRoute — завершение по routeID (версию определяет сервис)
+func (s *RouteRunService) FinishRoute(ctx context.Context, userID, routeID int) error {
+	ver, err := s.getVersion(ctx, userID, routeID)
+	if err != nil {
+		return err
+	}
+	return s.runRepo.Finish(ctx, userID, ver.ID)
+}
+
 func (s *RouteRunService) UpdateMessageIDs(ctx context.Context, userID, versionID int, contentMsgID, voiceMsgID *int) error {
 	return s.runRepo.UpdateMessageIDs(ctx, userID, versionID, contentMsgID, voiceMsgID)
 }
@@ -149,21 +279,65 @@ func (s *RouteRunService) ensureAccess(ctx context.Context, userID, routeID int)
 	return nil
 }
 
-// Детали активной версии по routeID
-func (s *RouteRunService) getVersion(ctx context.Context, routeID int) (*models.RouteVersion, error) {
-	ver, err := s.routes.Details(ctx, routeID)
+// Версия, закреплённая за покупателем (купленная или выбранная при обновлении).
+// Публикация новой версии не меняет прогулку, пока пользователь сам не обновится.
+func (s *RouteRunService) getVersion(ctx context.Context, userID, routeID int) (*models.RouteVersion, error) {
+	verID, ok, err := s.orders.PinnedVersion(ctx, userID, routeID)
 	if err != nil {
 		return nil, err
 	}
-	return ver, nil
+	if !ok {
+		// автор проходит предпросмотр черновика (см. Preview)
+		if pr, err := s.runRepo.DraftProgress(ctx, userID, routeID); err == nil {
+			return s.routes.VersionByID(ctx, pr.VersionID)
+		}
+		return nil, ErrNoAccess
+	}
+	return s.routes.VersionByID(ctx, verID)
+}
+
+// Ближайшая к исходной точка новой версии: то же название, иначе ближайшая по координатам
+func matchPoint(from *models.RoutePoint, points []models.RoutePoint) *models.RoutePoint {
+	if len(points) == 0 {
+		return nil
+	}
+	title := strings.ToLower(strings.TrimSpace(from.Title))
+	for i := range points {
+		if strings.ToLower(strings.TrimSpace(points[i].Title)) == title {
+			return &points[i]
+		}
+	}
+	best := &points[0]
+	bestDist := distanceKm(from.Lat, from.Lon, best.Lat, best.Lon)
+	for i := 1; i < len(points); i++ {
+		if d := distanceKm(from.Lat, from.Lon, points[i].Lat, points[i].Lon); d < bestDist {
+			best, bestDist = &points[i], d
+		}
+	}
+	return best
+}
+
+// Расстояние по большому кругу (haversine), км
+func distanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	rad := func(d float64) float64 { return d * math.Pi / 180 }
+	dLat := rad(lat2 - lat1)
+	dLon := rad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
 }
 
 // Перейти к первой точке версии и заапсертить прогресс
 func (s *RouteRunService) moveFirst(ctx context.Context, userID, routeID int) (*PointWithMedia, error) {
-	ver, err := s.getVersion(ctx, routeID)
+	ver, err := s.getVersion(ctx, userID, routeID)
 	if err != nil {
 		return nil, err
 	}
+	return s.moveFirstOf(ctx, userID, ver)
+}
+
+func (s *RouteRunService) moveFirstOf(ctx context.Context, userID int, ver *models.RouteVersion) (*PointWithMedia, error) {
 	// попробуем явный idx=0, если его нет — возьмём FirstPoint
 	p, err := s.runRepo.PointByIndex(ctx, ver.ID, 0)
 	if err != nil {
@@ -209,7 +383,7 @@ func (s *RouteRunService) pack(
 	p *models.RoutePoint,
 ) (*PointWithMedia, error) {
 
-	photoIds, voiceIds, err := s.runRepo.PointMediaIDs(ctx, p.ID)
+	photoIds, voiceIds, err := s.localizedPoint(ctx, p)
 	if err != nil {
 		return nil, err
 	}
@@ -244,3 +418,12 @@ func (s *RouteRunService) pack(
 		VoiceMsgID:   voiceID,
 	}, nil
 }
+
+// localizedPoint переводит точку на язык из контекста и выбирает медиа:
+// озвучку на этом языке, если она записана, иначе оригинальную
+func (s *RouteRunService) localizedPoint(ctx context.Context, p *models.RoutePoint) (photoIDs, voiceIDs []int64, err error) {
+	if err := s.content.Point(ctx, p); err != nil {
+		return nil, nil, err
+	}
+	return s.runRepo.PointMediaIDs(ctx, p.ID, i18n.From(ctx).Lang())
+}
@@ -2,30 +2,151 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"walki/internal/domain"
 	"walki/internal/models"
 	"walki/internal/repository"
 )
 
+// Inline-поиск дёргается на каждый набранный символ: ответы кэшируем ненадолго
+const (
+	searchCacheTTL  = time.Minute
+	searchCacheSize = 1000
+)
+
+// ErrRouteUnavailable — маршрута нет в каталоге (удалён, скрыт или в архиве),
+// например по устаревшей ссылке route_<id>
+var ErrRouteUnavailable = errors.New("route is unavailable")
+
 type RouteService struct {
-	repo repository.RouteRepository
+	repo    repository.RouteRepository
+	content contentLocalizer
+
+	mu          sync.Mutex
+	searchCache map[searchKey]searchCacheEntry
+}
+
+type searchKey struct {
+	filter        models.RouteFilter
+	limit, offset int
 }
 
-func NewRouteService(repo repository.RouteRepository) *RouteService {
-	return &RouteService{repo: repo}
+type searchCacheEntry struct {
+	routes  []models.RouteVersion
+	fetched time.Time
 }
 
-func (s *RouteService) Cities(ctx context.Context) ([]string, error) {
-	return s.repo.Cities(ctx)
+func NewRouteService(repo repository.RouteRepository, tr repository.TranslationRepository) *RouteService {
+	return &RouteService{repo: repo, content: contentLocalizer{tr}, searchCache: map[searchKey]searchCacheEntry{}}
 }
 
-func (s *RouteService) ListByCity(ctx context.Context, city string) ([]models.RouteVersion, error) {
-	return s.repo.ByCity(ctx, city)
+func (s *RouteService) Cities(ctx context.Context, limit, offset int) ([]string, int, error) {
+	return s.repo.Cities(ctx, limit, offset)
+}
+
+// Маршруты для показа пользователю — с переводом на язык из контекста
+
+func (s *RouteService) ListByCity(ctx context.Context, city string, limit, offset int) ([]models.RouteVersion, int, error) {
+	routes, total, err := s.repo.ByCity(ctx, city, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return routes, total, s.content.Versions(ctx, routes)
 }
 
 func (s *RouteService) Details(ctx context.Context, routeID int) (*models.RouteVersion, error) {
-	return s.repo.Details(ctx, routeID)
+	ver, err := s.repo.Details(ctx, routeID)
+	if errors.Is(err, repository.ErrRouteUnavailable) {
+		return nil, ErrRouteUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ver, s.content.Version(ctx, ver)
 }
 
 func (s *RouteService) VersionByID(ctx context.Context, routeVersionID int) (*models.RouteVersion, error) {
-	return s.repo.VersionByID(ctx, routeVersionID)
+	ver, err := s.repo.VersionByID(ctx, routeVersionID)
+	if err != nil {
+		return nil, err
+	}
+	return ver, s.content.Version(ctx, ver)
+}
+
+// Translated — копия версии с переводом на язык из контекста (для рассылок)
+func (s *RouteService) Translated(ctx context.Context, ver *models.RouteVersion) (*models.RouteVersion, error) {
+	cp := *ver
+	return &cp, s.content.Version(ctx, &cp)
+}
+
+// Nearby — маршруты со стартом в радиусе radiusKm, от ближайших
+func (s *RouteService) Nearby(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]domain.NearbyRoute, error) {
+	routes, err := s.repo.Nearby(ctx, lat, lon, radiusKm, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range routes {
+		if err := s.content.Version(ctx, &routes[i].Route); err != nil {
+			return nil, err
+		}
+	}
+	return routes, nil
+}
+
+// Gallery — изображения версии для превью, обложка первой
+func (s *RouteService) Gallery(ctx context.Context, versionID int) ([]int64, error) {
+	return s.repo.GalleryMediaIDs(ctx, versionID)
+}
+
+// Themes — тематики для фильтра поиска
+func (s *RouteService) Themes(ctx context.Context) ([]string, error) {
+	return s.repo.Themes(ctx)
+}
+
+// Search — поиск по каталогу; результат переведён на язык из контекста
+func (s *RouteService) Search(ctx context.Context, f models.RouteFilter, limit, offset int) ([]models.RouteVersion, error) {
+	cached, err := s.search(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	routes := append([]models.RouteVersion(nil), cached...)
+	return routes, s.content.Versions(ctx, routes)
+}
+
+// search — поиск с кэшем на searchCacheTTL.
+// Результат общий для всех вызывающих: изменять его нельзя.
+func (s *RouteService) search(ctx context.Context, f models.RouteFilter, limit, offset int) ([]models.RouteVersion, error) {
+	f.Query = strings.ToLower(strings.Join(strings.Fields(f.Query), " "))
+	key := searchKey{f, limit, offset}
+	now := time.Now()
+
+	s.mu.Lock()
+	e, ok := s.searchCache[key]
+	s.mu.Unlock()
+	if ok && now.Sub(e.fetched) < searchCacheTTL {
+		return e.routes, nil
+	}
+
+	routes, err := s.repo.Search(ctx, f, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if len(s.searchCache) >= searchCacheSize {
+		for k, e := range s.searchCache {
+			if now.Sub(e.fetched) >= searchCacheTTL {
+				delete(s.searchCache, k)
+			}
+		}
+		if len(s.searchCache) >= searchCacheSize {
+			s.searchCache = map[searchKey]searchCacheEntry{}
+		}
+	}
+	s.searchCache[key] = searchCacheEntry{routes: routes, fetched: now}
+	s.mu.Unlock()
+	return routes, nil
 }
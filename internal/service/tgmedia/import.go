@@ -0,0 +1,109 @@
+package tgmedia
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"walki/internal/models"
+)
+
+// Bot API отдаёт файлы размером до 20 МБ
+const maxDownloadBytes = 20 << 20
+
+// downloadClient ограничивает скачивание по времени: зависшая загрузка
+// не должна держать воркер пула (и очередь апдейтов чата) бесконечно
+var downloadClient = &http.Client{Timeout: time.Minute}
+
+// TelegramFile — файл, присланный пользователем в бот
+type TelegramFile struct {
+	FileID    string
+	FileName  string
+	MimeType  string
+	Size      int64
+	MediaType string // image | audio
+}
+
+// FileLinker выдаёт прямую ссылку на файл Telegram
+type FileLinker interface {
+	GetFileDirectURL(fileID string) (string, error)
+}
+
+// ImportTelegramFile скачивает файл из Telegram, кладёт его в S3 и регистрирует в media.
+// Полученный file_id сразу кэшируется: повторная отправка не потребует загрузки.
+func (s *Service) ImportTelegramFile(ctx context.Context, bot FileLinker, f TelegramFile, uploadedBy int) (int64, error) {
+	if f.Size > maxDownloadBytes {
+		return 0, errors.New("file is too large")
+	}
+	link, err := bot.GetFileDirectURL(f.FileID)
+	if err != nil {
+		return 0, fmt.Errorf("get file url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download file: status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return 0, fmt.Errorf("read file: %w", err)
+	}
+	if len(body) > maxDownloadBytes {
+		return 0, errors.New("file is too large")
+	}
+
+	key, err := objectKey(f.MediaType, path.Ext(f.FileName))
+	if err != nil {
+		return 0, err
+	}
+	if err := s.S3.PutObject(ctx, key, bytes.NewReader(body), int64(len(body)), f.MimeType); err != nil {
+		return 0, fmt.Errorf("put object: %w", err)
+	}
+
+	bucket := s.S3.Bucket
+	mime := f.MimeType
+	id, err := s.Repo.Create(ctx, &models.Media{
+		Type:       f.MediaType,
+		URL:        "s3://" + bucket + "/" + key,
+		Filename:   f.FileName,
+		SizeBytes:  int64(len(body)),
+		UploadedBy: uploadedBy,
+		IsPublic:   false,
+		MimeType:   &mime,
+		S3Bucket:   &bucket,
+		S3Key:      &key,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("create media: %w", err)
+	}
+	// без кэша file_id файл просто загрузится в Telegram заново при отправке
+	if err := s.Repo.UpsertTelegramFileID(ctx, id, f.FileID, mime, nil); err != nil {
+		log.Printf("Error caching telegram file_id for media %d: %v", id, err)
+	}
+	return id, nil
+}
+
+// media/<type>/<yyyy>/<mm>/<random><ext>
+func objectKey(mediaType, ext string) (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("media/%s/%s/%s%s", mediaType, time.Now().Format("2006/01"), hex.EncodeToString(b[:]), ext), nil
+}
@@ -0,0 +1,49 @@
+package tgmedia
+
+import (
+	"context"
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// PhotoFile — источник фото для сообщений, которые собирает вызывающий
+// (правка экрана, альбом): кэшированный file_id или presigned-ссылка из S3.
+// cached=false — после отправки передайте результат в RememberPhoto.
+func (s *Service) PhotoFile(ctx context.Context, mediaID int64) (file tgbotapi.RequestFileData, cached bool, err error) {
+	if fid, ok, err := s.Repo.GetTelegramFileID(ctx, mediaID); err == nil && ok && fid != "" {
+		return tgbotapi.FileID(fid), true, nil
+	}
+	m, err := s.Repo.GetByID(ctx, mediaID)
+	if err != nil {
+		return nil, false, err
+	}
+	if m == nil || m.S3Key == nil || *m.S3Key == "" {
+		return nil, false, errors.New("media has no s3 object")
+	}
+	url, err := s.S3.PresignGet(ctx, *m.S3Key, s.URLTTL)
+	if err != nil {
+		return nil, false, err
+	}
+	return tgbotapi.FileURL(url), false, nil
+}
+
+// CachedPhotoID — file_id фото, если оно уже отправлялось (для inline-режима:
+// presigned-ссылка протухнет раньше, чем Telegram перестанет показывать результат)
+func (s *Service) CachedPhotoID(ctx context.Context, mediaID int64) (string, bool) {
+	fid, ok, err := s.Repo.GetTelegramFileID(ctx, mediaID)
+	return fid, err == nil && ok && fid != ""
+}
+
+// RememberPhoto кэширует file_id из сообщения, отправленного по ссылке из PhotoFile
+func (s *Service) RememberPhoto(ctx context.Context, mediaID int64, msg tgbotapi.Message) {
+	if len(msg.Photo) == 0 {
+		return
+	}
+	var chatID int64
+	if msg.Chat != nil {
+		chatID = msg.Chat.ID
+	}
+	// Telegram хранит фото в JPEG независимо от исходного формата
+	s.cacheTG(ctx, mediaID, msg.Photo[len(msg.Photo)-1].FileID, "image/jpeg", chatID)
+}
@@ -26,16 +26,22 @@ func New(repo repository.MediaRepository, s3c *s3client.Client) *Service {
 	}
 }
 
+// Sender — отправка сообщений (BotAPI или sender.Sender с лимитами)
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+}
+
 // SendMedia — единственная публичная точка.
 // Определяет способ отправки по MIME: image/jpeg|png -> Photo, audio/* -> Audio, иначе -> Document.
 // Возвращает сохранённый fileID и messageID отправленного сообщения.
 func (s *Service) SendMedia(
 	ctx context.Context,
-	bot *tgbotapi.BotAPI,
+	bot Sender,
 	chatID int64,
 	mediaID int64,
 	caption string,
 	parseMode string, // "", tgbotapi.ModeMarkdown(V2), tgbotapi.ModeHTML
+	replyMarkup any, // nil — без клавиатуры
 ) (fileID string, messageID int, err error) {
 
 	// 1) Достаём метаданные
@@ -59,6 +65,7 @@ func (s *Service) SendMedia(
 		case "photo":
 			msg := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(fid))
 			msg.Caption = caption
+			msg.ReplyMarkup = replyMarkup
 			if parseMode != "" {
 				msg.ParseMode = parseMode
 			}
@@ -68,6 +75,17 @@ func (s *Service) SendMedia(
 		case "audio":
 			msg := tgbotapi.NewAudio(chatID, tgbotapi.FileID(fid))
 			msg.Caption = caption
+			msg.ReplyMarkup = replyMarkup
+			if parseMode != "" {
+				msg.ParseMode = parseMode
+			}
+			sent, err := bot.Send(msg)
+			return fid, sent.MessageID, err
+
+		case "voice":
+			msg := tgbotapi.NewVoice(chatID, tgbotapi.FileID(fid))
+			msg.Caption = caption
+			msg.ReplyMarkup = replyMarkup
 			if parseMode != "" {
 				msg.ParseMode = parseMode
 			}
@@ -77,6 +95,7 @@ func (s *Service) SendMedia(
 		default: // document
 			msg := tgbotapi.NewDocument(chatID, tgbotapi.FileID(fid))
 			msg.Caption = caption
+			msg.ReplyMarkup = replyMarkup
 			if parseMode != "" {
 				msg.ParseMode = parseMode
 			}
@@ -96,19 +115,22 @@ func (s *Service) SendMedia(
 	case "photo":
 		// Telegram как фото надёжно ест jpg/png; остальные форматы лучше документом.
 		if !isPhotoMime(mime) {
-			return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime)
+			return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
 		}
-		return s.sendPhotoURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime)
+		return s.sendPhotoURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
 
 	case "audio":
 		// Если это не audio/* — уходим в документ
 		if !strings.HasPrefix(mime, "audio/") {
-			return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime)
+			return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
 		}
-		return s.sendAudioURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime)
+		return s.sendAudioURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
+
+	case "voice":
+		return s.sendVoiceURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
 
 	default:
-		return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime)
+		return s.sendDocumentURL(ctx, bot, chatID, mediaID, caption, parseMode, url, mime, replyMarkup)
 	}
 }
 
@@ -118,6 +140,8 @@ func chooseKind(mime string) string {
 	switch {
 	case isPhotoMime(mime):
 		return "photo"
+	case isVoiceMime(mime):
+		return "voice"
 	case strings.HasPrefix(mime, "audio/"):
 		return "audio"
 	default:
@@ -130,16 +154,23 @@ func isPhotoMime(mime string) bool {
 	return strings.Contains(m, "jpeg") || strings.Contains(m, "jpg") || strings.Contains(m, "png")
 }
 
+// Голосовые заметки Telegram приходят как OGG/Opus и отправляются через sendVoice
+func isVoiceMime(mime string) bool {
+	m := strings.ToLower(mime)
+	return strings.HasPrefix(m, "audio/ogg") || strings.HasPrefix(m, "audio/opus")
+}
+
 func (s *Service) cacheTG(ctx context.Context, mediaID int64, fid, contentType string, chatID int64) {
 	_ = s.Repo.UpsertTelegramFileID(ctx, mediaID, fid, contentType, &chatID)
 }
 
-func (s *Service) sendPhotoURL(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64,
-	mediaID int64, caption, parseMode, url, mime string,
+func (s *Service) sendPhotoURL(ctx context.Context, bot Sender, chatID int64,
+	mediaID int64, caption, parseMode, url, mime string, replyMarkup any,
 ) (string, int, error) {
 
 	msg := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(url))
 	msg.Caption = caption
+	msg.ReplyMarkup = replyMarkup
 	if parseMode != "" {
 		msg.ParseMode = parseMode
 	}
@@ -155,12 +186,13 @@ func (s *Service) sendPhotoURL(ctx context.Context, bot *tgbotapi.BotAPI, chatID
 	return fid, sent.MessageID, nil
 }
 
-func (s *Service) sendAudioURL(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64,
-	mediaID int64, caption, parseMode, url, mime string,
+func (s *Service) sendAudioURL(ctx context.Context, bot Sender, chatID int64,
+	mediaID int64, caption, parseMode, url, mime string, replyMarkup any,
 ) (string, int, error) {
 
 	msg := tgbotapi.NewAudio(chatID, tgbotapi.FileURL(url))
 	msg.Caption = caption
+	msg.ReplyMarkup = replyMarkup
 	if parseMode != "" {
 		msg.ParseMode = parseMode
 	}
@@ -176,12 +208,13 @@ func (s *Service) sendAudioURL(ctx context.Context, bot *tgbotapi.BotAPI, chatID
 	return fid, sent.MessageID, nil
 }
 
-func (s *Service) sendDocumentURL(ctx context.Context, bot *tgbotapi.BotAPI, chatID int64,
-	mediaID int64, caption, parseMode, url, mime string,
+func (s *Service) sendDocumentURL(ctx context.Context, bot Sender, chatID int64,
+	mediaID int64, caption, parseMode, url, mime string, replyMarkup any,
 ) (string, int, error) {
 
 	msg := tgbotapi.NewDocument(chatID, tgbotapi.FileURL(url))
 	msg.Caption = caption
+	msg.ReplyMarkup = replyMarkup
 	if parseMode != "" {
 		msg.ParseMode = parseMode
 	}
@@ -196,3 +229,25 @@ func (s *Service) sendDocumentURL(ctx context.Context, bot *tgbotapi.BotAPI, cha
 	s.cacheTG(ctx, mediaID, fid, mime, chatID)
 	return fid, sent.MessageID, nil
 }
+
+func (s *Service) sendVoiceURL(ctx context.Context, bot Sender, chatID int64,
+	mediaID int64, caption, parseMode, url, mime string, replyMarkup any,
+) (string, int, error) {
+
+	msg := tgbotapi.NewVoice(chatID, tgbotapi.FileURL(url))
+	msg.Caption = caption
+	msg.ReplyMarkup = replyMarkup
+	if parseMode != "" {
+		msg.ParseMode = parseMode
+	}
+	sent, err := bot.Send(msg)
+	if err != nil {
+		return "", 0, err
+	}
+	if sent.Voice == nil {
+		return "", 0, errors.New("telegram did not return voice file_id")
+	}
+	fid := sent.Voice.FileID
+	s.cacheTG(ctx, mediaID, fid, mime, chatID)
+	return fid, sent.MessageID, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"walki/internal/i18n"
 	"walki/internal/models"
 	"walki/internal/repository"
 )
@@ -14,12 +15,39 @@ func NewUserService(repo repository.UserRepository) *UserService {
 	return &UserService{repo: repo}
 }
 
-// Register сохраняет нового пользователя или обновляет существующего
-func (s *UserService) Register(ctx context.Context, user *models.User) error {
+// Register сохраняет нового пользователя или обновляет существующего.
+// Заполняет user.ID; created — пользователь пришёл впервые.
+func (s *UserService) Register(ctx context.Context, user *models.User) (created bool, err error) {
 	return s.repo.Upsert(ctx, user)
 }
 
+// AttributeReferral привязывает пригласившего. Засчитываются только новые
+// пользователи: старые не должны «переписываться» на чужую ссылку.
+func (s *UserService) AttributeReferral(ctx context.Context, user *models.User, created bool, referralCode string) (bool, error) {
+	if !created || referralCode == "" || referralCode == user.ReferralCode {
+		return false, nil
+	}
+	return s.repo.SetReferrer(ctx, user.ID, referralCode)
+}
+
+// SetLanguage сохраняет язык интерфейса, выбранный пользователем
+func (s *UserService) SetLanguage(ctx context.Context, user *models.User, lang string) error {
+	if !i18n.Supported(lang) {
+		return ErrInvalidValue
+	}
+	if err := s.repo.SetLanguage(ctx, user.ID, lang); err != nil {
+		return err
+	}
+	user.Language = lang
+	return nil
+}
+
 // GetByTelegramID получает пользователя по его Telegram ID
 func (s *UserService) GetByTelegramID(ctx context.Context, tgID int64) (*models.User, error) {
 	return s.repo.ByTelegramID(ctx, tgID)
 }
+
+// Admins возвращает администраторов каталога (для уведомлений о модерации)
+func (s *UserService) Admins(ctx context.Context) ([]models.User, error) {
+	return s.repo.ByRole(ctx, models.RoleAdmin)
+}
@@ -3,10 +3,12 @@ package s3client
 import (
 	"context"
 	"io"
+	"net/http"
 	"os"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	awscfg "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
@@ -16,12 +18,21 @@ type Client struct {
 	S3      *s3.Client
 	Presign *s3.PresignClient
 	Bucket  string
+
+	transport *http.Transport // для Close
 }
 
 func New(ctx context.Context) (*Client, error) {
+	c := &Client{}
+	// транспорт запоминаем, чтобы при остановке закрыть keep-alive соединения
+	httpClient := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		c.transport = tr
+	})
+
 	// IAM будет искать AWS_REGION, AWS_ACCESS_KEY_ID / SECRET, а также профиль ~/.aws/
 	cfg, err := awscfg.LoadDefaultConfig(ctx,
 		awscfg.WithRegion(os.Getenv("AWS_REGION")),
+		awscfg.WithHTTPClient(httpClient),
 	)
 	if err != nil {
 		return nil, err
@@ -35,22 +46,32 @@ func New(ctx context.Context) (*Client, error) {
 		o.BaseEndpoint = aws.String(endpoint)
 	})
 
-	return &Client{
-		S3:      s3Client,
-		Presign: s3.NewPresignClient(s3Client),
-		Bucket:  bucket,
-	}, nil
+	c.S3 = s3Client
+	c.Presign = s3.NewPresignClient(s3Client)
+	c.Bucket = bucket
+	return c, nil
+}
+
+// Close закрывает простаивающие соединения с хранилищем
+func (c *Client) Close() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
 }
 
 func (c *Client) PutObject(ctx context.Context, key string, body io.Reader, size int64, mime string) error {
-	_, err := c.S3.PutObject(ctx, &s3.PutObjectInput{
+	in := &s3.PutObjectInput{
 		Bucket:       aws.String(c.Bucket),
 		Key:          aws.String(key),
 		Body:         body,
 		ContentType:  aws.String(mime),
 		ACL:          s3types.ObjectCannedACLPrivate,
 		CacheControl: aws.String("public, max-age=31536000, immutable"),
-	})
+	}
+	if size > 0 {
+		in.ContentLength = aws.Int64(size)
+	}
+	_, err := c.S3.PutObject(ctx, in)
 	return err
 }
 
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS route_status_history CASCADE;
+
+DROP INDEX IF EXISTS route_versions_route_status_idx;
+
+ALTER TABLE route_versions
+    DROP COLUMN IF EXISTS published_by,
+    DROP COLUMN IF EXISTS published_at,
+    DROP COLUMN IF EXISTS status;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
+
+DROP TYPE IF EXISTS user_role CASCADE;
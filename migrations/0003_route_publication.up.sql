@@ -0,0 +1,41 @@
+-- Роли пользователей: авторы (гиды) и администраторы каталога
+CREATE TYPE user_role AS ENUM ('user', 'guide', 'admin');
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role user_role NOT NULL DEFAULT 'user';
+
+-- Статус версии: draft -> published -> archived (заменена более новой)
+ALTER TABLE route_versions
+    ADD COLUMN IF NOT EXISTS status       route_status NOT NULL DEFAULT 'draft',
+    ADD COLUMN IF NOT EXISTS published_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS published_by INT REFERENCES users (id) ON DELETE SET NULL;
+
+-- Уже опубликованные и снятые в архив маршруты: последняя версия считается
+-- опубликованной (архив скрывает сам маршрут, купленный доступ остаётся),
+-- предыдущие — заменёнными
+UPDATE route_versions rv
+SET status       = CASE
+                       WHEN rv.version_number = (SELECT MAX(version_number) FROM route_versions WHERE route_id = rv.route_id)
+                           THEN 'published'::route_status
+                       ELSE 'archived'::route_status
+                   END,
+    published_at = rv.created_at
+FROM routes r
+WHERE r.id = rv.route_id
+  AND r.status IN ('published', 'archived');
+
+-- Журнал переходов статусов (кто и когда)
+CREATE TABLE route_status_history
+(
+    id          SERIAL PRIMARY KEY,
+    route_id    INT          NOT NULL REFERENCES routes (id) ON DELETE CASCADE,
+    version_id  INT REFERENCES route_versions (id) ON DELETE CASCADE,
+    from_status route_status,
+    to_status   route_status NOT NULL,
+    changed_by  INT REFERENCES users (id) ON DELETE SET NULL,
+    changed_at  TIMESTAMP    NOT NULL DEFAULT NOW(),
+    comment     TEXT
+);
+
+CREATE INDEX route_status_history_route_idx ON route_status_history (route_id, changed_at DESC);
+CREATE INDEX route_versions_route_status_idx ON route_versions (route_id, status, version_number DESC);
@@ -0,0 +1,4 @@
+-- Версия, по которой покупатель проходит маршрут.
+-- NULL — купленная версия (orders.version_id); заполняется, когда пользователь соглашается обновиться.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS walk_version_id INT REFERENCES route_versions (id) ON DELETE SET NULL;
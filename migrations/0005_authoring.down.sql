@@ -0,0 +1,7 @@
+ALTER TABLE route_point_logs
+    DROP CONSTRAINT IF EXISTS route_point_logs_point_id_fkey,
+    ADD CONSTRAINT route_point_logs_point_id_fkey
+        FOREIGN KEY (point_id) REFERENCES route_points (id) ON DELETE SET NULL;
+
+ALTER TABLE route_versions
+    DROP COLUMN IF EXISTS submitted_at;
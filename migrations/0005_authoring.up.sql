@@ -0,0 +1,10 @@
+-- Черновик отправлен автором на публикацию
+ALTER TABLE route_versions
+    ADD COLUMN IF NOT EXISTS submitted_at TIMESTAMP;
+
+-- Автор может удалить точку черновика, по которой уже прошёл предпросмотр:
+-- point_id в журнале NOT NULL, поэтому записи удаляются вместе с точкой
+ALTER TABLE route_point_logs
+    DROP CONSTRAINT IF EXISTS route_point_logs_point_id_fkey,
+    ADD CONSTRAINT route_point_logs_point_id_fkey
+        FOREIGN KEY (point_id) REFERENCES route_points (id) ON DELETE CASCADE;
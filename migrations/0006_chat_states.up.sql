@@ -0,0 +1,9 @@
+-- Состояния диалогов (FSM) — по одному на чат
+CREATE TABLE chat_states
+(
+    chat_id    BIGINT PRIMARY KEY,
+    state      VARCHAR(100) NOT NULL,
+    data       JSONB        NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,9 @@
+-- Временные блокировки пользователей (флуд, злоупотребления)
+CREATE TABLE user_bans
+(
+    telegram_id BIGINT PRIMARY KEY,
+    until       TIMESTAMP NOT NULL,
+    reason      TEXT      NOT NULL DEFAULT '',
+    banned_by   INT REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
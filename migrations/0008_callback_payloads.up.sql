@@ -0,0 +1,9 @@
+-- Длинные данные inline-кнопок: в callback_data уходит только короткий ключ
+CREATE TABLE callback_payloads
+(
+    id         VARCHAR(16) PRIMARY KEY,
+    payload    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_callback_payloads_created_at ON callback_payloads (created_at);
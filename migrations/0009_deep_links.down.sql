@@ -0,0 +1,6 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code;
+DROP TABLE IF EXISTS user_promos;
+DROP TABLE IF EXISTS promo_codes;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS referral_code,
+    DROP COLUMN IF EXISTS referred_by;
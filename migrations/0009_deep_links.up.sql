@@ -0,0 +1,30 @@
+-- Кто пригласил пользователя (ссылка ?start=ref_<referral_code>), фиксируется один раз.
+-- В ссылке — случайный код, а не users.id: по id можно перебрать пользователей
+-- и оценить их число. Значение по умолчанию вычисляется для каждой строки,
+-- так что существующие пользователи тоже получают свои коды.
+ALTER TABLE users
+    ADD COLUMN referred_by   INT REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN referral_code VARCHAR(16) NOT NULL UNIQUE
+        DEFAULT substr(md5(random()::text || clock_timestamp()::text), 1, 12);
+
+-- Промокоды рекламных кампаний (ссылка ?start=promo_<code>)
+CREATE TABLE promo_codes
+(
+    code             VARCHAR(32) PRIMARY KEY,
+    discount_percent INT       NOT NULL CHECK (discount_percent BETWEEN 1 AND 100),
+    valid_until      TIMESTAMP,          -- NULL = бессрочно
+    max_uses         INT,                -- NULL = без ограничения
+    used_count       INT       NOT NULL DEFAULT 0,
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Активированный, но ещё не использованный промокод: один на пользователя
+CREATE TABLE user_promos
+(
+    user_id      INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    code         VARCHAR(32) NOT NULL REFERENCES promo_codes (code) ON DELETE CASCADE,
+    activated_at TIMESTAMP   NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE orders
+    ADD COLUMN promo_code VARCHAR(32) REFERENCES promo_codes (code) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS route_points_search ON route_points;
+DROP FUNCTION IF EXISTS route_points_search_touch();
+DROP TRIGGER IF EXISTS route_versions_search ON route_versions;
+DROP FUNCTION IF EXISTS route_versions_search_tsv();
+ALTER TABLE route_versions DROP COLUMN IF EXISTS search_tsv;
@@ -0,0 +1,51 @@
+-- Полнотекстовый поиск по каталогу: название, город, тематика, описание и названия точек
+ALTER TABLE route_versions
+    ADD COLUMN search_tsv tsvector NOT NULL DEFAULT ''::tsvector;
+
+CREATE FUNCTION route_versions_search_tsv() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    NEW.search_tsv :=
+            setweight(to_tsvector('russian', coalesce(NEW.title, '')), 'A') ||
+            setweight(to_tsvector('russian', coalesce(NEW.city, '')), 'A') ||
+            setweight(to_tsvector('russian', coalesce(NEW.theme, '')), 'B') ||
+            setweight(to_tsvector('russian', coalesce(NEW.description, '')), 'C') ||
+            setweight(to_tsvector('russian', coalesce(
+                    (SELECT string_agg(p.title, ' ' ORDER BY p.order_index)
+                     FROM route_points p
+                     WHERE p.version_id = NEW.id), '')), 'C');
+    RETURN NEW;
+END
+$$;
+
+CREATE TRIGGER route_versions_search
+    BEFORE INSERT OR UPDATE OF title, city, theme, description
+    ON route_versions
+    FOR EACH ROW
+EXECUTE FUNCTION route_versions_search_tsv();
+
+-- Изменение точек пересчитывает вектор версии: UPDATE OF title срабатывает и без смены значения
+CREATE FUNCTION route_points_search_touch() RETURNS trigger
+    LANGUAGE plpgsql AS
+$$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE route_versions SET title = title WHERE id = OLD.version_id;
+    END IF;
+    IF TG_OP <> 'DELETE' AND (TG_OP = 'INSERT' OR NEW.version_id IS DISTINCT FROM OLD.version_id) THEN
+        UPDATE route_versions SET title = title WHERE id = NEW.version_id;
+    END IF;
+    RETURN NULL;
+END
+$$;
+
+CREATE TRIGGER route_points_search
+    AFTER INSERT OR DELETE OR UPDATE OF title, version_id
+    ON route_points
+    FOR EACH ROW
+EXECUTE FUNCTION route_points_search_touch();
+
+UPDATE route_versions SET title = title;
+
+CREATE INDEX idx_route_versions_search ON route_versions USING GIN (search_tsv);
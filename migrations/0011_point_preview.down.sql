@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_route_points_preview;
+ALTER TABLE route_points DROP COLUMN IF EXISTS is_preview;
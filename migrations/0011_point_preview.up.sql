@@ -0,0 +1,5 @@
+-- Пробные точки: доступны до покупки как превью маршрута
+ALTER TABLE route_points
+    ADD COLUMN is_preview BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_route_points_preview ON route_points (version_id, order_index) WHERE is_preview;
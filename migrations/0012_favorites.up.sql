@@ -0,0 +1,11 @@
+-- Избранные маршруты; notify — сообщать о скидке и новой версии
+CREATE TABLE favorites
+(
+    user_id    INT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    route_id   INT       NOT NULL REFERENCES routes (id) ON DELETE CASCADE,
+    notify     BOOLEAN   NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, route_id)
+);
+
+CREATE INDEX idx_favorites_route ON favorites (route_id) WHERE notify;
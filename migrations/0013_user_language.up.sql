@@ -0,0 +1,3 @@
+-- Язык интерфейса, выбранный пользователем; NULL — по языку клиента Telegram
+ALTER TABLE users
+    ADD COLUMN language VARCHAR(8);
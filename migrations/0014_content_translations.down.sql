@@ -0,0 +1,5 @@
+ALTER TABLE route_point_media
+    DROP COLUMN IF EXISTS language;
+
+DROP TABLE IF EXISTS route_point_translations;
+DROP TABLE IF EXISTS route_version_translations;
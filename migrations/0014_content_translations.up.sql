@@ -0,0 +1,24 @@
+-- Переводы контента маршрута. Оригинал в route_versions/route_points написан на языке
+-- по умолчанию; пустое поле перевода заменяется оригиналом.
+CREATE TABLE route_version_translations
+(
+    version_id  INT          NOT NULL REFERENCES route_versions (id) ON DELETE CASCADE,
+    language    VARCHAR(8)   NOT NULL,
+    title       VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT         NOT NULL DEFAULT '',
+    PRIMARY KEY (version_id, language)
+);
+
+CREATE TABLE route_point_translations
+(
+    point_id             INT          NOT NULL REFERENCES route_points (id) ON DELETE CASCADE,
+    language             VARCHAR(8)   NOT NULL,
+    title                VARCHAR(255) NOT NULL DEFAULT '',
+    description          TEXT         NOT NULL DEFAULT '',
+    arrival_instructions TEXT         NOT NULL DEFAULT '',
+    PRIMARY KEY (point_id, language)
+);
+
+-- Язык озвучки точки; NULL — оригинал (и все фото: они от языка не зависят)
+ALTER TABLE route_point_media
+    ADD COLUMN language VARCHAR(8);
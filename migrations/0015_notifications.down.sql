@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS uq_reviews_user_route;
+DROP TABLE IF EXISTS scheduled_jobs;
+ALTER TABLE route_progress DROP COLUMN IF EXISTS last_activity_at;
+DROP TABLE IF EXISTS notification_settings;
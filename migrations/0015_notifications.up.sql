@@ -0,0 +1,43 @@
+-- Настройки уведомлений; нет строки — всё включено, тихие часы 22:00–09:00
+CREATE TABLE notification_settings
+(
+    user_id    INT       PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    reminders  BOOLEAN   NOT NULL DEFAULT TRUE, -- скорое окончание доступа
+    nudges     BOOLEAN   NOT NULL DEFAULT TRUE, -- незаконченная прогулка
+    reviews    BOOLEAN   NOT NULL DEFAULT TRUE, -- просьба оценить пройденный маршрут
+    quiet_from SMALLINT           DEFAULT 22,   -- тихие часы [from, to) по часовому поясу бота; NULL — выключены
+    quiet_to   SMALLINT           DEFAULT 9,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Последнее действие в прогулке — для напоминания о незаконченной
+ALTER TABLE route_progress
+    ADD COLUMN last_activity_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- Запланированные уведомления: одно на (kind, ref_id). Экземпляры бота
+-- добавляют их идемпотентно, а забирают через FOR UPDATE SKIP LOCKED.
+CREATE TABLE scheduled_jobs
+(
+    id           BIGSERIAL PRIMARY KEY,
+    kind         VARCHAR(32) NOT NULL,
+    ref_id       INT         NOT NULL, -- заказ или прогресс, в зависимости от kind
+    user_id      INT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    run_at       TIMESTAMP   NOT NULL,
+    locked_until TIMESTAMP,            -- занято экземпляром до этого времени
+    attempts     INT         NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    done_at      TIMESTAMP,
+    created_at   TIMESTAMP   NOT NULL DEFAULT NOW(),
+    UNIQUE (kind, ref_id)
+);
+
+CREATE INDEX idx_scheduled_jobs_due ON scheduled_jobs (run_at) WHERE done_at IS NULL;
+
+-- Одна оценка маршрута от пользователя (Rate — upsert по этой паре); из дублей оставляем последнюю
+DELETE FROM reviews r
+    USING reviews newer
+WHERE newer.user_id = r.user_id
+  AND newer.route_id = r.route_id
+  AND (COALESCE(newer.updated_at, newer.created_at, 'epoch'), newer.id) > (COALESCE(r.updated_at, r.created_at, 'epoch'), r.id);
+
+CREATE UNIQUE INDEX uq_reviews_user_route ON reviews (user_id, route_id);
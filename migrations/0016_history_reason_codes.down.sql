@@ -0,0 +1,7 @@
+UPDATE route_status_history
+SET comment = CASE comment
+                  WHEN 'replaced' THEN 'заменена новой версией'
+                  WHEN 'hidden' THEN 'скрыт из каталога'
+                  WHEN 'shown' THEN 'показан в каталоге'
+              END
+WHERE comment IN ('replaced', 'hidden', 'shown');
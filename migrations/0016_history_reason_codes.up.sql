@@ -0,0 +1,9 @@
+-- Причины в журнале публикации — коды вместо русского текста:
+-- текст подставляется на языке того, кто смотрит историю
+UPDATE route_status_history
+SET comment = CASE comment
+                  WHEN 'заменена новой версией' THEN 'replaced'
+                  WHEN 'скрыт из каталога' THEN 'hidden'
+                  WHEN 'показан в каталоге' THEN 'shown'
+              END
+WHERE comment IN ('заменена новой версией', 'скрыт из каталога', 'показан в каталоге');